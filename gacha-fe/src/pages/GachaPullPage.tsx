@@ -84,7 +84,9 @@ const GachaPullPage: React.FC = () => {
 
     const endpoint = gachaEndpoint;
     try {
-      const response = await handleRequest<CharacterGacha>(endpoint, "GET");
+      const response = await handleRequest<CharacterGacha>(endpoint, "POST", {}, {
+        "Idempotency-Key": crypto.randomUUID(),
+      }, false);
       if (response.code === 200) {
         setCharacter(response.data);
       } else {
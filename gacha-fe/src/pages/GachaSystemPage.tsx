@@ -6,6 +6,7 @@ import { DeletIcon, EditIcon, RedirectIcon } from "../components/Icon";
 import { LogoutButton } from "../components/LogoutButton";
 import InputRarityModal from "../components/RarityModal";
 import { Character } from "../types/characterType";
+import { ConfigVersion } from "../types/configVersionType";
 import { GachaSytemDetail } from "../types/gachaSystemType";
 import { Rarity } from "../types/rarityType";
 import { handleRequest } from "../utils/api";
@@ -47,6 +48,7 @@ const GachaSystemPage: React.FC = () => {
   const [endpoint, setEndpoint] = useState<string>("");
   const [rarities, setRarities] = useState<Rarity[]>([]);
   const [rarityMap, setRarityMap] = useState<Record<number, string>>({});
+  const [activeVersion, setActiveVersion] = useState<ConfigVersion | null>(null);
 
   const [refreshToggle, setRefreshToggle] = useState(false);
   const [inputRarity, setInputRarity] = useState<Rarity | null>(null);
@@ -77,7 +79,7 @@ const GachaSystemPage: React.FC = () => {
         }, {});
         setRarityMap(newMap);
 
-        console.log(response)
+        await fetchActiveVersion();
   
       } else if (response.code === 404) {
         setIsNotFound(true);
@@ -102,10 +104,61 @@ const GachaSystemPage: React.FC = () => {
     }
   }
 
+  // Players are served the published version, edits stay a draft until they are published
+  const fetchActiveVersion = async () => {
+    const versionsEndpoint = `${import.meta.env.VITE_GACHA_MASTER_URL}/id/${gachaSystemId}/version/all`;
+    const response = await handleRequest<ConfigVersion[]>(versionsEndpoint, "GET");
+
+    if (response.code === 200) {
+      setActiveVersion(response.data.find((version) => version.active) ?? null);
+    }
+  }
+
   useEffect(() => {
     fetchData();
   }, [refreshToggle]); 
 
+  const handlePublish = async () => {
+    const publishEndpoint = `${import.meta.env.VITE_GACHA_MASTER_URL}/publish`;
+    try {
+      const response = await handleRequest<ConfigVersion>(publishEndpoint, "POST", {
+        gachaSystemId: parseInt(gachaSystemId),
+      });
+
+      if (response.code === 200) {
+        toast.success(`Version ${response.data.number} is live`);
+        setRefreshToggle(!refreshToggle);
+      } else {
+        toast.error(`Publish failed: ${response.data.message}`);
+      }
+    } catch (error) {
+      toast.error("An error occurred. Please try again.");
+    }
+  };
+
+  const handleDiscard = async () => {
+    const confirmation = window.confirm(`Are you sure you want to discard the draft? Rarities and characters go back to version ${activeVersion?.number}.`);
+    if (!confirmation) {
+      return;
+    }
+
+    const discardEndpoint = `${import.meta.env.VITE_GACHA_MASTER_URL}/draft/discard`;
+    try {
+      const response = await handleRequest<ConfigVersion>(discardEndpoint, "POST", {
+        gachaSystemId: parseInt(gachaSystemId),
+      });
+
+      if (response.code === 200) {
+        toast.success("Draft discarded");
+        setRefreshToggle(!refreshToggle);
+      } else {
+        toast.error(`Discard failed: ${response.data.message}`);
+      }
+    } catch (error) {
+      toast.error("An error occurred. Please try again.");
+    }
+  };
+
   // Mendapatkan data Gacha System dan detailnya
 
   const handleRarityDelete = async (id: number) => {
@@ -256,6 +309,31 @@ const GachaSystemPage: React.FC = () => {
               <RedirectIcon/>
             </Link>
 
+            {/* Publishing */}
+            <div className="mb-6">
+              <div className="flex justify-between items-center mb-2">
+                <h2 className="text-xl font-semibold text-white">Publishing</h2>
+                <div className="flex space-x-2">
+                  <button
+                    onClick={handleDiscard}
+                    disabled={!activeVersion}
+                    className="bg-transparent hover:bg-white text-white hover:bg-opacity-50 outline outline-white outline-1 rounded px-2 py-1 flex items-center justify-center disabled:opacity-50 disabled:hover:bg-transparent">
+                    Discard draft
+                  </button>
+                  <button
+                    onClick={handlePublish}
+                    className="bg-white bg-opacity-80 hover:bg-opacity-100 text-gray-800 rounded px-2 py-1 flex items-center justify-center">
+                    Publish
+                  </button>
+                </div>
+              </div>
+              <p className="text-white text-sm">
+                {activeVersion
+                  ? `Players pull from version ${activeVersion.number}, published ${new Date(activeVersion.createdAt).toLocaleString()}. Changes below are a draft until you publish them.`
+                  : "Not published yet. Players cannot pull until you publish."}
+              </p>
+            </div>
+
             {/* Rarities */}
             <div className="mb-6">
               <div className="flex justify-between  items-center mb-2">
@@ -0,0 +1,8 @@
+export type ConfigVersion = {
+  id: number,
+  number: number,
+  contentHash: string,
+  active: boolean,
+  createdAt: string,
+  message?: string
+};
@@ -4,12 +4,14 @@ export const handleRequest = async <T> (
     url: string,
     method: string,
     body: object | FormData | string = {},
-    headers: Record<string, string> = {}
+    headers: Record<string, string> = {},
+    withAuth: boolean = true
   ): Promise<ApiResponse<T>> => {
     const jwtToken = localStorage.getItem("jwt_token");
   
       // Jika ada JWT token, tambahkan ke headers Authorization
-      if (jwtToken) {
+      // Token dashboard hanya untuk gacha-master, bukan untuk endpoint gacha-pull
+      if (jwtToken && withAuth) {
         headers["Authorization"] = `Bearer ${jwtToken}`;
       }
   
@@ -0,0 +1,23 @@
+package app
+
+import (
+	"crypto/subtle"
+	"gacha-master/client"
+	"gacha-master/exception"
+	"net/http"
+)
+
+// InternalApiKeyMiddleware lets in the other services of the platform, which share the internal
+// API key. The internal endpoints stay closed when no internal API key is configured.
+func InternalApiKeyMiddleware(internalApiKey string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			requestKey := request.Header.Get(client.InternalApiKeyHeader)
+			if internalApiKey == "" || subtle.ConstantTimeCompare([]byte(requestKey), []byte(internalApiKey)) != 1 {
+				panic(exception.NewUnauthorizedError("Invalid internal API key"))
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -39,6 +39,12 @@ func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interfa
 		if badRequestError(writer, request, actualErr) {
 			return
 		}
+		if badGatewayError(writer, request, actualErr) {
+			return
+		}
+		if unauthorizedError(writer, request, actualErr) {
+			return
+		}
 		internalServerError(writer, request, actualErr)
 	} else {
 		internalServerError(writer, request, errors.New(fmt.Sprintf("%v", err)))
@@ -84,6 +90,15 @@ func conflictError(writer http.ResponseWriter, request *http.Request, err error)
 	return false
 }
 
+func badGatewayError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var badGatewayErr *exception.BadGatewayError
+	if errors.As(err, &badGatewayErr) {
+		writeErrorResponse(writer, http.StatusBadGateway, "BAD GATEWAY", badGatewayErr.Error())
+		return true
+	}
+	return false
+}
+
 func badRequestError(writer http.ResponseWriter, request *http.Request, err error) bool {
 	var userErr *exception.BadRequestError
 	if errors.As(err, &userErr) {
@@ -93,6 +108,15 @@ func badRequestError(writer http.ResponseWriter, request *http.Request, err erro
 	return false
 }
 
+func unauthorizedError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var unauthorizedErr *exception.UnauthorizedError
+	if errors.As(err, &unauthorizedErr) {
+		writeErrorResponse(writer, http.StatusUnauthorized, "UNAUTHORIZED", unauthorizedErr.Error())
+		return true
+	}
+	return false
+}
+
 func internalServerError(writer http.ResponseWriter, request *http.Request, err error) {
 	writeErrorResponse(writer, http.StatusInternalServerError, "INTERNAL SERVER ERROR", err.Error())
 }
@@ -15,6 +15,12 @@ func NewRouter(
 	gachaSystemController controller.GachaSystemController,
 	rarityController controller.RarityController,
 	characterController controller.CharacterController,
+	apiKeyController controller.ApiKeyController,
+	currencyController controller.CurrencyController,
+	walletController controller.WalletController,
+	bannerController controller.BannerController,
+	simulationController controller.SimulationController,
+	gachaConfigController controller.GachaConfigController,
 ) http.Handler {
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
@@ -42,6 +48,7 @@ func NewRouter(
 			subRouter.Get("/id/{gachaSystemId}", gachaSystemController.FindById)
 			subRouter.Get("/all", gachaSystemController.FindAll)
 			subRouter.Get("/id/all", gachaSystemController.FindAll)
+			subRouter.Put("/settings/update", gachaSystemController.UpdateSettings)
 
 			subRouter.Post("/character/create", characterController.Create)
 			subRouter.Patch("/character/update", characterController.Update)
@@ -53,9 +60,43 @@ func NewRouter(
 			subRouter.Get("/id/{gachaSystemId}/rarity/all", rarityController.GetAll)
 			subRouter.Delete("/id/{gachaSystemId}/rarity/{rarityId}", rarityController.Delete)
 
+			subRouter.Post("/api-key/create", apiKeyController.Create)
+			subRouter.Get("/id/{gachaSystemId}/api-key/all", apiKeyController.GetAll)
+			subRouter.Delete("/id/{gachaSystemId}/api-key/{apiKeyId}", apiKeyController.Revoke)
+
+			subRouter.Post("/currency/create", currencyController.Create)
+			subRouter.Put("/currency/update", currencyController.Update)
+			subRouter.Get("/id/{gachaSystemId}/currency/all", currencyController.GetAll)
+			subRouter.Delete("/id/{gachaSystemId}/currency/{currencyId}", currencyController.Delete)
+
+			subRouter.Post("/wallet/grant", walletController.Grant)
+			subRouter.Post("/wallet/revoke", walletController.Revoke)
+			subRouter.Get("/id/{gachaSystemId}/wallet/{playerId}", walletController.GetByPlayerId)
+
+			subRouter.Post("/banner/create", bannerController.Create)
+			subRouter.Put("/banner/update", bannerController.Update)
+			subRouter.Get("/id/{gachaSystemId}/banner/all", bannerController.GetAll)
+			subRouter.Delete("/id/{gachaSystemId}/banner/{bannerId}", bannerController.Delete)
+
+			subRouter.Post("/simulate", simulationController.Simulate)
+
+			subRouter.Post("/publish", gachaConfigController.Publish)
+			subRouter.Post("/draft/discard", gachaConfigController.Discard)
+			subRouter.Get("/id/{gachaSystemId}/version/all", gachaConfigController.FindAllVersions)
+			subRouter.Get("/id/{gachaSystemId}/version/diff", gachaConfigController.Diff)
+			subRouter.Get("/id/{gachaSystemId}/version/{versionNumber}", gachaConfigController.FindVersion)
+
 		})
 	})
 
+	// Internal routes are called by the other services of the platform, never by users
+	router.Route("/internal/v1/gacha/{endpointId}", func(subRouter chi.Router) {
+		subRouter.Use(InternalApiKeyMiddleware(os.Getenv("INTERNAL_API_KEY")))
+
+		subRouter.Get("/config", gachaConfigController.FindByEndpointId)
+		subRouter.Get("/config/version/{versionId}", gachaConfigController.FindVersionByEndpointId)
+	})
+
 	// Public routes
 	router.Group(func(router chi.Router) {
 		router.Get("/api/v1/gacha/userId/{userId}", gachaSystemController.FindEndpointByNameAndUserId)
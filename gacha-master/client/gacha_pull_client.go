@@ -0,0 +1,216 @@
+// Package client calls the internal endpoints of gacha-pull, which runs the pull selection and
+// keeps the data of players.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gacha-master/exception"
+	"gacha-master/model/domain"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const InternalApiKeyHeader = "X-Internal-Api-Key"
+
+type GachaPullClient interface {
+	Simulate(ctx context.Context, endpointId string, body interface{}) json.RawMessage
+	InvalidateConfig(ctx context.Context, endpointId string)
+	FindWallet(ctx context.Context, gachaSystemId int, playerId string) ([]domain.Wallet, []domain.WalletLedgerEntry)
+	ApplyWalletEntry(ctx context.Context, entry *domain.WalletLedgerEntry)
+}
+
+type GachaPullClientImpl struct {
+	BaseUrl        string
+	InternalApiKey string
+	HttpClient     *http.Client
+}
+
+// NewGachaPullClient calls the gacha-pull service at baseUrl, like http://gacha-pull:8002, with
+// the internal API key shared by both services.
+func NewGachaPullClient(baseUrl string, internalApiKey string) GachaPullClient {
+	return &GachaPullClientImpl{
+		BaseUrl:        baseUrl,
+		InternalApiKey: internalApiKey,
+		HttpClient:     &http.Client{Timeout: time.Minute},
+	}
+}
+
+type gachaPullResponse struct {
+	Code   int             `json:"code"`
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+}
+
+type gachaPullErrorData struct {
+	Message string `json:"message"`
+}
+
+type walletLedgerData struct {
+	Balances []struct {
+		CurrencyId int   `json:"currencyId"`
+		Balance    int64 `json:"balance"`
+	} `json:"balances"`
+	Ledger []walletLedgerEntryData `json:"ledger"`
+}
+
+type walletLedgerEntryData struct {
+	Id           int64     `json:"id"`
+	CurrencyId   int       `json:"currencyId"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balanceAfter"`
+	Reason       string    `json:"reason"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+type walletEntryBody struct {
+	PlayerId   string `json:"playerId"`
+	CurrencyId int    `json:"currencyId"`
+	Amount     int64  `json:"amount"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+}
+
+func (client *GachaPullClientImpl) Simulate(ctx context.Context, endpointId string, body interface{}) json.RawMessage {
+	return client.post(ctx, fmt.Sprintf("/internal/v1/gacha/%s/simulate", endpointId), body)
+}
+
+// InvalidateConfig tells gacha-pull the configuration it serves for a gacha system changed, so
+// pulls see the change right away. It never fails the change itself: an invalidation that does
+// not arrive is logged, and gacha-pull picks the change up once its cache expires.
+func (client *GachaPullClientImpl) InvalidateConfig(ctx context.Context, endpointId string) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Failed to invalidate the gacha config of %s on gacha-pull: %v", endpointId, err)
+		}
+	}()
+
+	client.post(ctx, fmt.Sprintf("/internal/v1/gacha/%s/config/invalidate", endpointId), nil)
+}
+
+// FindWallet returns the balances of a player and the latest entries of their ledger, newest
+// first.
+func (client *GachaPullClientImpl) FindWallet(ctx context.Context, gachaSystemId int, playerId string) ([]domain.Wallet, []domain.WalletLedgerEntry) {
+	data := client.call(ctx, http.MethodGet, fmt.Sprintf("/internal/v1/gacha-system/%d/wallet/%s", gachaSystemId, url.PathEscape(playerId)), nil)
+
+	var ledger walletLedgerData
+	if err := json.Unmarshal(data, &ledger); err != nil {
+		log.Printf("Failed to read gacha-pull wallet: %v", err)
+		panic(exception.NewBadGatewayError("gacha-pull returned an invalid response"))
+	}
+
+	var wallets []domain.Wallet
+	for _, balance := range ledger.Balances {
+		wallets = append(wallets, domain.Wallet{
+			GachaSystemId: gachaSystemId,
+			PlayerId:      playerId,
+			CurrencyId:    balance.CurrencyId,
+			Balance:       balance.Balance,
+		})
+	}
+
+	var entries []domain.WalletLedgerEntry
+	for _, entryData := range ledger.Ledger {
+		entry := domain.WalletLedgerEntry{GachaSystemId: gachaSystemId, PlayerId: playerId}
+		entryData.applyTo(&entry)
+		entries = append(entries, entry)
+	}
+
+	return wallets, entries
+}
+
+// ApplyWalletEntry credits (positive amount) or debits (negative amount) the wallet of a player in
+// gacha-pull, and fills in the id, balance and time of the recorded entry. A debit larger than the
+// balance is refused by gacha-pull as a bad request.
+func (client *GachaPullClientImpl) ApplyWalletEntry(ctx context.Context, entry *domain.WalletLedgerEntry) {
+	data := client.post(ctx, fmt.Sprintf("/internal/v1/gacha-system/%d/wallet/entries", entry.GachaSystemId), &walletEntryBody{
+		PlayerId:   entry.PlayerId,
+		CurrencyId: entry.CurrencyId,
+		Amount:     entry.Amount,
+		Reason:     entry.Reason,
+		Note:       entry.Note,
+	})
+
+	var entryData walletLedgerEntryData
+	if err := json.Unmarshal(data, &entryData); err != nil {
+		log.Printf("Failed to read gacha-pull wallet entry: %v", err)
+		panic(exception.NewBadGatewayError("gacha-pull returned an invalid response"))
+	}
+	entryData.applyTo(entry)
+}
+
+func (entryData *walletLedgerEntryData) applyTo(entry *domain.WalletLedgerEntry) {
+	entry.Id = entryData.Id
+	entry.CurrencyId = entryData.CurrencyId
+	entry.Amount = entryData.Amount
+	entry.BalanceAfter = entryData.BalanceAfter
+	entry.Reason = entryData.Reason
+	entry.Note = entryData.Note
+	entry.CreatedAt = entryData.CreatedAt
+}
+
+func (client *GachaPullClientImpl) post(ctx context.Context, path string, body interface{}) json.RawMessage {
+	return client.call(ctx, http.MethodPost, path, body)
+}
+
+// call sends a request to an internal endpoint, with body unless it is nil, and returns the data
+// of its response. Client errors of gacha-pull are passed on, anything else means gacha-pull could
+// not be reached or failed.
+func (client *GachaPullClientImpl) call(ctx context.Context, method string, path string, body interface{}) json.RawMessage {
+	if client.BaseUrl == "" || client.InternalApiKey == "" {
+		panic(exception.NewBadGatewayError("gacha-pull internal API is not configured"))
+	}
+
+	var requestBody []byte
+	if body != nil {
+		var err error
+		requestBody, err = json.Marshal(body)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, client.BaseUrl+path, bytes.NewReader(requestBody))
+	if err != nil {
+		panic(err)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set(InternalApiKeyHeader, client.InternalApiKey)
+
+	response, err := client.HttpClient.Do(request)
+	if err != nil {
+		log.Printf("Failed to call gacha-pull: %v", err)
+		panic(exception.NewBadGatewayError("gacha-pull could not be reached"))
+	}
+	defer response.Body.Close()
+
+	var pullResponse gachaPullResponse
+	if err := json.NewDecoder(response.Body).Decode(&pullResponse); err != nil {
+		log.Printf("Failed to read gacha-pull response: %v", err)
+		panic(exception.NewBadGatewayError("gacha-pull returned an invalid response"))
+	}
+
+	if response.StatusCode == http.StatusOK {
+		return pullResponse.Data
+	}
+
+	var errorData gachaPullErrorData
+	_ = json.Unmarshal(pullResponse.Data, &errorData)
+
+	switch response.StatusCode {
+	case http.StatusBadRequest:
+		panic(exception.NewBadRequestError(errorData.Message))
+	case http.StatusNotFound:
+		panic(exception.NewNotFoundError(errorData.Message))
+	default:
+		log.Printf("gacha-pull responded %d: %s", response.StatusCode, errorData.Message)
+		panic(exception.NewBadGatewayError("gacha-pull failed to handle the request"))
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type ApiKeyController interface {
+	Create(writer http.ResponseWriter, request *http.Request)
+	GetAll(writer http.ResponseWriter, request *http.Request)
+	Revoke(writer http.ResponseWriter, request *http.Request)
+}
+
+type ApiKeyControllerImpl struct {
+	ApiKeyService service.ApiKeyService
+}
+
+func NewApiKeyController(apiKeyService service.ApiKeyService) ApiKeyController {
+	return &ApiKeyControllerImpl{
+		ApiKeyService: apiKeyService,
+	}
+}
+
+func (controller *ApiKeyControllerImpl) Create(writer http.ResponseWriter, request *http.Request) {
+	apiKeyCreateRequest := web.ApiKeyCreateRequest{}
+	helper.ReadFromRequestBody(request, &apiKeyCreateRequest)
+
+	apiKeyResponse := controller.ApiKeyService.Create(request.Context(), &apiKeyCreateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   apiKeyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImpl) GetAll(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	apiKeysResponse := controller.ApiKeyService.FindAllByGachaSystemId(request.Context(), gachaSystemId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   apiKeysResponse,
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *ApiKeyControllerImpl) Revoke(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	apiKeyIdStr := chi.URLParam(request, "apiKeyId")
+	apiKeyId, _ := strconv.Atoi(apiKeyIdStr)
+
+	apiKeyResponse := controller.ApiKeyService.Revoke(request.Context(), apiKeyId, gachaSystemId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   apiKeyResponse,
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"fmt"
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type BannerController interface {
+	Create(writer http.ResponseWriter, request *http.Request)
+	Update(writer http.ResponseWriter, request *http.Request)
+	Delete(writer http.ResponseWriter, request *http.Request)
+	GetAll(writer http.ResponseWriter, request *http.Request)
+}
+
+type BannerControllerImpl struct {
+	BannerService service.BannerService
+}
+
+func NewBannerController(bannerService service.BannerService) BannerController {
+	return &BannerControllerImpl{
+		BannerService: bannerService,
+	}
+}
+
+func (controller *BannerControllerImpl) Create(writer http.ResponseWriter, request *http.Request) {
+	bannerCreateRequest := web.BannerCreateRequest{}
+	helper.ReadFromRequestBody(request, &bannerCreateRequest)
+
+	bannerResponse := controller.BannerService.Create(request.Context(), &bannerCreateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   bannerResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *BannerControllerImpl) Update(writer http.ResponseWriter, request *http.Request) {
+	bannerUpdateRequest := web.BannerUpdateRequest{}
+	helper.ReadFromRequestBody(request, &bannerUpdateRequest)
+
+	bannerResponse := controller.BannerService.Update(request.Context(), &bannerUpdateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   bannerResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *BannerControllerImpl) Delete(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	bannerIdStr := chi.URLParam(request, "bannerId")
+	bannerId, _ := strconv.Atoi(bannerIdStr)
+
+	controller.BannerService.Delete(request.Context(), bannerId, gachaSystemId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data: map[string]interface{}{
+			"message": fmt.Sprintf("Banner with ID %d successfully deleted", bannerId),
+		},
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *BannerControllerImpl) GetAll(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	bannersResponse := controller.BannerService.FindAllByGachaSystemId(request.Context(), gachaSystemId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   bannersResponse,
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
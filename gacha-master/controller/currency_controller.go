@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"fmt"
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type CurrencyController interface {
+	Create(writer http.ResponseWriter, request *http.Request)
+	Update(writer http.ResponseWriter, request *http.Request)
+	Delete(writer http.ResponseWriter, request *http.Request)
+	GetAll(writer http.ResponseWriter, request *http.Request)
+}
+
+type CurrencyControllerImpl struct {
+	CurrencyService service.CurrencyService
+}
+
+func NewCurrencyController(currencyService service.CurrencyService) CurrencyController {
+	return &CurrencyControllerImpl{
+		CurrencyService: currencyService,
+	}
+}
+
+func (controller *CurrencyControllerImpl) Create(writer http.ResponseWriter, request *http.Request) {
+	currencyCreateRequest := web.CurrencyCreateRequest{}
+	helper.ReadFromRequestBody(request, &currencyCreateRequest)
+
+	currencyResponse := controller.CurrencyService.Create(request.Context(), &currencyCreateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   currencyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CurrencyControllerImpl) Update(writer http.ResponseWriter, request *http.Request) {
+	currencyUpdateRequest := web.CurrencyUpdateRequest{}
+	helper.ReadFromRequestBody(request, &currencyUpdateRequest)
+
+	currencyResponse := controller.CurrencyService.Update(request.Context(), &currencyUpdateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   currencyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CurrencyControllerImpl) Delete(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	currencyIdStr := chi.URLParam(request, "currencyId")
+	currencyId, _ := strconv.Atoi(currencyIdStr)
+
+	controller.CurrencyService.Delete(request.Context(), currencyId, gachaSystemId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data: map[string]interface{}{
+			"message": fmt.Sprintf("Currency with ID %d successfully deleted", currencyId),
+		},
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CurrencyControllerImpl) GetAll(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	currenciesResponse := controller.CurrencyService.FindAllByGachaSystemId(request.Context(), gachaSystemId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   currenciesResponse,
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type GachaConfigController interface {
+	FindByEndpointId(writer http.ResponseWriter, request *http.Request)
+	FindVersionByEndpointId(writer http.ResponseWriter, request *http.Request)
+	Publish(writer http.ResponseWriter, request *http.Request)
+	Discard(writer http.ResponseWriter, request *http.Request)
+	FindAllVersions(writer http.ResponseWriter, request *http.Request)
+	FindVersion(writer http.ResponseWriter, request *http.Request)
+	Diff(writer http.ResponseWriter, request *http.Request)
+}
+
+type GachaConfigControllerImpl struct {
+	GachaConfigService service.GachaConfigService
+}
+
+func NewGachaConfigController(gachaConfigService service.GachaConfigService) GachaConfigController {
+	return &GachaConfigControllerImpl{
+		GachaConfigService: gachaConfigService,
+	}
+}
+
+// FindByEndpointId answers with the config snapshot and its version as ETag. A caller that sends
+// the ETag of the snapshot it holds back gets 304 Not Modified while the snapshot is unchanged.
+func (controller *GachaConfigControllerImpl) FindByEndpointId(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	configResponse := controller.GachaConfigService.FindByEndpointId(request.Context(), endpointId)
+
+	etag := `"` + configResponse.Version + `"`
+	writer.Header().Set("ETag", etag)
+	writer.Header().Set("Cache-Control", "no-cache")
+
+	if request.Header.Get("If-None-Match") == etag {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   configResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *GachaConfigControllerImpl) FindVersionByEndpointId(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	versionIdStr := chi.URLParam(request, "versionId")
+	versionId, _ := strconv.Atoi(versionIdStr)
+
+	configResponse := controller.GachaConfigService.FindVersionByEndpointId(request.Context(), endpointId, versionId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   configResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *GachaConfigControllerImpl) Publish(writer http.ResponseWriter, request *http.Request) {
+	publishRequest := web.GachaConfigPublishRequest{}
+	helper.ReadFromRequestBody(request, &publishRequest)
+
+	versionResponse := controller.GachaConfigService.Publish(request.Context(), &publishRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   versionResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *GachaConfigControllerImpl) Discard(writer http.ResponseWriter, request *http.Request) {
+	discardRequest := web.GachaConfigDiscardRequest{}
+	helper.ReadFromRequestBody(request, &discardRequest)
+
+	versionResponse := controller.GachaConfigService.Discard(request.Context(), &discardRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   versionResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *GachaConfigControllerImpl) FindAllVersions(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	versionsResponse := controller.GachaConfigService.FindAllVersions(request.Context(), gachaSystemId)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   versionsResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *GachaConfigControllerImpl) FindVersion(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	versionNumberStr := chi.URLParam(request, "versionNumber")
+	versionNumber, _ := strconv.Atoi(versionNumberStr)
+
+	versionResponse := controller.GachaConfigService.FindVersion(request.Context(), gachaSystemId, versionNumber)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   versionResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// Diff compares the versions given by the from and to query parameters. Leaving to out compares
+// with the draft.
+func (controller *GachaConfigControllerImpl) Diff(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	from, _ := strconv.Atoi(request.URL.Query().Get("from"))
+	to, _ := strconv.Atoi(request.URL.Query().Get("to"))
+
+	diffResponse := controller.GachaConfigService.Diff(request.Context(), gachaSystemId, from, to)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   diffResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -18,6 +18,7 @@ type GachaSystemController interface {
 	Delete(writer http.ResponseWriter, request *http.Request)
 	FindAll(writer http.ResponseWriter, request *http.Request)
 	FindEndpointByNameAndUserId(writer http.ResponseWriter, request *http.Request)
+	UpdateSettings(writer http.ResponseWriter, request *http.Request)
 }
 
 type GachaSystemControllerImpl struct {
@@ -146,3 +147,17 @@ func (controller *GachaSystemControllerImpl) FindEndpointByNameAndUserId(writer
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
+
+func (controller *GachaSystemControllerImpl) UpdateSettings(writer http.ResponseWriter, request *http.Request) {
+	settingsUpdateRequest := web.GachaSystemSettingsUpdateRequest{}
+	helper.ReadFromRequestBody(request, &settingsUpdateRequest)
+
+	settingsResponse := controller.GachaSystemService.UpdateSettings(request.Context(), &settingsUpdateRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   settingsResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/service"
+	"net/http"
+)
+
+type SimulationController interface {
+	Simulate(writer http.ResponseWriter, request *http.Request)
+}
+
+type SimulationControllerImpl struct {
+	SimulationService service.SimulationService
+}
+
+func NewSimulationController(simulationService service.SimulationService) SimulationController {
+	return &SimulationControllerImpl{
+		SimulationService: simulationService,
+	}
+}
+
+func (controller *SimulationControllerImpl) Simulate(writer http.ResponseWriter, request *http.Request) {
+	simulationRequest := web.SimulationRequest{}
+	helper.ReadFromRequestBody(request, &simulationRequest)
+
+	simulationResponse := controller.SimulationService.Simulate(request.Context(), &simulationRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   simulationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
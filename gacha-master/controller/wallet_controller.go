@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type WalletController interface {
+	Grant(writer http.ResponseWriter, request *http.Request)
+	Revoke(writer http.ResponseWriter, request *http.Request)
+	GetByPlayerId(writer http.ResponseWriter, request *http.Request)
+}
+
+type WalletControllerImpl struct {
+	WalletService service.WalletService
+}
+
+func NewWalletController(walletService service.WalletService) WalletController {
+	return &WalletControllerImpl{
+		WalletService: walletService,
+	}
+}
+
+func (controller *WalletControllerImpl) Grant(writer http.ResponseWriter, request *http.Request) {
+	walletAdjustRequest := web.WalletAdjustRequest{}
+	helper.ReadFromRequestBody(request, &walletAdjustRequest)
+
+	entryResponse := controller.WalletService.Grant(request.Context(), &walletAdjustRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   entryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *WalletControllerImpl) Revoke(writer http.ResponseWriter, request *http.Request) {
+	walletAdjustRequest := web.WalletAdjustRequest{}
+	helper.ReadFromRequestBody(request, &walletAdjustRequest)
+
+	entryResponse := controller.WalletService.Revoke(request.Context(), &walletAdjustRequest)
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   entryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *WalletControllerImpl) GetByPlayerId(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemIdStr := chi.URLParam(request, "gachaSystemId")
+	gachaSystemId, _ := strconv.Atoi(gachaSystemIdStr)
+
+	playerId := chi.URLParam(request, "playerId")
+
+	walletResponse := controller.WalletService.FindByGachaSystemIdAndPlayerId(request.Context(), gachaSystemId, playerId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   walletResponse,
+	}
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -13,6 +13,20 @@ CREATE TABLE gacha_system (
   user_id INTEGER NOT NULL,
   name VARCHAR(100) NOT NULL,
   endpoint_id TEXT NOT NULL UNIQUE,
+  guaranteed_rarity_id INTEGER,
+  guarantee_pull_count INTEGER DEFAULT 10 NOT NULL CHECK (guarantee_pull_count > 0),
+  provably_fair BOOLEAN DEFAULT FALSE NOT NULL,
+  private BOOLEAN DEFAULT FALSE NOT NULL,
+  endpoint_rate_limit INTEGER DEFAULT 0 NOT NULL CHECK (endpoint_rate_limit >= 0),
+  api_key_rate_limit INTEGER DEFAULT 0 NOT NULL CHECK (api_key_rate_limit >= 0),
+  player_rate_limit INTEGER DEFAULT 0 NOT NULL CHECK (player_rate_limit >= 0),
+  multi_pull_discount NUMERIC(5,2) DEFAULT 0 NOT NULL CHECK (multi_pull_discount >= 0 AND multi_pull_discount <= 100),
+  multi_pull_discount_count INTEGER DEFAULT 10 NOT NULL CHECK (multi_pull_discount_count > 0),
+  mode VARCHAR(20) DEFAULT 'standard' NOT NULL CHECK (mode IN ('standard', 'box')),
+  active_config_version_id INTEGER,
+  -- Set by migrations for gacha systems created before config versions, gacha-master publishes
+  -- their first version on startup
+  initial_publish_pending BOOLEAN DEFAULT FALSE NOT NULL,
   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
   FOREIGN KEY (user_id)
       REFERENCES users(id)
@@ -24,7 +38,16 @@ CREATE TABLE rarity (
     id SERIAL NOT NULL,
     name VARCHAR(50) NOT NULL,
     chance NUMERIC(7,2) NOT NULL CHECK (chance >= 0 AND chance <= 100),
+    pity_threshold INTEGER DEFAULT 0 NOT NULL CHECK (pity_threshold >= 0),
+    soft_pity_start INTEGER DEFAULT 0 NOT NULL CHECK (soft_pity_start >= 0),
+    soft_pity_step NUMERIC(7,2) DEFAULT 0 NOT NULL CHECK (soft_pity_step >= 0 AND soft_pity_step <= 100),
+    featured_chance NUMERIC(5,2) DEFAULT 50 NOT NULL CHECK (featured_chance >= 0 AND featured_chance <= 100),
+    duplicate_conversion VARCHAR(20) DEFAULT 'none' NOT NULL CHECK (duplicate_conversion IN ('none', 'shards', 'constellation')),
+    duplicate_currency_id INTEGER,
+    duplicate_shards INTEGER DEFAULT 0 NOT NULL CHECK (duplicate_shards >= 0),
+    max_constellation INTEGER DEFAULT 0 NOT NULL CHECK (max_constellation >= 0),
     created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMPTZ,
     PRIMARY KEY (gacha_system_id, id),
     FOREIGN KEY (gacha_system_id)
         REFERENCES gacha_system(id)
@@ -37,7 +60,12 @@ CREATE TABLE character (
    rarity_id INTEGER NOT NULL,
    name VARCHAR(100) NOT NULL,
    image_url TEXT,
+   featured BOOLEAN DEFAULT FALSE NOT NULL,
+   weight NUMERIC(9,2) DEFAULT 1 NOT NULL CHECK (weight > 0),
+   box_quantity INTEGER DEFAULT 1 NOT NULL CHECK (box_quantity >= 0),
+   box_key BOOLEAN DEFAULT FALSE NOT NULL,
    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   deleted_at TIMESTAMPTZ,
    PRIMARY KEY (gacha_system_id, id),
    FOREIGN KEY (gacha_system_id)
        REFERENCES gacha_system(id)
@@ -45,4 +73,73 @@ CREATE TABLE character (
    FOREIGN KEY (gacha_system_id, rarity_id)
        REFERENCES rarity(gacha_system_id, id)
        ON DELETE CASCADE
-);
\ No newline at end of file
+);
+
+-- Rarities, characters and banners are the draft of a gacha system, pulls are served from its
+-- published config versions. Items deleted from the draft are marked by deleted_at until the next
+-- publish, so a discard can bring them back. The data of players is kept by gacha-pull in its own
+-- database, see gacha-pull/migrations.
+CREATE TABLE api_key (
+   id SERIAL PRIMARY KEY,
+   gacha_system_id INTEGER NOT NULL,
+   name VARCHAR(100) NOT NULL,
+   key_prefix VARCHAR(16) NOT NULL,
+   key_hash VARCHAR(64) NOT NULL UNIQUE,
+   expires_at TIMESTAMPTZ,
+   revoked_at TIMESTAMPTZ,
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   FOREIGN KEY (gacha_system_id)
+       REFERENCES gacha_system(id)
+       ON DELETE CASCADE
+);
+
+CREATE TABLE currency (
+   gacha_system_id INTEGER NOT NULL,
+   id SERIAL NOT NULL,
+   name VARCHAR(50) NOT NULL,
+   pull_cost INTEGER DEFAULT 0 NOT NULL CHECK (pull_cost >= 0),
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   PRIMARY KEY (gacha_system_id, id),
+   FOREIGN KEY (gacha_system_id)
+       REFERENCES gacha_system(id)
+       ON DELETE CASCADE
+);
+
+CREATE TABLE banner (
+   id SERIAL PRIMARY KEY,
+   gacha_system_id INTEGER NOT NULL,
+   name VARCHAR(100) NOT NULL,
+   starts_at TIMESTAMPTZ NOT NULL,
+   ends_at TIMESTAMPTZ NOT NULL,
+   timezone VARCHAR(64) DEFAULT 'UTC' NOT NULL,
+   featured_character_ids INTEGER[] DEFAULT '{}' NOT NULL,
+   spark_threshold INTEGER DEFAULT 0 NOT NULL CHECK (spark_threshold >= 0),
+   spark_currency_id INTEGER,
+   spark_conversion INTEGER DEFAULT 0 NOT NULL CHECK (spark_conversion >= 0),
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   deleted_at TIMESTAMPTZ,
+   CHECK (ends_at > starts_at),
+   FOREIGN KEY (gacha_system_id)
+       REFERENCES gacha_system(id)
+       ON DELETE CASCADE
+);
+
+CREATE INDEX banner_schedule_idx ON banner (gacha_system_id, starts_at);
+
+-- Every publish of a gacha system freezes its configuration as the next numbered version
+CREATE TABLE gacha_config_version (
+   id SERIAL PRIMARY KEY,
+   gacha_system_id INTEGER NOT NULL,
+   number INTEGER NOT NULL CHECK (number > 0),
+   content_hash CHAR(64) NOT NULL,
+   snapshot JSONB NOT NULL,
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   UNIQUE (gacha_system_id, number),
+   FOREIGN KEY (gacha_system_id)
+       REFERENCES gacha_system(id)
+       ON DELETE CASCADE
+);
+
+ALTER TABLE gacha_system ADD FOREIGN KEY (active_config_version_id)
+   REFERENCES gacha_config_version(id)
+   ON DELETE SET NULL;
@@ -0,0 +1,29 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	ApiKeyPrefix       = "gk_"
+	apiKeyBytes        = 32
+	apiKeyPrefixLength = len(ApiKeyPrefix) + 8
+)
+
+// GenerateApiKey returns a new random API key together with the prefix shown in key listings.
+func GenerateApiKey() (string, string) {
+	key := make([]byte, apiKeyBytes)
+	_, err := rand.Read(key)
+	PanicIfError(err, "Failed to generate API key")
+
+	apiKey := ApiKeyPrefix + hex.EncodeToString(key)
+	return apiKey, apiKey[:apiKeyPrefixLength]
+}
+
+// HashApiKey returns the hex encoded SHA-256 hash under which an API key is stored.
+func HashApiKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
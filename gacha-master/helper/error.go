@@ -10,9 +10,14 @@ func PanicIfError(err error, message string) {
 }
 
 const (
-	ErrBeginTransaction    = "Failed to begin database transaction"
-	ErrUserNotFound        = "User not found"
-	ErrGachaSystemNotFound = "Gacha system not found"
-	ErrCharacterNotFound   = "Character not found"
-	ErrRarityNotFound      = "Rarity not found"
+	ErrBeginTransaction        = "Failed to begin database transaction"
+	ErrUserNotFound            = "User not found"
+	ErrGachaSystemNotFound     = "Gacha system not found"
+	ErrCharacterNotFound       = "Character not found"
+	ErrRarityNotFound          = "Rarity not found"
+	ErrApiKeyNotFound          = "API key not found"
+	ErrCurrencyNotFound        = "Currency not found"
+	ErrBannerNotFound          = "Banner not found"
+	ErrConfigVersionNotFound   = "Config version not found"
+	ErrGachaSystemNotPublished = "Gacha system is not published yet"
 )
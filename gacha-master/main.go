@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"gacha-master/app"
+	"gacha-master/client"
 	"gacha-master/controller"
 	"gacha-master/helper"
 	"gacha-master/repository"
@@ -9,6 +11,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	_ "github.com/joho/godotenv/autoload"
 	"net/http"
+	"os"
 )
 
 func main() {
@@ -20,18 +23,41 @@ func main() {
 	gachaSystemRepository := repository.NewGachaSystemRepository(dbpool)
 	rarityRepository := repository.NewRarityRepository(dbpool)
 	characterRepository := repository.NewCharacterRepository(dbpool)
+	apiKeyRepository := repository.NewApiKeyRepository(dbpool)
+	currencyRepository := repository.NewCurrencyRepository(dbpool)
+	bannerRepository := repository.NewBannerRepository(dbpool)
+	gachaConfigVersionRepository := repository.NewGachaConfigVersionRepository(dbpool)
 
-	gachaSystemService := service.NewGachaSystemService(gachaSystemRepository, rarityRepository, characterRepository, validate)
-	rarityService := service.NewRarityService(rarityRepository, gachaSystemRepository, validate)
+	gachaPullClient := client.NewGachaPullClient(os.Getenv("GACHA_PULL_INTERNAL_URL"), os.Getenv("INTERNAL_API_KEY"))
+
+	gachaSystemService := service.NewGachaSystemService(gachaSystemRepository, rarityRepository, characterRepository, gachaPullClient, validate)
+	rarityService := service.NewRarityService(rarityRepository, gachaSystemRepository, currencyRepository, validate)
 	characterService := service.NewCharacterService(characterRepository, rarityRepository, gachaSystemRepository, validate)
+	apiKeyService := service.NewApiKeyService(apiKeyRepository, gachaSystemRepository, gachaPullClient, validate)
+	currencyService := service.NewCurrencyService(currencyRepository, gachaSystemRepository, gachaPullClient, validate)
+	walletService := service.NewWalletService(currencyRepository, gachaSystemRepository, gachaPullClient, validate)
+	bannerService := service.NewBannerService(bannerRepository, characterRepository, gachaSystemRepository, currencyRepository, validate)
+	simulationService := service.NewSimulationService(gachaSystemRepository, rarityRepository, characterRepository, bannerRepository,
+		gachaPullClient, validate)
+	gachaConfigService := service.NewGachaConfigService(gachaSystemRepository, rarityRepository, characterRepository, bannerRepository,
+		gachaConfigVersionRepository, currencyRepository, apiKeyRepository, gachaPullClient, validate)
+	gachaConfigService.PublishPending(context.Background())
+
 	uploaderService := service.NewUploaderServiceImpl()
 	defer uploaderService.Close()
 
 	gachaSystemController := controller.NewGachaSystemController(gachaSystemService, rarityService, characterService, uploaderService)
 	rarityController := controller.NewRarityController(rarityService)
 	characterController := controller.NewCharacterController(characterService, uploaderService)
+	apiKeyController := controller.NewApiKeyController(apiKeyService)
+	currencyController := controller.NewCurrencyController(currencyService)
+	walletController := controller.NewWalletController(walletService)
+	bannerController := controller.NewBannerController(bannerService)
+	simulationController := controller.NewSimulationController(simulationService)
+	gachaConfigController := controller.NewGachaConfigController(gachaConfigService)
 
-	router := app.NewRouter(gachaSystemController, rarityController, characterController)
+	router := app.NewRouter(gachaSystemController, rarityController, characterController, apiKeyController,
+		currencyController, walletController, bannerController, simulationController, gachaConfigController)
 
 	server := http.Server{
 		Addr:    ":8001",
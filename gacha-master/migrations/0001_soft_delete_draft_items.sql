@@ -0,0 +1,9 @@
+-- Items deleted from the draft of a gacha system are marked as deleted until the next publish
+-- instead of being deleted, so discarding the draft can bring them back. db_init.sql already
+-- creates the new layout, this brings databases created before it up to date.
+BEGIN;
+
+ALTER TABLE rarity ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE character ADD COLUMN deleted_at TIMESTAMPTZ;
+
+COMMIT;
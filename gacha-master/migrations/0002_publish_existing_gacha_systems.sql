@@ -0,0 +1,11 @@
+-- Gacha systems are only served once a config version is published. Those created before config
+-- versions were served straight from their rows, so they are marked for gacha-master to publish
+-- their current configuration as version 1 on its next startup. db_init.sql already creates the
+-- new layout, this brings databases created before it up to date.
+BEGIN;
+
+ALTER TABLE gacha_system ADD COLUMN IF NOT EXISTS initial_publish_pending BOOLEAN DEFAULT FALSE NOT NULL;
+
+UPDATE gacha_system SET initial_publish_pending = TRUE WHERE active_config_version_id IS NULL;
+
+COMMIT;
@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+type ApiKey struct {
+	Id            int
+	GachaSystemId int
+	Name          string
+	Prefix        string
+	KeyHash       string
+	ExpiresAt     *time.Time
+	RevokedAt     *time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,8 @@
+package domain
+
+type Currency struct {
+	Id            int
+	Name          string
+	PullCost      int
+	GachaSystemId int
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// A GachaConfigVersion is the configuration of a gacha system frozen at a publish. Versions are
+// numbered from 1 per gacha system and never change once stored, only the active one is served
+// to gacha-pull.
+type GachaConfigVersion struct {
+	Id            int
+	GachaSystemId int
+	Number        int
+	ContentHash   string
+	Snapshot      []byte
+	Active        bool
+	CreatedAt     time.Time
+}
@@ -1,8 +1,25 @@
 package domain
 
+// A standard gacha system pulls from an endless pool by rarity chance. A box gacha system gives
+// every player a box holding a fixed quantity of each character, drawn without replacement.
+const (
+	GachaSystemModeStandard = "standard"
+	GachaSystemModeBox      = "box"
+)
+
 type GachaSystem struct {
-	Id         int
-	Name       string
-	EndpointId string
-	UserId     int
+	Id                     int
+	Name                   string
+	EndpointId             string
+	UserId                 int
+	GuaranteedRarityId     int
+	GuaranteePullCount     int
+	ProvablyFair           bool
+	Private                bool
+	EndpointRateLimit      int
+	ApiKeyRateLimit        int
+	PlayerRateLimit        int
+	MultiPullDiscount      float32
+	MultiPullDiscountCount int
+	Mode                   string
 }
@@ -1,8 +1,27 @@
 package domain
 
+// How duplicates of a character the player already owns are converted. Shards credits the
+// duplicate shards in the duplicate currency for every duplicate, constellation raises the
+// constellation level of the character up to the max constellation and converts duplicates past
+// that into shards.
+const (
+	DuplicateConversionNone          = "none"
+	DuplicateConversionShards        = "shards"
+	DuplicateConversionConstellation = "constellation"
+)
+
 type Rarity struct {
-	Id            int
-	Name          string
-	Chance        float32
-	GachaSystemId int
+	Id             int
+	Name           string
+	Chance         float32
+	PityThreshold  int
+	SoftPityStart  int
+	SoftPityStep   float32
+	FeaturedChance float32
+	GachaSystemId  int
+
+	DuplicateConversion string
+	DuplicateCurrencyId *int
+	DuplicateShards     int
+	MaxConstellation    int
 }
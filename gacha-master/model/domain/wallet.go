@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type Wallet struct {
+	GachaSystemId int
+	PlayerId      string
+	CurrencyId    int
+	Balance       int64
+}
+
+// WalletLedgerEntry records one credit (positive amount) or debit (negative amount) of a wallet.
+type WalletLedgerEntry struct {
+	Id            int64
+	GachaSystemId int
+	PlayerId      string
+	CurrencyId    int
+	Amount        int64
+	BalanceAfter  int64
+	Reason        string
+	Note          string
+	CreatedAt     time.Time
+}
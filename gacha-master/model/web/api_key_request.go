@@ -0,0 +1,9 @@
+package web
+
+import "time"
+
+type ApiKeyCreateRequest struct {
+	GachaSystemId int        `json:"gachaSystemId" validate:"required"`
+	Name          string     `json:"name" validate:"required,max=100"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+}
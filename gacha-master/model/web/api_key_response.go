@@ -0,0 +1,45 @@
+package web
+
+import (
+	"gacha-master/model/domain"
+	"time"
+)
+
+type ApiKeyResponse struct {
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Active    bool       `json:"active"`
+}
+
+// ApiKeyCreateResponse is the only response that carries the key itself, it is stored hashed
+// and cannot be shown again.
+type ApiKeyCreateResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+func ToApiKeyResponse(apiKey *domain.ApiKey) *ApiKeyResponse {
+	return &ApiKeyResponse{
+		Id:        apiKey.Id,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		ExpiresAt: apiKey.ExpiresAt,
+		RevokedAt: apiKey.RevokedAt,
+		CreatedAt: apiKey.CreatedAt,
+		Active:    apiKey.RevokedAt == nil && (apiKey.ExpiresAt == nil || apiKey.ExpiresAt.After(time.Now())),
+	}
+}
+
+func ToApiKeysResponse(apiKeys []domain.ApiKey) []ApiKeyResponse {
+	var apiKeyResponses []ApiKeyResponse
+	for _, apiKey := range apiKeys {
+		apiKeyResponse := ToApiKeyResponse(&apiKey)
+		apiKeyResponses = append(apiKeyResponses, *apiKeyResponse)
+	}
+
+	return apiKeyResponses
+}
@@ -0,0 +1,29 @@
+package web
+
+// Banner times are local times in the timezone of the banner, like 2024-07-01T00:00:00, so a
+// banner scheduled at midnight flips over at midnight of that timezone. A time with an explicit
+// offset is taken as is.
+type BannerCreateRequest struct {
+	GachaSystemId        int    `json:"gachaSystemId" validate:"required"`
+	Name                 string `json:"name" validate:"required,max=100"`
+	StartsAt             string `json:"startsAt" validate:"required"`
+	EndsAt               string `json:"endsAt" validate:"required"`
+	Timezone             string `json:"timezone" validate:"omitempty,timezone"`
+	FeaturedCharacterIds []int  `json:"featuredCharacterIds"`
+	SparkThreshold       int    `json:"sparkThreshold" validate:"gte=0"`
+	SparkCurrencyId      *int   `json:"sparkCurrencyId"`
+	SparkConversion      int    `json:"sparkConversion" validate:"gte=0"`
+}
+
+type BannerUpdateRequest struct {
+	Id                   int    `json:"id" validate:"required"`
+	GachaSystemId        int    `json:"gachaSystemId" validate:"required"`
+	Name                 string `json:"name" validate:"required,max=100"`
+	StartsAt             string `json:"startsAt" validate:"required"`
+	EndsAt               string `json:"endsAt" validate:"required"`
+	Timezone             string `json:"timezone" validate:"omitempty,timezone"`
+	FeaturedCharacterIds []int  `json:"featuredCharacterIds"`
+	SparkThreshold       int    `json:"sparkThreshold" validate:"gte=0"`
+	SparkCurrencyId      *int   `json:"sparkCurrencyId"`
+	SparkConversion      int    `json:"sparkConversion" validate:"gte=0"`
+}
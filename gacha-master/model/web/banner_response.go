@@ -0,0 +1,69 @@
+package web
+
+import (
+	"gacha-master/model/domain"
+	"time"
+)
+
+const (
+	BannerStatusScheduled = "scheduled"
+	BannerStatusActive    = "active"
+	BannerStatusEnded     = "ended"
+)
+
+type BannerResponse struct {
+	Id                   int       `json:"id"`
+	Name                 string    `json:"name"`
+	StartsAt             time.Time `json:"startsAt"`
+	EndsAt               time.Time `json:"endsAt"`
+	Timezone             string    `json:"timezone"`
+	FeaturedCharacterIds []int     `json:"featuredCharacterIds"`
+	SparkThreshold       int       `json:"sparkThreshold"`
+	SparkCurrencyId      *int      `json:"sparkCurrencyId"`
+	SparkConversion      int       `json:"sparkConversion"`
+	Status               string    `json:"status"`
+}
+
+// ToBannerResponse shows the banner times in the timezone of the banner.
+func ToBannerResponse(banner *domain.Banner) *BannerResponse {
+	location, err := time.LoadLocation(banner.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	now := time.Now()
+	status := BannerStatusActive
+	if now.Before(banner.StartsAt) {
+		status = BannerStatusScheduled
+	} else if !now.Before(banner.EndsAt) {
+		status = BannerStatusEnded
+	}
+
+	featuredCharacterIds := banner.FeaturedCharacterIds
+	if featuredCharacterIds == nil {
+		featuredCharacterIds = []int{}
+	}
+
+	return &BannerResponse{
+		Id:                   banner.Id,
+		Name:                 banner.Name,
+		StartsAt:             banner.StartsAt.In(location),
+		EndsAt:               banner.EndsAt.In(location),
+		Timezone:             banner.Timezone,
+		FeaturedCharacterIds: featuredCharacterIds,
+		SparkThreshold:       banner.SparkThreshold,
+		SparkCurrencyId:      banner.SparkCurrencyId,
+		SparkConversion:      banner.SparkConversion,
+		Status:               status,
+	}
+}
+
+func ToBannersResponse(banners []domain.Banner) []BannerResponse {
+	var bannerResponses []BannerResponse
+	for _, banner := range banners {
+		bannerResponse := ToBannerResponse(&banner)
+		bannerResponses = append(bannerResponses, *bannerResponse)
+	}
+
+	return bannerResponses
+}
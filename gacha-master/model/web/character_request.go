@@ -9,10 +9,19 @@ import (
 	"strconv"
 )
 
+const (
+	DefaultCharacterWeight      = 1
+	DefaultCharacterBoxQuantity = 1
+)
+
 type CharacterCreateRequest struct {
-	GachaSystemId int    `form:"gachaSystemId" validate:"required"`
-	Name          string `form:"name" validate:"required"`
-	RarityId      int    `form:"rarityId" validate:"required"`
+	GachaSystemId int     `form:"gachaSystemId" validate:"required"`
+	Name          string  `form:"name" validate:"required"`
+	RarityId      int     `form:"rarityId" validate:"required"`
+	Featured      bool    `form:"featured"`
+	Weight        float32 `form:"weight" validate:"gt=0"`
+	BoxQuantity   int     `form:"boxQuantity" validate:"gte=0"`
+	BoxKey        bool    `form:"boxKey"`
 }
 
 type ImageCharacterUploadRequest struct {
@@ -28,10 +37,14 @@ type ImageUrlCharacterUpdateRequest struct {
 }
 
 type CharacterUpdateRequest struct {
-	Id            int    `form:"id" validate:"required"`
-	GachaSystemId int    `form:"gachaSystemId" validate:"required"`
-	Name          string `form:"name"`
-	RarityId      int    `form:"rarityId"`
+	Id            int      `form:"id" validate:"required"`
+	GachaSystemId int      `form:"gachaSystemId" validate:"required"`
+	Name          string   `form:"name"`
+	RarityId      int      `form:"rarityId"`
+	Featured      *bool    `form:"featured"`
+	Weight        *float32 `form:"weight" validate:"omitempty,gt=0"`
+	BoxQuantity   *int     `form:"boxQuantity" validate:"omitempty,gte=0"`
+	BoxKey        *bool    `form:"boxKey"`
 	ImageUrl      string
 }
 
@@ -45,11 +58,48 @@ func ToCharacterUpdateRequest(request *http.Request) *CharacterUpdateRequest {
 		rarityId = -1
 	}
 
+	var featured *bool
+	featuredValue, err := strconv.ParseBool(request.FormValue("featured"))
+	if err == nil {
+		featured = &featuredValue
+	}
+
+	var weight *float32
+	weightStr := request.FormValue("weight")
+	if weightStr != "" {
+		weightValue, err := strconv.ParseFloat(weightStr, 32)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid character weight"))
+		}
+		updatedWeight := float32(weightValue)
+		weight = &updatedWeight
+	}
+
+	var boxQuantity *int
+	boxQuantityStr := request.FormValue("boxQuantity")
+	if boxQuantityStr != "" {
+		boxQuantityValue, err := strconv.Atoi(boxQuantityStr)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid character box quantity"))
+		}
+		boxQuantity = &boxQuantityValue
+	}
+
+	var boxKey *bool
+	boxKeyValue, err := strconv.ParseBool(request.FormValue("boxKey"))
+	if err == nil {
+		boxKey = &boxKeyValue
+	}
+
 	return &CharacterUpdateRequest{
 		Id:            id,
 		Name:          request.FormValue("name"),
 		GachaSystemId: gachaSystemId,
 		RarityId:      rarityId,
+		Featured:      featured,
+		Weight:        weight,
+		BoxQuantity:   boxQuantity,
+		BoxKey:        boxKey,
 	}
 }
 
@@ -60,6 +110,18 @@ func (updateRequest *CharacterUpdateRequest) UpdateCharacter(character *domain.C
 	if updateRequest.RarityId != -1 {
 		character.RarityId = updateRequest.RarityId
 	}
+	if updateRequest.Featured != nil {
+		character.Featured = *updateRequest.Featured
+	}
+	if updateRequest.Weight != nil {
+		character.Weight = *updateRequest.Weight
+	}
+	if updateRequest.BoxQuantity != nil {
+		character.BoxQuantity = *updateRequest.BoxQuantity
+	}
+	if updateRequest.BoxKey != nil {
+		character.BoxKey = *updateRequest.BoxKey
+	}
 	if updateRequest.ImageUrl != "" {
 		character.ImageUrl = updateRequest.ImageUrl
 	}
@@ -95,10 +157,36 @@ func ToImageCharacterUploadRequest(request *http.Request) *ImageCharacterUploadR
 func ToCharacterCreateRequest(request *http.Request) *CharacterCreateRequest {
 	gachaSystemId, _ := strconv.Atoi(request.FormValue("gachaSystemId"))
 	rarityId, _ := strconv.Atoi(request.FormValue("rarityId"))
+	featured, _ := strconv.ParseBool(request.FormValue("featured"))
+
+	weight := float64(DefaultCharacterWeight)
+	weightStr := request.FormValue("weight")
+	if weightStr != "" {
+		var err error
+		weight, err = strconv.ParseFloat(weightStr, 32)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid character weight"))
+		}
+	}
+
+	boxQuantity := DefaultCharacterBoxQuantity
+	boxQuantityStr := request.FormValue("boxQuantity")
+	if boxQuantityStr != "" {
+		var err error
+		boxQuantity, err = strconv.Atoi(boxQuantityStr)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid character box quantity"))
+		}
+	}
+	boxKey, _ := strconv.ParseBool(request.FormValue("boxKey"))
 
 	return &CharacterCreateRequest{
 		Name:          request.FormValue("name"),
 		GachaSystemId: gachaSystemId,
 		RarityId:      rarityId,
+		Featured:      featured,
+		Weight:        float32(weight),
+		BoxQuantity:   boxQuantity,
+		BoxKey:        boxKey,
 	}
 }
@@ -5,10 +5,15 @@ import (
 )
 
 type CharacterResponse struct {
-	Id       int    `json:"id"`
-	Name     string `json:"name"`
-	ImageUrl string `json:"imageUrl"`
-	RarityId int    `json:"rarityId"`
+	Id       int     `json:"id"`
+	Name     string  `json:"name"`
+	ImageUrl string  `json:"imageUrl"`
+	Featured bool    `json:"featured"`
+	Weight   float32 `json:"weight"`
+	RarityId int     `json:"rarityId"`
+
+	BoxQuantity int  `json:"boxQuantity"`
+	BoxKey      bool `json:"boxKey"`
 }
 
 func ToCharacterResponse(character *domain.Character) *CharacterResponse {
@@ -16,7 +21,12 @@ func ToCharacterResponse(character *domain.Character) *CharacterResponse {
 		Id:       character.Id,
 		Name:     character.Name,
 		ImageUrl: character.ImageUrl,
+		Featured: character.Featured,
+		Weight:   character.Weight,
 		RarityId: character.RarityId,
+
+		BoxQuantity: character.BoxQuantity,
+		BoxKey:      character.BoxKey,
 	}
 }
 
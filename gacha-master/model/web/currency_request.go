@@ -0,0 +1,21 @@
+package web
+
+import "gacha-master/model/domain"
+
+type CurrencyCreateRequest struct {
+	GachaSystemId int    `json:"gachaSystemId" validate:"required"`
+	Name          string `json:"name" validate:"required,max=50"`
+	PullCost      int    `json:"pullCost" validate:"gte=0"`
+}
+
+type CurrencyUpdateRequest struct {
+	Id            int    `json:"id" validate:"required"`
+	GachaSystemId int    `json:"gachaSystemId" validate:"required"`
+	Name          string `json:"name" validate:"required,max=50"`
+	PullCost      int    `json:"pullCost" validate:"gte=0"`
+}
+
+func (updateRequest *CurrencyUpdateRequest) UpdateCurrency(currency *domain.Currency) {
+	currency.Name = updateRequest.Name
+	currency.PullCost = updateRequest.PullCost
+}
@@ -0,0 +1,27 @@
+package web
+
+import "gacha-master/model/domain"
+
+type CurrencyResponse struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	PullCost int    `json:"pullCost"`
+}
+
+func ToCurrencyResponse(currency *domain.Currency) *CurrencyResponse {
+	return &CurrencyResponse{
+		Id:       currency.Id,
+		Name:     currency.Name,
+		PullCost: currency.PullCost,
+	}
+}
+
+func ToCurrenciesResponse(currencies []domain.Currency) []CurrencyResponse {
+	var currencyResponses []CurrencyResponse
+	for _, currency := range currencies {
+		currencyResponse := ToCurrencyResponse(&currency)
+		currencyResponses = append(currencyResponses, *currencyResponse)
+	}
+
+	return currencyResponses
+}
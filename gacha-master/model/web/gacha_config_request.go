@@ -0,0 +1,9 @@
+package web
+
+type GachaConfigPublishRequest struct {
+	GachaSystemId int `json:"gachaSystemId" validate:"required"`
+}
+
+type GachaConfigDiscardRequest struct {
+	GachaSystemId int `json:"gachaSystemId" validate:"required"`
+}
@@ -0,0 +1,192 @@
+package web
+
+import (
+	"gacha-master/model/domain"
+	"time"
+)
+
+// GachaConfigResponse is the snapshot of the configuration gacha-pull pulls with. Version
+// identifies its content, SchemaVersion its layout. VersionId and VersionNumber point at the
+// published config version it was taken from, and are left out of the draft. Currencies and API
+// keys are not versioned, they are added live to the snapshot served to gacha-pull only.
+type GachaConfigResponse struct {
+	SchemaVersion int                            `json:"schemaVersion"`
+	Version       string                         `json:"version"`
+	VersionId     int                            `json:"versionId,omitempty"`
+	VersionNumber int                            `json:"versionNumber,omitempty"`
+	GachaSystem   GachaConfigSystemResponse      `json:"gachaSystem"`
+	Rarities      []GachaConfigRarityResponse    `json:"rarities"`
+	Characters    []GachaConfigCharacterResponse `json:"characters"`
+	Banners       []GachaConfigBannerResponse    `json:"banners"`
+	Currencies    []GachaConfigCurrencyResponse  `json:"currencies,omitempty"`
+	ApiKeys       []GachaConfigApiKeyResponse    `json:"apiKeys,omitempty"`
+}
+
+type GachaConfigSystemResponse struct {
+	Id                     int     `json:"id"`
+	Name                   string  `json:"name"`
+	EndpointId             string  `json:"endpointId"`
+	GuaranteedRarityId     int     `json:"guaranteedRarityId"`
+	GuaranteePullCount     int     `json:"guaranteePullCount"`
+	ProvablyFair           bool    `json:"provablyFair"`
+	Private                bool    `json:"private"`
+	EndpointRateLimit      int     `json:"endpointRateLimit"`
+	ApiKeyRateLimit        int     `json:"apiKeyRateLimit"`
+	PlayerRateLimit        int     `json:"playerRateLimit"`
+	MultiPullDiscount      float32 `json:"multiPullDiscount"`
+	MultiPullDiscountCount int     `json:"multiPullDiscountCount"`
+	Mode                   string  `json:"mode"`
+}
+
+type GachaConfigRarityResponse struct {
+	Id                  int     `json:"id"`
+	Name                string  `json:"name"`
+	Chance              float32 `json:"chance"`
+	PityThreshold       int     `json:"pityThreshold"`
+	SoftPityStart       int     `json:"softPityStart"`
+	SoftPityStep        float32 `json:"softPityStep"`
+	FeaturedChance      float32 `json:"featuredChance"`
+	DuplicateConversion string  `json:"duplicateConversion"`
+	DuplicateCurrencyId *int    `json:"duplicateCurrencyId"`
+	DuplicateShards     int     `json:"duplicateShards"`
+	MaxConstellation    int     `json:"maxConstellation"`
+}
+
+type GachaConfigCharacterResponse struct {
+	Id          int     `json:"id"`
+	Name        string  `json:"name"`
+	ImageUrl    string  `json:"imageUrl"`
+	Featured    bool    `json:"featured"`
+	Weight      float32 `json:"weight"`
+	RarityId    int     `json:"rarityId"`
+	BoxQuantity int     `json:"boxQuantity"`
+	BoxKey      bool    `json:"boxKey"`
+}
+
+type GachaConfigBannerResponse struct {
+	Id                   int       `json:"id"`
+	Name                 string    `json:"name"`
+	StartsAt             time.Time `json:"startsAt"`
+	EndsAt               time.Time `json:"endsAt"`
+	Timezone             string    `json:"timezone"`
+	FeaturedCharacterIds []int     `json:"featuredCharacterIds"`
+	SparkThreshold       int       `json:"sparkThreshold"`
+	SparkCurrencyId      *int      `json:"sparkCurrencyId"`
+	SparkConversion      int       `json:"sparkConversion"`
+}
+
+type GachaConfigCurrencyResponse struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	PullCost int    `json:"pullCost"`
+}
+
+// GachaConfigApiKeyResponse is an API key that is not revoked. Only the hash of the key is shared.
+type GachaConfigApiKeyResponse struct {
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	KeyHash   string     `json:"keyHash"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// ToGachaConfigAccess lists the currencies and the API keys that are not revoked, as added to the
+// served snapshot.
+func ToGachaConfigAccess(currencies []domain.Currency, apiKeys []domain.ApiKey) ([]GachaConfigCurrencyResponse, []GachaConfigApiKeyResponse) {
+	var currencyResponses []GachaConfigCurrencyResponse
+	for _, currency := range currencies {
+		currencyResponses = append(currencyResponses, GachaConfigCurrencyResponse{
+			Id:       currency.Id,
+			Name:     currency.Name,
+			PullCost: currency.PullCost,
+		})
+	}
+
+	var apiKeyResponses []GachaConfigApiKeyResponse
+	for _, apiKey := range apiKeys {
+		if apiKey.RevokedAt != nil {
+			continue
+		}
+
+		var expiresAt *time.Time
+		if apiKey.ExpiresAt != nil {
+			utc := apiKey.ExpiresAt.UTC()
+			expiresAt = &utc
+		}
+		apiKeyResponses = append(apiKeyResponses, GachaConfigApiKeyResponse{
+			Id:        apiKey.Id,
+			Name:      apiKey.Name,
+			KeyHash:   apiKey.KeyHash,
+			ExpiresAt: expiresAt,
+		})
+	}
+
+	return currencyResponses, apiKeyResponses
+}
+
+func ToGachaConfigResponse(gachaSystem *domain.GachaSystem, rarities []domain.Rarity, characters []domain.Character, banners []domain.Banner) *GachaConfigResponse {
+	configResponse := &GachaConfigResponse{
+		GachaSystem: GachaConfigSystemResponse{
+			Id:                     gachaSystem.Id,
+			Name:                   gachaSystem.Name,
+			EndpointId:             gachaSystem.EndpointId,
+			GuaranteedRarityId:     gachaSystem.GuaranteedRarityId,
+			GuaranteePullCount:     gachaSystem.GuaranteePullCount,
+			ProvablyFair:           gachaSystem.ProvablyFair,
+			Private:                gachaSystem.Private,
+			EndpointRateLimit:      gachaSystem.EndpointRateLimit,
+			ApiKeyRateLimit:        gachaSystem.ApiKeyRateLimit,
+			PlayerRateLimit:        gachaSystem.PlayerRateLimit,
+			MultiPullDiscount:      gachaSystem.MultiPullDiscount,
+			MultiPullDiscountCount: gachaSystem.MultiPullDiscountCount,
+			Mode:                   gachaSystem.Mode,
+		},
+		Rarities:   []GachaConfigRarityResponse{},
+		Characters: []GachaConfigCharacterResponse{},
+		Banners:    []GachaConfigBannerResponse{},
+	}
+
+	for _, rarity := range rarities {
+		configResponse.Rarities = append(configResponse.Rarities, GachaConfigRarityResponse{
+			Id:                  rarity.Id,
+			Name:                rarity.Name,
+			Chance:              rarity.Chance,
+			PityThreshold:       rarity.PityThreshold,
+			SoftPityStart:       rarity.SoftPityStart,
+			SoftPityStep:        rarity.SoftPityStep,
+			FeaturedChance:      rarity.FeaturedChance,
+			DuplicateConversion: rarity.DuplicateConversion,
+			DuplicateCurrencyId: rarity.DuplicateCurrencyId,
+			DuplicateShards:     rarity.DuplicateShards,
+			MaxConstellation:    rarity.MaxConstellation,
+		})
+	}
+
+	for _, character := range characters {
+		configResponse.Characters = append(configResponse.Characters, GachaConfigCharacterResponse{
+			Id:          character.Id,
+			Name:        character.Name,
+			ImageUrl:    character.ImageUrl,
+			Featured:    character.Featured,
+			Weight:      character.Weight,
+			RarityId:    character.RarityId,
+			BoxQuantity: character.BoxQuantity,
+			BoxKey:      character.BoxKey,
+		})
+	}
+
+	for _, banner := range banners {
+		configResponse.Banners = append(configResponse.Banners, GachaConfigBannerResponse{
+			Id:                   banner.Id,
+			Name:                 banner.Name,
+			StartsAt:             banner.StartsAt.UTC(),
+			EndsAt:               banner.EndsAt.UTC(),
+			Timezone:             banner.Timezone,
+			FeaturedCharacterIds: banner.FeaturedCharacterIds,
+			SparkThreshold:       banner.SparkThreshold,
+			SparkCurrencyId:      banner.SparkCurrencyId,
+			SparkConversion:      banner.SparkConversion,
+		})
+	}
+
+	return configResponse
+}
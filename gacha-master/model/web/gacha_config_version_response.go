@@ -0,0 +1,67 @@
+package web
+
+import (
+	"gacha-master/model/domain"
+	"time"
+)
+
+const (
+	GachaConfigChangeAdded   = "added"
+	GachaConfigChangeRemoved = "removed"
+	GachaConfigChangeChanged = "changed"
+)
+
+// GachaConfigVersionResponse describes a published config version. Config only comes with a
+// single version, not with the list.
+type GachaConfigVersionResponse struct {
+	Id          int                  `json:"id"`
+	Number      int                  `json:"number"`
+	ContentHash string               `json:"contentHash"`
+	Active      bool                 `json:"active"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	Config      *GachaConfigResponse `json:"config,omitempty"`
+}
+
+// GachaConfigDiffResponse lists what changed between two config versions. A To of 0 stands for
+// the draft that is not published yet.
+type GachaConfigDiffResponse struct {
+	From       int                      `json:"from"`
+	To         int                      `json:"to"`
+	Settings   []GachaConfigFieldChange `json:"settings"`
+	Rarities   []GachaConfigItemChange  `json:"rarities"`
+	Characters []GachaConfigItemChange  `json:"characters"`
+	Banners    []GachaConfigItemChange  `json:"banners"`
+}
+
+type GachaConfigFieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// GachaConfigItemChange is a rarity, character or banner that was added, removed or changed.
+// Fields only lists the changed fields of a changed item.
+type GachaConfigItemChange struct {
+	Id     int                      `json:"id"`
+	Change string                   `json:"change"`
+	Fields []GachaConfigFieldChange `json:"fields,omitempty"`
+}
+
+func ToGachaConfigVersionResponse(version *domain.GachaConfigVersion) *GachaConfigVersionResponse {
+	return &GachaConfigVersionResponse{
+		Id:          version.Id,
+		Number:      version.Number,
+		ContentHash: version.ContentHash,
+		Active:      version.Active,
+		CreatedAt:   version.CreatedAt,
+	}
+}
+
+func ToGachaConfigVersionsResponse(versions []domain.GachaConfigVersion) []GachaConfigVersionResponse {
+	versionResponses := []GachaConfigVersionResponse{}
+	for _, version := range versions {
+		versionResponses = append(versionResponses, *ToGachaConfigVersionResponse(&version))
+	}
+
+	return versionResponses
+}
@@ -1,5 +1,38 @@
 package web
 
+import "gacha-master/model/domain"
+
 type GachaSystemCreateRequest struct {
 	Name string `json:"name" validate:"required"`
 }
+
+type GachaSystemSettingsUpdateRequest struct {
+	GachaSystemId          int     `json:"gachaSystemId" validate:"required"`
+	GuaranteedRarityId     int     `json:"guaranteedRarityId" validate:"gte=0"`
+	GuaranteePullCount     int     `json:"guaranteePullCount" validate:"required,gt=0"`
+	ProvablyFair           bool    `json:"provablyFair"`
+	Private                bool    `json:"private"`
+	EndpointRateLimit      int     `json:"endpointRateLimit" validate:"gte=0"`
+	ApiKeyRateLimit        int     `json:"apiKeyRateLimit" validate:"gte=0"`
+	PlayerRateLimit        int     `json:"playerRateLimit" validate:"gte=0"`
+	MultiPullDiscount      float32 `json:"multiPullDiscount" validate:"gte=0,lte=100"`
+	MultiPullDiscountCount int     `json:"multiPullDiscountCount" validate:"gte=0"`
+	Mode                   string  `json:"mode" validate:"omitempty,oneof=standard box"`
+}
+
+func (updateRequest *GachaSystemSettingsUpdateRequest) UpdateGachaSystem(gachaSystem *domain.GachaSystem) {
+	gachaSystem.GuaranteedRarityId = updateRequest.GuaranteedRarityId
+	gachaSystem.GuaranteePullCount = updateRequest.GuaranteePullCount
+	gachaSystem.ProvablyFair = updateRequest.ProvablyFair
+	gachaSystem.Private = updateRequest.Private
+	gachaSystem.EndpointRateLimit = updateRequest.EndpointRateLimit
+	gachaSystem.ApiKeyRateLimit = updateRequest.ApiKeyRateLimit
+	gachaSystem.PlayerRateLimit = updateRequest.PlayerRateLimit
+	gachaSystem.MultiPullDiscount = updateRequest.MultiPullDiscount
+	if updateRequest.MultiPullDiscountCount > 0 {
+		gachaSystem.MultiPullDiscountCount = updateRequest.MultiPullDiscountCount
+	}
+	if updateRequest.Mode != "" {
+		gachaSystem.Mode = updateRequest.Mode
+	}
+}
@@ -3,11 +3,25 @@ package web
 import "gacha-master/model/domain"
 
 type GachaSystemDetailResponse struct {
-	Id         int                 `json:"id"`
-	Name       string              `json:"name"`
-	Endpoint   string              `json:"endpoint"`
-	Rarities   []RarityResponse    `json:"rarities"`
-	Characters []CharacterResponse `json:"characters"`
+	Id         int                          `json:"id"`
+	Name       string                       `json:"name"`
+	Endpoint   string                       `json:"endpoint"`
+	Settings   *GachaSystemSettingsResponse `json:"settings"`
+	Rarities   []RarityResponse             `json:"rarities"`
+	Characters []CharacterResponse          `json:"characters"`
+}
+
+type GachaSystemSettingsResponse struct {
+	GuaranteedRarityId     int     `json:"guaranteedRarityId"`
+	GuaranteePullCount     int     `json:"guaranteePullCount"`
+	ProvablyFair           bool    `json:"provablyFair"`
+	Private                bool    `json:"private"`
+	EndpointRateLimit      int     `json:"endpointRateLimit"`
+	ApiKeyRateLimit        int     `json:"apiKeyRateLimit"`
+	PlayerRateLimit        int     `json:"playerRateLimit"`
+	MultiPullDiscount      float32 `json:"multiPullDiscount"`
+	MultiPullDiscountCount int     `json:"multiPullDiscountCount"`
+	Mode                   string  `json:"mode"`
 }
 
 type GachaSystemResponse struct {
@@ -28,6 +42,21 @@ func ToGachaSystemResponse(gachaSystem *domain.GachaSystem) *GachaSystemResponse
 	}
 }
 
+func ToGachaSystemSettingsResponse(gachaSystem *domain.GachaSystem) *GachaSystemSettingsResponse {
+	return &GachaSystemSettingsResponse{
+		GuaranteedRarityId:     gachaSystem.GuaranteedRarityId,
+		GuaranteePullCount:     gachaSystem.GuaranteePullCount,
+		ProvablyFair:           gachaSystem.ProvablyFair,
+		Private:                gachaSystem.Private,
+		EndpointRateLimit:      gachaSystem.EndpointRateLimit,
+		ApiKeyRateLimit:        gachaSystem.ApiKeyRateLimit,
+		PlayerRateLimit:        gachaSystem.PlayerRateLimit,
+		MultiPullDiscount:      gachaSystem.MultiPullDiscount,
+		MultiPullDiscountCount: gachaSystem.MultiPullDiscountCount,
+		Mode:                   gachaSystem.Mode,
+	}
+}
+
 func ToGachaSystemsResponse(rarities []domain.GachaSystem) []GachaSystemResponse {
 	var gachaSystemResponses []GachaSystemResponse
 	for _, gachaSystem := range rarities {
@@ -4,21 +4,62 @@ import (
 	"gacha-master/model/domain"
 )
 
+const DefaultFeaturedChance = 50
+
 type RarityCreateRequest struct {
-	GachaSystemId int     `json:"gachaSystemId" validate:"required"`
-	Name          string  `json:"name" validate:"required"`
-	Chance        float32 `json:"chance" validate:"required,gte=0,lte=100"`
+	GachaSystemId  int      `json:"gachaSystemId" validate:"required"`
+	Name           string   `json:"name" validate:"required"`
+	Chance         float32  `json:"chance" validate:"required,gte=0,lte=100"`
+	PityThreshold  int      `json:"pityThreshold" validate:"gte=0"`
+	SoftPityStart  int      `json:"softPityStart" validate:"gte=0"`
+	SoftPityStep   float32  `json:"softPityStep" validate:"gte=0,lte=100"`
+	FeaturedChance *float32 `json:"featuredChance" validate:"omitempty,gte=0,lte=100"`
+
+	DuplicateConversion string `json:"duplicateConversion" validate:"omitempty,oneof=none shards constellation"`
+	DuplicateCurrencyId *int   `json:"duplicateCurrencyId"`
+	DuplicateShards     int    `json:"duplicateShards" validate:"gte=0"`
+	MaxConstellation    int    `json:"maxConstellation" validate:"gte=0"`
 }
 
 type RarityUpdateRequest struct {
-	Id            int     `json:"id" validate:"required"`
-	GachaSystemId int     `json:"gachaSystemId" validate:"required"`
-	Name          string  `json:"name" validate:"required"`
-	Chance        float32 `json:"chance" validate:"required,gte=0,lte=100"`
+	Id             int      `json:"id" validate:"required"`
+	GachaSystemId  int      `json:"gachaSystemId" validate:"required"`
+	Name           string   `json:"name" validate:"required"`
+	Chance         float32  `json:"chance" validate:"required,gte=0,lte=100"`
+	PityThreshold  int      `json:"pityThreshold" validate:"gte=0"`
+	SoftPityStart  int      `json:"softPityStart" validate:"gte=0"`
+	SoftPityStep   float32  `json:"softPityStep" validate:"gte=0,lte=100"`
+	FeaturedChance *float32 `json:"featuredChance" validate:"omitempty,gte=0,lte=100"`
+
+	DuplicateConversion string `json:"duplicateConversion" validate:"omitempty,oneof=none shards constellation"`
+	DuplicateCurrencyId *int   `json:"duplicateCurrencyId"`
+	DuplicateShards     int    `json:"duplicateShards" validate:"gte=0"`
+	MaxConstellation    int    `json:"maxConstellation" validate:"gte=0"`
 }
 
 func (updateRequest *RarityUpdateRequest) UpdateRarity(rarity *domain.Rarity) {
 	rarity.Name = updateRequest.Name
 	rarity.Id = updateRequest.Id
 	rarity.Chance = updateRequest.Chance
+	rarity.PityThreshold = updateRequest.PityThreshold
+	rarity.SoftPityStart = updateRequest.SoftPityStart
+	rarity.SoftPityStep = updateRequest.SoftPityStep
+	if updateRequest.FeaturedChance != nil {
+		rarity.FeaturedChance = *updateRequest.FeaturedChance
+	}
+	rarity.DuplicateConversion = duplicateConversionOrNone(updateRequest.DuplicateConversion)
+	rarity.DuplicateCurrencyId = updateRequest.DuplicateCurrencyId
+	rarity.DuplicateShards = updateRequest.DuplicateShards
+	rarity.MaxConstellation = updateRequest.MaxConstellation
+}
+
+func (createRequest *RarityCreateRequest) DuplicateConversionOrNone() string {
+	return duplicateConversionOrNone(createRequest.DuplicateConversion)
+}
+
+func duplicateConversionOrNone(duplicateConversion string) string {
+	if duplicateConversion == "" {
+		return domain.DuplicateConversionNone
+	}
+	return duplicateConversion
 }
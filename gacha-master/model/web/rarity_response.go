@@ -3,16 +3,34 @@ package web
 import "gacha-master/model/domain"
 
 type RarityResponse struct {
-	Id     int     `json:"id"`
-	Name   string  `json:"name"`
-	Chance float32 `json:"chance"`
+	Id             int     `json:"id"`
+	Name           string  `json:"name"`
+	Chance         float32 `json:"chance"`
+	PityThreshold  int     `json:"pityThreshold"`
+	SoftPityStart  int     `json:"softPityStart"`
+	SoftPityStep   float32 `json:"softPityStep"`
+	FeaturedChance float32 `json:"featuredChance"`
+
+	DuplicateConversion string `json:"duplicateConversion"`
+	DuplicateCurrencyId *int   `json:"duplicateCurrencyId"`
+	DuplicateShards     int    `json:"duplicateShards"`
+	MaxConstellation    int    `json:"maxConstellation"`
 }
 
 func ToRarityResponse(rarity *domain.Rarity) *RarityResponse {
 	return &RarityResponse{
-		Id:     rarity.Id,
-		Name:   rarity.Name,
-		Chance: rarity.Chance,
+		Id:             rarity.Id,
+		Name:           rarity.Name,
+		Chance:         rarity.Chance,
+		PityThreshold:  rarity.PityThreshold,
+		SoftPityStart:  rarity.SoftPityStart,
+		SoftPityStep:   rarity.SoftPityStep,
+		FeaturedChance: rarity.FeaturedChance,
+
+		DuplicateConversion: rarity.DuplicateConversion,
+		DuplicateCurrencyId: rarity.DuplicateCurrencyId,
+		DuplicateShards:     rarity.DuplicateShards,
+		MaxConstellation:    rarity.MaxConstellation,
 	}
 }
 
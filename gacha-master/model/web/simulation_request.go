@@ -0,0 +1,20 @@
+package web
+
+// SimulationRequest names the target by character or by rarity name. Left out numbers fall back
+// to the defaults of gacha-pull: 10000 runs, at most 1000 pulls per run and single pulls.
+type SimulationRequest struct {
+	GachaSystemId int    `json:"gachaSystemId" validate:"required"`
+	BannerId      int    `json:"bannerId"`
+	Character     string `json:"character" validate:"required_without=Rarity,excluded_with=Rarity,max=100"`
+	Rarity        string `json:"rarity" validate:"max=50"`
+	Runs          int    `json:"runs" validate:"gte=0,lte=100000"`
+	MaxPulls      int    `json:"maxPulls" validate:"gte=0,lte=10000"`
+	PullsPerStep  int    `json:"pullsPerStep" validate:"gte=0,lte=100"`
+}
+
+// GachaPullSimulationRequest is the simulation sent to gacha-pull, together with the draft it is
+// run on.
+type GachaPullSimulationRequest struct {
+	*SimulationRequest
+	Config *GachaConfigResponse `json:"config"`
+}
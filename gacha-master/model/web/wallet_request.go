@@ -0,0 +1,14 @@
+package web
+
+const (
+	WalletReasonGrant  = "grant"
+	WalletReasonRevoke = "revoke"
+)
+
+type WalletAdjustRequest struct {
+	GachaSystemId int    `json:"gachaSystemId" validate:"required"`
+	PlayerId      string `json:"playerId" validate:"required,max=100"`
+	CurrencyId    int    `json:"currencyId" validate:"required"`
+	Amount        int64  `json:"amount" validate:"required,gt=0"`
+	Note          string `json:"note" validate:"max=255"`
+}
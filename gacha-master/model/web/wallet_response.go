@@ -0,0 +1,76 @@
+package web
+
+import (
+	"gacha-master/model/domain"
+	"time"
+)
+
+type WalletResponse struct {
+	PlayerId string                      `json:"playerId"`
+	Balances []WalletBalanceResponse     `json:"balances"`
+	Ledger   []WalletLedgerEntryResponse `json:"ledger"`
+}
+
+type WalletBalanceResponse struct {
+	CurrencyId int    `json:"currencyId"`
+	Currency   string `json:"currency"`
+	Balance    int64  `json:"balance"`
+}
+
+type WalletLedgerEntryResponse struct {
+	Id           int64     `json:"id"`
+	CurrencyId   int       `json:"currencyId"`
+	Currency     string    `json:"currency"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balanceAfter"`
+	Reason       string    `json:"reason"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func ToWalletLedgerEntryResponse(entry *domain.WalletLedgerEntry, currencyName string) *WalletLedgerEntryResponse {
+	return &WalletLedgerEntryResponse{
+		Id:           entry.Id,
+		CurrencyId:   entry.CurrencyId,
+		Currency:     currencyName,
+		Amount:       entry.Amount,
+		BalanceAfter: entry.BalanceAfter,
+		Reason:       entry.Reason,
+		Note:         entry.Note,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
+
+// ToWalletResponse lists a balance for every currency of the gacha system, including the ones the
+// player never received.
+func ToWalletResponse(playerId string, currencies []domain.Currency, wallets []domain.Wallet, entries []domain.WalletLedgerEntry) *WalletResponse {
+	currencyNameMap := make(map[int]string)
+	for _, currency := range currencies {
+		currencyNameMap[currency.Id] = currency.Name
+	}
+
+	balanceMap := make(map[int]int64)
+	for _, wallet := range wallets {
+		balanceMap[wallet.CurrencyId] = wallet.Balance
+	}
+
+	walletResponse := &WalletResponse{
+		PlayerId: playerId,
+		Balances: []WalletBalanceResponse{},
+		Ledger:   []WalletLedgerEntryResponse{},
+	}
+
+	for _, currency := range currencies {
+		walletResponse.Balances = append(walletResponse.Balances, WalletBalanceResponse{
+			CurrencyId: currency.Id,
+			Currency:   currency.Name,
+			Balance:    balanceMap[currency.Id],
+		})
+	}
+
+	for _, entry := range entries {
+		walletResponse.Ledger = append(walletResponse.Ledger, *ToWalletLedgerEntryResponse(&entry, currencyNameMap[entry.CurrencyId]))
+	}
+
+	return walletResponse
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type ApiKeyRepository interface {
+	Save(ctx context.Context, apiKey *domain.ApiKey)
+	FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.ApiKey
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.ApiKey
+	Revoke(ctx context.Context, apiKey *domain.ApiKey)
+}
+
+type ApiKeyRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewApiKeyRepository(dbpool *pgxpool.Pool) ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *ApiKeyRepositoryImpl) Save(ctx context.Context, apiKey *domain.ApiKey) {
+	query := `INSERT INTO api_key (gacha_system_id, name, key_prefix, key_hash, expires_at)
+				VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, query, apiKey.GachaSystemId, apiKey.Name, apiKey.Prefix, apiKey.KeyHash, apiKey.ExpiresAt).Scan(&apiKey.Id, &apiKey.CreatedAt)
+	helper.PanicIfError(err, "Failed to save API key")
+}
+
+func (repository *ApiKeyRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.ApiKey {
+	query := `SELECT id, gacha_system_id, name, key_prefix, key_hash, expires_at, revoked_at, created_at
+			FROM api_key
+			WHERE id = $1 AND gacha_system_id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	row := tx.QueryRow(ctx, query, id, gachaSystemId)
+
+	return getApiKeyFromRow(row)
+}
+
+func (repository *ApiKeyRepositoryImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.ApiKey {
+	query := `SELECT id, gacha_system_id, name, key_prefix, key_hash, expires_at, revoked_at, created_at
+			FROM api_key
+			WHERE gacha_system_id = $1
+			ORDER BY created_at DESC`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId)
+	if err != nil {
+		log.Printf("Error while querying API keys: %v", err)
+	}
+	defer rows.Close()
+
+	var apiKeys []domain.ApiKey
+	for rows.Next() {
+		apiKey := getApiKeyFromRow(rows)
+		if apiKey == nil {
+			continue
+		}
+
+		apiKeys = append(apiKeys, *apiKey)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning API keys: %v", err)
+	}
+
+	return apiKeys
+}
+
+func (repository *ApiKeyRepositoryImpl) Revoke(ctx context.Context, apiKey *domain.ApiKey) {
+	query := `UPDATE api_key SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 RETURNING revoked_at`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, query, apiKey.Id).Scan(&apiKey.RevokedAt)
+	helper.PanicIfError(err, "Failed to revoke API key")
+}
+
+func getApiKeyFromRow(row pgx.Row) *domain.ApiKey {
+	var apiKey domain.ApiKey
+
+	err := row.Scan(&apiKey.Id, &apiKey.GachaSystemId, &apiKey.Name, &apiKey.Prefix, &apiKey.KeyHash,
+		&apiKey.ExpiresAt, &apiKey.RevokedAt, &apiKey.CreatedAt)
+	if err != nil {
+		return nil
+	}
+
+	return &apiKey
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+	"time"
+)
+
+type BannerRepository interface {
+	Save(ctx context.Context, banner *domain.Banner)
+	FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.Banner
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.Banner
+	ExistsOverlapping(ctx context.Context, gachaSystemId int, startsAt time.Time, endsAt time.Time, excludedId int) bool
+	Update(ctx context.Context, banner *domain.Banner)
+	Delete(ctx context.Context, id int, gachaSystemId int)
+}
+
+type BannerRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewBannerRepository(dbpool *pgxpool.Pool) BannerRepository {
+	return &BannerRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *BannerRepositoryImpl) Save(ctx context.Context, banner *domain.Banner) {
+	query := `INSERT INTO banner (gacha_system_id, name, starts_at, ends_at, timezone, featured_character_ids,
+				spark_threshold, spark_currency_id, spark_conversion)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, query, banner.GachaSystemId, banner.Name, banner.StartsAt, banner.EndsAt, banner.Timezone,
+		featuredCharacterIds(banner), banner.SparkThreshold, banner.SparkCurrencyId, banner.SparkConversion).Scan(&banner.Id)
+	helper.PanicIfError(err, "Failed to save banner")
+}
+
+func (repository *BannerRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.Banner {
+	query := `SELECT id, gacha_system_id, name, starts_at, ends_at, timezone, featured_character_ids,
+				spark_threshold, spark_currency_id, spark_conversion
+				FROM banner WHERE id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getBannerFromRow(tx.QueryRow(ctx, query, id, gachaSystemId))
+}
+
+func (repository *BannerRepositoryImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.Banner {
+	query := `SELECT id, gacha_system_id, name, starts_at, ends_at, timezone, featured_character_ids,
+				spark_threshold, spark_currency_id, spark_conversion
+				FROM banner WHERE gacha_system_id = $1 AND deleted_at IS NULL ORDER BY starts_at`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId)
+	if err != nil {
+		log.Printf("Error while querying banners: %v", err)
+	}
+	defer rows.Close()
+
+	var banners []domain.Banner
+	for rows.Next() {
+		banner := getBannerFromRow(rows)
+		if banner == nil {
+			continue
+		}
+
+		banners = append(banners, *banner)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning banners: %v", err)
+	}
+
+	return banners
+}
+
+// ExistsOverlapping reports whether another banner of the gacha system runs at any moment between
+// startsAt and endsAt. The banner with excludedId is ignored so a banner never overlaps itself.
+func (repository *BannerRepositoryImpl) ExistsOverlapping(ctx context.Context, gachaSystemId int, startsAt time.Time, endsAt time.Time, excludedId int) bool {
+	query := `SELECT EXISTS (
+				SELECT 1 FROM banner
+				WHERE gacha_system_id = $1 AND id <> $4 AND starts_at < $3 AND ends_at > $2 AND deleted_at IS NULL
+			)`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	var exists bool
+	err = tx.QueryRow(ctx, query, gachaSystemId, startsAt, endsAt, excludedId).Scan(&exists)
+	helper.PanicIfError(err, "Failed to check overlapping banners")
+
+	return exists
+}
+
+func (repository *BannerRepositoryImpl) Update(ctx context.Context, banner *domain.Banner) {
+	query := `UPDATE banner
+	          SET name = $1, starts_at = $2, ends_at = $3, timezone = $4, featured_character_ids = $5,
+	              spark_threshold = $6, spark_currency_id = $7, spark_conversion = $8
+	          WHERE id = $9 AND gacha_system_id = $10`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, banner.Name, banner.StartsAt, banner.EndsAt, banner.Timezone, featuredCharacterIds(banner),
+		banner.SparkThreshold, banner.SparkCurrencyId, banner.SparkConversion, banner.Id, banner.GachaSystemId)
+	helper.PanicIfError(err, "Failed to update banner")
+}
+
+// Delete removes a banner from the draft. The row is only marked, it stays until the next publish
+// so discarding the draft can bring it back.
+func (repository *BannerRepositoryImpl) Delete(ctx context.Context, id int, gachaSystemId int) {
+	query := `UPDATE banner SET deleted_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, id, gachaSystemId)
+	helper.PanicIfError(err, "Failed to delete banner")
+}
+
+// featuredCharacterIds returns the featured characters of a banner as a non nil slice, so an
+// empty list is stored as an empty array instead of NULL.
+func featuredCharacterIds(banner *domain.Banner) []int {
+	if banner.FeaturedCharacterIds == nil {
+		return []int{}
+	}
+	return banner.FeaturedCharacterIds
+}
+
+func getBannerFromRow(row pgx.Row) *domain.Banner {
+	var banner domain.Banner
+
+	err := row.Scan(&banner.Id, &banner.GachaSystemId, &banner.Name, &banner.StartsAt, &banner.EndsAt, &banner.Timezone,
+		&banner.FeaturedCharacterIds, &banner.SparkThreshold, &banner.SparkCurrencyId, &banner.SparkConversion)
+	if err != nil {
+		return nil
+	}
+
+	return &banner
+}
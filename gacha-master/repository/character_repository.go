@@ -31,8 +31,8 @@ func NewCharacterRepository(dbpool *pgxpool.Pool) CharacterRepository {
 }
 
 func (repository *CharacterRepositoryImpl) Save(ctx context.Context, character *domain.Character) {
-	query := `INSERT INTO character (name, rarity_id, gacha_system_id) 
-				VALUES ($1, $2, $3) RETURNING id`
+	query := `INSERT INTO character (name, rarity_id, gacha_system_id, featured, weight, box_quantity, box_key) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -40,14 +40,15 @@ func (repository *CharacterRepositoryImpl) Save(ctx context.Context, character *
 	defer helper.CommitOrRollback(tx, ctx)
 
 	var id int
-	err = tx.QueryRow(ctx, query, character.Name, character.RarityId, character.GachaSystemId).Scan(&id)
+	err = tx.QueryRow(ctx, query, character.Name, character.RarityId, character.GachaSystemId, character.Featured, character.Weight,
+		character.BoxQuantity, character.BoxKey).Scan(&id)
 	helper.PanicIfError(err, helper.ErrUserNotFound)
 
 	character.Id = id
 }
 
 func (repository *CharacterRepositoryImpl) FindByNameAndGachaSystemId(ctx context.Context, name string, gachaSystemId int) *domain.Character {
-	query := `SELECT id, name, image_url, rarity_id, gacha_system_id FROM character WHERE LOWER(name) = LOWER($1) AND gacha_system_id = $2`
+	query := `SELECT id, name, image_url, featured, weight, rarity_id, gacha_system_id, box_quantity, box_key FROM character WHERE LOWER(name) = LOWER($1) AND gacha_system_id = $2 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -60,7 +61,7 @@ func (repository *CharacterRepositoryImpl) FindByNameAndGachaSystemId(ctx contex
 }
 
 func (repository *CharacterRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.Character {
-	query := `SELECT id, name, image_url, rarity_id, gacha_system_id FROM character WHERE id = $1 AND gacha_system_id = $2`
+	query := `SELECT id, name, image_url, featured, weight, rarity_id, gacha_system_id, box_quantity, box_key FROM character WHERE id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -72,7 +73,7 @@ func (repository *CharacterRepositoryImpl) FindByIdAndGachaSystemId(ctx context.
 }
 
 func (repository *CharacterRepositoryImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.Character {
-	query := `SELECT id, name, image_url, rarity_id, gacha_system_id FROM character WHERE gacha_system_id = $1`
+	query := `SELECT id, name, image_url, featured, weight, rarity_id, gacha_system_id, box_quantity, box_key FROM character WHERE gacha_system_id = $1 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -90,7 +91,8 @@ func (repository *CharacterRepositoryImpl) FindAllByGachaSystemId(ctx context.Co
 		var character domain.Character
 		var imageUrl sql.NullString
 
-		err = rows.Scan(&character.Id, &character.Name, &imageUrl, &character.RarityId, &character.GachaSystemId)
+		err = rows.Scan(&character.Id, &character.Name, &imageUrl, &character.Featured, &character.Weight, &character.RarityId, &character.GachaSystemId,
+			&character.BoxQuantity, &character.BoxKey)
 		if err != nil {
 			log.Printf("Error scanning row: %v", err)
 			continue
@@ -113,15 +115,16 @@ func (repository *CharacterRepositoryImpl) FindAllByGachaSystemId(ctx context.Co
 
 func (repository *CharacterRepositoryImpl) Update(ctx context.Context, character *domain.Character) {
 	query := `UPDATE character 
-	          SET name = $1, rarity_id = $2, image_url = $3
-	          WHERE id = $4`
+	          SET name = $1, rarity_id = $2, image_url = $3, featured = $4, weight = $5, box_quantity = $6, box_key = $7
+	          WHERE id = $8`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
 
 	defer helper.CommitOrRollback(tx, ctx)
 
-	_, err = tx.Exec(ctx, query, character.Name, character.RarityId, character.ImageUrl, character.Id)
+	_, err = tx.Exec(ctx, query, character.Name, character.RarityId, character.ImageUrl, character.Featured, character.Weight,
+		character.BoxQuantity, character.BoxKey, character.Id)
 	helper.PanicIfError(err, "Failed to update character")
 }
 
@@ -139,8 +142,11 @@ func (repository *CharacterRepositoryImpl) InsertImageUrl(ctx context.Context, c
 	helper.PanicIfError(err, "Failed to update image url character")
 }
 
+// Delete removes a character from the draft. The row is only marked, it stays until the next
+// publish so discarding the draft can bring it back.
 func (repository *CharacterRepositoryImpl) Delete(ctx context.Context, id int, gachaSystemId int) {
-	query := `DELETE FROM character WHERE id = $1 AND gacha_system_id = $2`
+	query := `UPDATE character SET deleted_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -155,7 +161,8 @@ func getCharacterFromRow(row pgx.Row) *domain.Character {
 	var character domain.Character
 	var imageUrl sql.NullString
 
-	err := row.Scan(&character.Id, &character.Name, &imageUrl, &character.RarityId, &character.GachaSystemId)
+	err := row.Scan(&character.Id, &character.Name, &imageUrl, &character.Featured, &character.Weight, &character.RarityId, &character.GachaSystemId,
+		&character.BoxQuantity, &character.BoxKey)
 	if err != nil {
 		log.Printf("Error scanning row: %v", err)
 		return nil
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type CurrencyRepository interface {
+	Save(ctx context.Context, currency *domain.Currency)
+	FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.Currency
+	FindByNameAndGachaSystemId(ctx context.Context, name string, gachaSystemId int) *domain.Currency
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.Currency
+	Update(ctx context.Context, currency *domain.Currency)
+	Delete(ctx context.Context, id int, gachaSystemId int)
+}
+
+type CurrencyRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewCurrencyRepository(dbpool *pgxpool.Pool) CurrencyRepository {
+	return &CurrencyRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *CurrencyRepositoryImpl) Save(ctx context.Context, currency *domain.Currency) {
+	query := `INSERT INTO currency (gacha_system_id, name, pull_cost) VALUES ($1, $2, $3) RETURNING id`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, query, currency.GachaSystemId, currency.Name, currency.PullCost).Scan(&currency.Id)
+	helper.PanicIfError(err, "Failed to save currency")
+}
+
+func (repository *CurrencyRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.Currency {
+	query := `SELECT id, name, pull_cost, gacha_system_id FROM currency WHERE id = $1 AND gacha_system_id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	row := tx.QueryRow(ctx, query, id, gachaSystemId)
+
+	var currency domain.Currency
+	err = row.Scan(&currency.Id, &currency.Name, &currency.PullCost, &currency.GachaSystemId)
+	if err != nil {
+		return nil
+	}
+
+	return &currency
+}
+
+func (repository *CurrencyRepositoryImpl) FindByNameAndGachaSystemId(ctx context.Context, name string, gachaSystemId int) *domain.Currency {
+	query := `SELECT id, name, pull_cost, gacha_system_id FROM currency WHERE LOWER(name) = LOWER($1) AND gacha_system_id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	row := tx.QueryRow(ctx, query, name, gachaSystemId)
+
+	var currency domain.Currency
+	err = row.Scan(&currency.Id, &currency.Name, &currency.PullCost, &currency.GachaSystemId)
+	if err != nil {
+		return nil
+	}
+
+	return &currency
+}
+
+func (repository *CurrencyRepositoryImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.Currency {
+	query := `SELECT id, name, pull_cost, gacha_system_id FROM currency WHERE gacha_system_id = $1 ORDER BY id`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId)
+	if err != nil {
+		log.Printf("Error while querying currencies: %v", err)
+	}
+	defer rows.Close()
+
+	var currencies []domain.Currency
+	for rows.Next() {
+		var currency domain.Currency
+		err = rows.Scan(&currency.Id, &currency.Name, &currency.PullCost, &currency.GachaSystemId)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		currencies = append(currencies, currency)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning currencies: %v", err)
+	}
+
+	return currencies
+}
+
+func (repository *CurrencyRepositoryImpl) Update(ctx context.Context, currency *domain.Currency) {
+	query := `UPDATE currency SET name = $1, pull_cost = $2 WHERE id = $3 AND gacha_system_id = $4`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, currency.Name, currency.PullCost, currency.Id, currency.GachaSystemId)
+	helper.PanicIfError(err, "Failed to update currency")
+}
+
+func (repository *CurrencyRepositoryImpl) Delete(ctx context.Context, id int, gachaSystemId int) {
+	query := `DELETE FROM currency WHERE id = $1 AND gacha_system_id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, id, gachaSystemId)
+	helper.PanicIfError(err, "Failed to delete currency")
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type GachaConfigVersionRepository interface {
+	Publish(ctx context.Context, version *domain.GachaConfigVersion)
+	FindActiveByGachaSystemId(ctx context.Context, gachaSystemId int) *domain.GachaConfigVersion
+	FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.GachaConfigVersion
+	FindByNumberAndGachaSystemId(ctx context.Context, number int, gachaSystemId int) *domain.GachaConfigVersion
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.GachaConfigVersion
+	RestoreDraft(ctx context.Context, gachaSystem *domain.GachaSystem, rarities []domain.Rarity, characters []domain.Character, banners []domain.Banner)
+}
+
+type GachaConfigVersionRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewGachaConfigVersionRepository(dbpool *pgxpool.Pool) GachaConfigVersionRepository {
+	return &GachaConfigVersionRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+// Publish stores the version under the next number of its gacha system and makes it the active
+// one. The gacha system row is locked first so two publishes never take the same number. Items
+// deleted from the draft were kept for a discard until now and are removed for good.
+func (repository *GachaConfigVersionRepositoryImpl) Publish(ctx context.Context, version *domain.GachaConfigVersion) {
+	lockQuery := `SELECT id FROM gacha_system WHERE id = $1 FOR UPDATE`
+	purgeBannersQuery := `DELETE FROM banner WHERE gacha_system_id = $1 AND deleted_at IS NOT NULL`
+	purgeCharactersQuery := `DELETE FROM character WHERE gacha_system_id = $1 AND deleted_at IS NOT NULL`
+	purgeRaritiesQuery := `DELETE FROM rarity WHERE gacha_system_id = $1 AND deleted_at IS NOT NULL`
+	insertQuery := `INSERT INTO gacha_config_version (gacha_system_id, number, content_hash, snapshot)
+				VALUES ($1, (SELECT COALESCE(MAX(number), 0) + 1 FROM gacha_config_version WHERE gacha_system_id = $1), $2, $3)
+				RETURNING id, number, created_at`
+	activateQuery := `UPDATE gacha_system SET active_config_version_id = $1, initial_publish_pending = FALSE WHERE id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	var gachaSystemId int
+	err = tx.QueryRow(ctx, lockQuery, version.GachaSystemId).Scan(&gachaSystemId)
+	helper.PanicIfError(err, helper.ErrGachaSystemNotFound)
+
+	err = tx.QueryRow(ctx, insertQuery, version.GachaSystemId, version.ContentHash, version.Snapshot).
+		Scan(&version.Id, &version.Number, &version.CreatedAt)
+	helper.PanicIfError(err, "Failed to save gacha config version")
+
+	_, err = tx.Exec(ctx, activateQuery, version.Id, version.GachaSystemId)
+	helper.PanicIfError(err, "Failed to activate gacha config version")
+
+	_, err = tx.Exec(ctx, purgeBannersQuery, version.GachaSystemId)
+	helper.PanicIfError(err, "Failed to remove deleted banners")
+	_, err = tx.Exec(ctx, purgeCharactersQuery, version.GachaSystemId)
+	helper.PanicIfError(err, "Failed to remove deleted characters")
+	_, err = tx.Exec(ctx, purgeRaritiesQuery, version.GachaSystemId)
+	helper.PanicIfError(err, "Failed to remove deleted rarities")
+
+	version.Active = true
+}
+
+func (repository *GachaConfigVersionRepositoryImpl) FindActiveByGachaSystemId(ctx context.Context, gachaSystemId int) *domain.GachaConfigVersion {
+	query := `SELECT v.id, v.gacha_system_id, v.number, v.content_hash, v.snapshot, TRUE, v.created_at
+				FROM gacha_config_version v
+				JOIN gacha_system s ON s.active_config_version_id = v.id
+				WHERE s.id = $1`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getGachaConfigVersionFromRow(tx.QueryRow(ctx, query, gachaSystemId))
+}
+
+func (repository *GachaConfigVersionRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.GachaConfigVersion {
+	query := `SELECT v.id, v.gacha_system_id, v.number, v.content_hash, v.snapshot,
+				COALESCE(s.active_config_version_id = v.id, FALSE), v.created_at
+				FROM gacha_config_version v
+				JOIN gacha_system s ON s.id = v.gacha_system_id
+				WHERE v.id = $1 AND v.gacha_system_id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getGachaConfigVersionFromRow(tx.QueryRow(ctx, query, id, gachaSystemId))
+}
+
+func (repository *GachaConfigVersionRepositoryImpl) FindByNumberAndGachaSystemId(ctx context.Context, number int, gachaSystemId int) *domain.GachaConfigVersion {
+	query := `SELECT v.id, v.gacha_system_id, v.number, v.content_hash, v.snapshot,
+				COALESCE(s.active_config_version_id = v.id, FALSE), v.created_at
+				FROM gacha_config_version v
+				JOIN gacha_system s ON s.id = v.gacha_system_id
+				WHERE v.number = $1 AND v.gacha_system_id = $2`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getGachaConfigVersionFromRow(tx.QueryRow(ctx, query, number, gachaSystemId))
+}
+
+// FindAllByGachaSystemId lists the versions of a gacha system, newest first, without their
+// snapshots.
+func (repository *GachaConfigVersionRepositoryImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.GachaConfigVersion {
+	query := `SELECT v.id, v.gacha_system_id, v.number, v.content_hash, NULL::JSONB,
+				COALESCE(s.active_config_version_id = v.id, FALSE), v.created_at
+				FROM gacha_config_version v
+				JOIN gacha_system s ON s.id = v.gacha_system_id
+				WHERE v.gacha_system_id = $1
+				ORDER BY v.number DESC`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId)
+	if err != nil {
+		log.Printf("Error while querying gacha config versions: %v", err)
+		return nil
+	}
+	defer rows.Close()
+
+	var versions []domain.GachaConfigVersion
+	for rows.Next() {
+		version := getGachaConfigVersionFromRow(rows)
+		if version == nil {
+			continue
+		}
+
+		versions = append(versions, *version)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning gacha config versions: %v", err)
+	}
+
+	return versions
+}
+
+// RestoreDraft overwrites the draft of a gacha system, its pull settings, rarities, characters and
+// banners, with the given configuration in one transaction. Items keep their ids, so references to
+// them stay valid, and items deleted from the draft come back. Items missing from the
+// configuration were added to the draft after it was published and are deleted.
+func (repository *GachaConfigVersionRepositoryImpl) RestoreDraft(ctx context.Context, gachaSystem *domain.GachaSystem, rarities []domain.Rarity, characters []domain.Character, banners []domain.Banner) {
+	settingsQuery := `UPDATE gacha_system
+	          SET guaranteed_rarity_id = NULLIF($1, 0), guarantee_pull_count = $2, provably_fair = $3,
+	              multi_pull_discount = $4, multi_pull_discount_count = $5, mode = $6
+	          WHERE id = $7`
+	deleteBannersQuery := `DELETE FROM banner WHERE gacha_system_id = $1 AND id <> ALL($2)`
+	deleteCharactersQuery := `DELETE FROM character WHERE gacha_system_id = $1 AND id <> ALL($2)`
+	deleteRaritiesQuery := `DELETE FROM rarity WHERE gacha_system_id = $1 AND id <> ALL($2)`
+	rarityQuery := `INSERT INTO rarity (gacha_system_id, id, name, chance, pity_threshold, soft_pity_start, soft_pity_step, featured_chance,
+				duplicate_conversion, duplicate_currency_id, duplicate_shards, max_constellation)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+				ON CONFLICT (gacha_system_id, id) DO UPDATE
+				SET name = EXCLUDED.name, chance = EXCLUDED.chance, pity_threshold = EXCLUDED.pity_threshold,
+				    soft_pity_start = EXCLUDED.soft_pity_start, soft_pity_step = EXCLUDED.soft_pity_step,
+				    featured_chance = EXCLUDED.featured_chance, duplicate_conversion = EXCLUDED.duplicate_conversion,
+				    duplicate_currency_id = EXCLUDED.duplicate_currency_id, duplicate_shards = EXCLUDED.duplicate_shards,
+				    max_constellation = EXCLUDED.max_constellation, deleted_at = NULL`
+	characterQuery := `INSERT INTO character (gacha_system_id, id, rarity_id, name, image_url, featured, weight, box_quantity, box_key)
+				VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9)
+				ON CONFLICT (gacha_system_id, id) DO UPDATE
+				SET rarity_id = EXCLUDED.rarity_id, name = EXCLUDED.name, image_url = EXCLUDED.image_url,
+				    featured = EXCLUDED.featured, weight = EXCLUDED.weight, box_quantity = EXCLUDED.box_quantity,
+				    box_key = EXCLUDED.box_key, deleted_at = NULL`
+	bannerQuery := `INSERT INTO banner (gacha_system_id, id, name, starts_at, ends_at, timezone, featured_character_ids,
+				spark_threshold, spark_currency_id, spark_conversion)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+				ON CONFLICT (id) DO UPDATE
+				SET name = EXCLUDED.name, starts_at = EXCLUDED.starts_at, ends_at = EXCLUDED.ends_at,
+				    timezone = EXCLUDED.timezone, featured_character_ids = EXCLUDED.featured_character_ids,
+				    spark_threshold = EXCLUDED.spark_threshold, spark_currency_id = EXCLUDED.spark_currency_id,
+				    spark_conversion = EXCLUDED.spark_conversion, deleted_at = NULL
+				WHERE banner.gacha_system_id = EXCLUDED.gacha_system_id`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, settingsQuery, gachaSystem.GuaranteedRarityId, gachaSystem.GuaranteePullCount, gachaSystem.ProvablyFair,
+		gachaSystem.MultiPullDiscount, gachaSystem.MultiPullDiscountCount, gachaSystem.Mode, gachaSystem.Id)
+	helper.PanicIfError(err, "Failed to restore gacha system settings")
+
+	rarityIds := []int{}
+	for _, rarity := range rarities {
+		rarityIds = append(rarityIds, rarity.Id)
+	}
+	characterIds := []int{}
+	for _, character := range characters {
+		characterIds = append(characterIds, character.Id)
+	}
+	bannerIds := []int{}
+	for _, banner := range banners {
+		bannerIds = append(bannerIds, banner.Id)
+	}
+
+	// Banners and characters go first, as they point at characters and rarities
+	_, err = tx.Exec(ctx, deleteBannersQuery, gachaSystem.Id, bannerIds)
+	helper.PanicIfError(err, "Failed to delete draft banners")
+	_, err = tx.Exec(ctx, deleteCharactersQuery, gachaSystem.Id, characterIds)
+	helper.PanicIfError(err, "Failed to delete draft characters")
+	_, err = tx.Exec(ctx, deleteRaritiesQuery, gachaSystem.Id, rarityIds)
+	helper.PanicIfError(err, "Failed to delete draft rarities")
+
+	for _, rarity := range rarities {
+		_, err = tx.Exec(ctx, rarityQuery, gachaSystem.Id, rarity.Id, rarity.Name, rarity.Chance, rarity.PityThreshold,
+			rarity.SoftPityStart, rarity.SoftPityStep, rarity.FeaturedChance, rarity.DuplicateConversion,
+			rarity.DuplicateCurrencyId, rarity.DuplicateShards, rarity.MaxConstellation)
+		helper.PanicIfError(err, "Failed to restore rarity")
+	}
+
+	for _, character := range characters {
+		_, err = tx.Exec(ctx, characterQuery, gachaSystem.Id, character.Id, character.RarityId, character.Name, character.ImageUrl,
+			character.Featured, character.Weight, character.BoxQuantity, character.BoxKey)
+		helper.PanicIfError(err, "Failed to restore character")
+	}
+
+	for _, banner := range banners {
+		_, err = tx.Exec(ctx, bannerQuery, gachaSystem.Id, banner.Id, banner.Name, banner.StartsAt, banner.EndsAt, banner.Timezone,
+			featuredCharacterIds(&banner), banner.SparkThreshold, banner.SparkCurrencyId, banner.SparkConversion)
+		helper.PanicIfError(err, "Failed to restore banner")
+	}
+}
+
+func getGachaConfigVersionFromRow(row pgx.Row) *domain.GachaConfigVersion {
+	var version domain.GachaConfigVersion
+
+	err := row.Scan(&version.Id, &version.GachaSystemId, &version.Number, &version.ContentHash, &version.Snapshot,
+		&version.Active, &version.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	helper.PanicIfError(err, "Failed to read gacha config version")
+
+	return &version
+}
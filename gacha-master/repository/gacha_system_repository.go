@@ -4,6 +4,7 @@ import (
 	"context"
 	"gacha-master/helper"
 	"gacha-master/model/domain"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -11,7 +12,11 @@ type GachaSystemRepository interface {
 	Save(ctx context.Context, gachaSystem *domain.GachaSystem)
 	FindByNameAndUserId(ctx context.Context, name string, userId int) *domain.GachaSystem
 	FindByIdAndUserId(ctx context.Context, id int, userId int) *domain.GachaSystem
+	FindByEndpointId(ctx context.Context, endpointId string) *domain.GachaSystem
 	FindAllByUserId(ctx context.Context, userId int) []domain.GachaSystem
+	FindAllPendingInitialPublish(ctx context.Context) []domain.GachaSystem
+	ClearPendingInitialPublish(ctx context.Context, gachaSystemId int)
+	UpdateSettings(ctx context.Context, gachaSystem *domain.GachaSystem)
 	Delete(ctx context.Context, gachaSystemId int)
 }
 
@@ -42,7 +47,8 @@ func (repository *GachaSystemRepositoryImpl) Save(ctx context.Context, gachaSyst
 }
 
 func (repository *GachaSystemRepositoryImpl) FindByNameAndUserId(ctx context.Context, name string, userId int) *domain.GachaSystem {
-	query := `SELECT id, name, endpoint_id
+	query := `SELECT id, name, endpoint_id, COALESCE(guaranteed_rarity_id, 0), guarantee_pull_count, provably_fair, private,
+			endpoint_rate_limit, api_key_rate_limit, player_rate_limit, multi_pull_discount, multi_pull_discount_count, mode
 			FROM gacha_system
 			WHERE LOWER(name) = LOWER($1) AND user_id = $2`
 
@@ -53,17 +59,12 @@ func (repository *GachaSystemRepositoryImpl) FindByNameAndUserId(ctx context.Con
 
 	row := tx.QueryRow(ctx, query, name, userId)
 
-	var gachaSystem domain.GachaSystem
-	err = row.Scan(&gachaSystem.Id, &gachaSystem.Name, &gachaSystem.EndpointId)
-	if err != nil {
-		return nil
-	}
-
-	return &gachaSystem
+	return getGachaSystemFromRow(row)
 }
 
 func (repository *GachaSystemRepositoryImpl) FindByIdAndUserId(ctx context.Context, id int, userId int) *domain.GachaSystem {
-	query := `SELECT id, name, endpoint_id
+	query := `SELECT id, name, endpoint_id, COALESCE(guaranteed_rarity_id, 0), guarantee_pull_count, provably_fair, private,
+			endpoint_rate_limit, api_key_rate_limit, player_rate_limit, multi_pull_discount, multi_pull_discount_count, mode
 			FROM gacha_system
 			WHERE id = $1 AND user_id = $2`
 
@@ -74,17 +75,28 @@ func (repository *GachaSystemRepositoryImpl) FindByIdAndUserId(ctx context.Conte
 
 	row := tx.QueryRow(ctx, query, id, userId)
 
-	var gachaSystem domain.GachaSystem
-	err = row.Scan(&gachaSystem.Id, &gachaSystem.Name, &gachaSystem.EndpointId)
-	if err != nil {
-		return nil
-	}
+	return getGachaSystemFromRow(row)
+}
 
-	return &gachaSystem
+func (repository *GachaSystemRepositoryImpl) FindByEndpointId(ctx context.Context, endpointId string) *domain.GachaSystem {
+	query := `SELECT id, name, endpoint_id, COALESCE(guaranteed_rarity_id, 0), guarantee_pull_count, provably_fair, private,
+			endpoint_rate_limit, api_key_rate_limit, player_rate_limit, multi_pull_discount, multi_pull_discount_count, mode
+			FROM gacha_system
+			WHERE endpoint_id = $1`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	row := tx.QueryRow(ctx, query, endpointId)
+
+	return getGachaSystemFromRow(row)
 }
 
 func (repository *GachaSystemRepositoryImpl) FindAllByUserId(ctx context.Context, userId int) []domain.GachaSystem {
-	query := `SELECT id, name, endpoint_id
+	query := `SELECT id, name, endpoint_id, COALESCE(guaranteed_rarity_id, 0), guarantee_pull_count, provably_fair, private,
+			endpoint_rate_limit, api_key_rate_limit, player_rate_limit, multi_pull_discount, multi_pull_discount_count, mode
               FROM gacha_system
               WHERE user_id = $1`
 
@@ -101,11 +113,11 @@ func (repository *GachaSystemRepositoryImpl) FindAllByUserId(ctx context.Context
 
 	var gachaSystems []domain.GachaSystem
 	for rows.Next() {
-		var gachaSystem domain.GachaSystem
-		if err := rows.Scan(&gachaSystem.Id, &gachaSystem.Name, &gachaSystem.EndpointId); err != nil {
+		gachaSystem := getGachaSystemFromRow(rows)
+		if gachaSystem == nil {
 			return nil
 		}
-		gachaSystems = append(gachaSystems, gachaSystem)
+		gachaSystems = append(gachaSystems, *gachaSystem)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -115,6 +127,65 @@ func (repository *GachaSystemRepositoryImpl) FindAllByUserId(ctx context.Context
 	return gachaSystems
 }
 
+// FindAllPendingInitialPublish lists the gacha systems that were served before config versions
+// existed and have not been published yet.
+func (repository *GachaSystemRepositoryImpl) FindAllPendingInitialPublish(ctx context.Context) []domain.GachaSystem {
+	query := `SELECT id, name, endpoint_id, COALESCE(guaranteed_rarity_id, 0), guarantee_pull_count, provably_fair, private,
+			endpoint_rate_limit, api_key_rate_limit, player_rate_limit, multi_pull_discount, multi_pull_discount_count, mode
+              FROM gacha_system
+              WHERE initial_publish_pending`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query)
+	helper.PanicIfError(err, "Failed to query gacha systems pending their first publish")
+	defer rows.Close()
+
+	var gachaSystems []domain.GachaSystem
+	for rows.Next() {
+		gachaSystem := getGachaSystemFromRow(rows)
+		if gachaSystem == nil {
+			continue
+		}
+		gachaSystems = append(gachaSystems, *gachaSystem)
+	}
+
+	return gachaSystems
+}
+
+func (repository *GachaSystemRepositoryImpl) ClearPendingInitialPublish(ctx context.Context, gachaSystemId int) {
+	query := `UPDATE gacha_system SET initial_publish_pending = FALSE WHERE id = $1`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, gachaSystemId)
+	helper.PanicIfError(err, "Failed to update gacha system")
+}
+
+func (repository *GachaSystemRepositoryImpl) UpdateSettings(ctx context.Context, gachaSystem *domain.GachaSystem) {
+	query := `UPDATE gacha_system
+	          SET guaranteed_rarity_id = NULLIF($1, 0), guarantee_pull_count = $2, provably_fair = $3, private = $4,
+	              endpoint_rate_limit = $5, api_key_rate_limit = $6, player_rate_limit = $7,
+	              multi_pull_discount = $8, multi_pull_discount_count = $9, mode = $10
+	          WHERE id = $11`
+
+	tx, err := repository.Dbpool.Begin(ctx)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, gachaSystem.GuaranteedRarityId, gachaSystem.GuaranteePullCount, gachaSystem.ProvablyFair, gachaSystem.Private,
+		gachaSystem.EndpointRateLimit, gachaSystem.ApiKeyRateLimit, gachaSystem.PlayerRateLimit,
+		gachaSystem.MultiPullDiscount, gachaSystem.MultiPullDiscountCount, gachaSystem.Mode, gachaSystem.Id)
+	helper.PanicIfError(err, "Failed to update gacha system settings")
+}
+
 func (repository *GachaSystemRepositoryImpl) Delete(ctx context.Context, gachaSystemId int) {
 	query := `DELETE FROM gacha_system WHERE id = $1 `
 
@@ -126,3 +197,16 @@ func (repository *GachaSystemRepositoryImpl) Delete(ctx context.Context, gachaSy
 	_, err = tx.Exec(ctx, query, gachaSystemId)
 	helper.PanicIfError(err, "Failed to delete gacha system")
 }
+
+func getGachaSystemFromRow(row pgx.Row) *domain.GachaSystem {
+	var gachaSystem domain.GachaSystem
+
+	err := row.Scan(&gachaSystem.Id, &gachaSystem.Name, &gachaSystem.EndpointId, &gachaSystem.GuaranteedRarityId, &gachaSystem.GuaranteePullCount, &gachaSystem.ProvablyFair, &gachaSystem.Private,
+		&gachaSystem.EndpointRateLimit, &gachaSystem.ApiKeyRateLimit, &gachaSystem.PlayerRateLimit,
+		&gachaSystem.MultiPullDiscount, &gachaSystem.MultiPullDiscountCount, &gachaSystem.Mode)
+	if err != nil {
+		return nil
+	}
+
+	return &gachaSystem
+}
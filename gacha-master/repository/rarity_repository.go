@@ -28,8 +28,9 @@ func NewRarityRepository(dbpool *pgxpool.Pool) RarityRepository {
 }
 
 func (repository *RarityRepositoryImpl) Save(ctx context.Context, rarity *domain.Rarity) {
-	query := `INSERT INTO rarity (gacha_system_id, name, chance) 
-				VALUES ($1, $2, $3) RETURNING id`
+	query := `INSERT INTO rarity (gacha_system_id, name, chance, pity_threshold, soft_pity_start, soft_pity_step, featured_chance,
+				duplicate_conversion, duplicate_currency_id, duplicate_shards, max_constellation) 
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -37,14 +38,17 @@ func (repository *RarityRepositoryImpl) Save(ctx context.Context, rarity *domain
 	defer helper.CommitOrRollback(tx, ctx)
 
 	var id int
-	err = tx.QueryRow(ctx, query, rarity.GachaSystemId, rarity.Name, rarity.Chance).Scan(&id)
+	err = tx.QueryRow(ctx, query, rarity.GachaSystemId, rarity.Name, rarity.Chance, rarity.PityThreshold, rarity.SoftPityStart, rarity.SoftPityStep, rarity.FeaturedChance,
+		rarity.DuplicateConversion, rarity.DuplicateCurrencyId, rarity.DuplicateShards, rarity.MaxConstellation).Scan(&id)
 	helper.PanicIfError(err, helper.ErrUserNotFound)
 
 	rarity.Id = id
 }
 
 func (repository *RarityRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Context, id int, gachaSystemId int) *domain.Rarity {
-	query := `SELECT id, name, chance, gacha_system_id FROM rarity WHERE id = $1 AND gacha_system_id = $2`
+	query := `SELECT id, name, chance, pity_threshold, soft_pity_start, soft_pity_step, featured_chance, gacha_system_id,
+				duplicate_conversion, duplicate_currency_id, duplicate_shards, max_constellation
+			FROM rarity WHERE id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -54,7 +58,8 @@ func (repository *RarityRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Con
 	row := tx.QueryRow(ctx, query, id, gachaSystemId)
 
 	var rarity domain.Rarity
-	err = row.Scan(&rarity.Id, &rarity.Name, &rarity.Chance, &rarity.GachaSystemId)
+	err = row.Scan(&rarity.Id, &rarity.Name, &rarity.Chance, &rarity.PityThreshold, &rarity.SoftPityStart, &rarity.SoftPityStep, &rarity.FeaturedChance, &rarity.GachaSystemId,
+		&rarity.DuplicateConversion, &rarity.DuplicateCurrencyId, &rarity.DuplicateShards, &rarity.MaxConstellation)
 	if err != nil {
 		return nil
 	}
@@ -63,7 +68,9 @@ func (repository *RarityRepositoryImpl) FindByIdAndGachaSystemId(ctx context.Con
 }
 
 func (repository *RarityRepositoryImpl) FindByNameAndGachaSystemId(ctx context.Context, name string, gachaSystemId int) *domain.Rarity {
-	query := `SELECT id, name, chance, gacha_system_id FROM rarity WHERE LOWER(name) = LOWER($1) AND gacha_system_id = $2`
+	query := `SELECT id, name, chance, pity_threshold, soft_pity_start, soft_pity_step, featured_chance, gacha_system_id,
+				duplicate_conversion, duplicate_currency_id, duplicate_shards, max_constellation
+			FROM rarity WHERE LOWER(name) = LOWER($1) AND gacha_system_id = $2 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -73,7 +80,8 @@ func (repository *RarityRepositoryImpl) FindByNameAndGachaSystemId(ctx context.C
 	row := tx.QueryRow(ctx, query, name, gachaSystemId)
 
 	var rarity domain.Rarity
-	err = row.Scan(&rarity.Id, &rarity.Name, &rarity.Chance, &rarity.GachaSystemId)
+	err = row.Scan(&rarity.Id, &rarity.Name, &rarity.Chance, &rarity.PityThreshold, &rarity.SoftPityStart, &rarity.SoftPityStep, &rarity.FeaturedChance, &rarity.GachaSystemId,
+		&rarity.DuplicateConversion, &rarity.DuplicateCurrencyId, &rarity.DuplicateShards, &rarity.MaxConstellation)
 	if err != nil {
 		return nil
 	}
@@ -82,7 +90,9 @@ func (repository *RarityRepositoryImpl) FindByNameAndGachaSystemId(ctx context.C
 }
 
 func (repository *RarityRepositoryImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []domain.Rarity {
-	query := `SELECT id, name, chance, gacha_system_id FROM rarity WHERE gacha_system_id = $1`
+	query := `SELECT id, name, chance, pity_threshold, soft_pity_start, soft_pity_step, featured_chance, gacha_system_id,
+				duplicate_conversion, duplicate_currency_id, duplicate_shards, max_constellation
+			FROM rarity WHERE gacha_system_id = $1 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
@@ -98,7 +108,8 @@ func (repository *RarityRepositoryImpl) FindAllByGachaSystemId(ctx context.Conte
 	var rarities []domain.Rarity
 	for rows.Next() {
 		var rarity domain.Rarity
-		err = rows.Scan(&rarity.Id, &rarity.Name, &rarity.Chance, &rarity.GachaSystemId)
+		err = rows.Scan(&rarity.Id, &rarity.Name, &rarity.Chance, &rarity.PityThreshold, &rarity.SoftPityStart, &rarity.SoftPityStep, &rarity.FeaturedChance, &rarity.GachaSystemId,
+			&rarity.DuplicateConversion, &rarity.DuplicateCurrencyId, &rarity.DuplicateShards, &rarity.MaxConstellation)
 
 		rarities = append(rarities, rarity)
 	}
@@ -112,26 +123,37 @@ func (repository *RarityRepositoryImpl) FindAllByGachaSystemId(ctx context.Conte
 
 func (repository *RarityRepositoryImpl) Update(ctx context.Context, rarity *domain.Rarity) {
 	query := `UPDATE rarity 
-	          SET name = $1, chance = $2 
-	          WHERE id = $3 AND gacha_system_id = $4`
+	          SET name = $1, chance = $2, pity_threshold = $3, soft_pity_start = $4, soft_pity_step = $5,
+	              featured_chance = $6, duplicate_conversion = $7, duplicate_currency_id = $8, duplicate_shards = $9,
+	              max_constellation = $10
+	          WHERE id = $11 AND gacha_system_id = $12`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
 
 	defer helper.CommitOrRollback(tx, ctx)
 
-	_, err = tx.Exec(ctx, query, rarity.Name, rarity.Chance, rarity.Id, rarity.GachaSystemId)
+	_, err = tx.Exec(ctx, query, rarity.Name, rarity.Chance, rarity.PityThreshold, rarity.SoftPityStart, rarity.SoftPityStep, rarity.FeaturedChance,
+		rarity.DuplicateConversion, rarity.DuplicateCurrencyId, rarity.DuplicateShards, rarity.MaxConstellation, rarity.Id, rarity.GachaSystemId)
 	helper.PanicIfError(err, "Failed to update rarity")
 }
 
+// Delete removes a rarity and its characters from the draft. The rows are only marked, they stay
+// until the next publish so discarding the draft can bring them back.
 func (repository *RarityRepositoryImpl) Delete(ctx context.Context, id int, gachaSystemId int) {
-	query := `DELETE FROM rarity WHERE id = $1 AND gacha_system_id = $2`
+	charactersQuery := `UPDATE character SET deleted_at = CURRENT_TIMESTAMP
+	          WHERE rarity_id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
+	query := `UPDATE rarity SET deleted_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND gacha_system_id = $2 AND deleted_at IS NULL`
 
 	tx, err := repository.Dbpool.Begin(ctx)
 	helper.PanicIfError(err, helper.ErrBeginTransaction)
 
 	defer helper.CommitOrRollback(tx, ctx)
 
+	_, err = tx.Exec(ctx, charactersQuery, id, gachaSystemId)
+	helper.PanicIfError(err, "Failed to delete characters of rarity")
+
 	_, err = tx.Exec(ctx, query, id, gachaSystemId)
 	helper.PanicIfError(err, "Failed to delete rarity")
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"gacha-master/client"
+	"gacha-master/exception"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"gacha-master/model/web"
+	"gacha-master/repository"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+type ApiKeyService interface {
+	Create(ctx context.Context, request *web.ApiKeyCreateRequest) *web.ApiKeyCreateResponse
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []web.ApiKeyResponse
+	Revoke(ctx context.Context, id int, gachaSystemId int) *web.ApiKeyResponse
+}
+
+type ApiKeyServiceImpl struct {
+	ApiKeyRepository      repository.ApiKeyRepository
+	GachaSystemRepository repository.GachaSystemRepository
+	GachaPullClient       client.GachaPullClient
+	Validate              *validator.Validate
+}
+
+func NewApiKeyService(
+	apiKeyRepository repository.ApiKeyRepository,
+	gachaSystemRepository repository.GachaSystemRepository,
+	gachaPullClient client.GachaPullClient,
+	validate *validator.Validate,
+) ApiKeyService {
+	return &ApiKeyServiceImpl{
+		ApiKeyRepository:      apiKeyRepository,
+		GachaSystemRepository: gachaSystemRepository,
+		GachaPullClient:       gachaPullClient,
+		Validate:              validate,
+	}
+}
+
+func (service *ApiKeyServiceImpl) Create(ctx context.Context, request *web.ApiKeyCreateRequest) *web.ApiKeyCreateResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		panic(exception.NewBadRequestError("Expiry date must be in the future"))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	key, prefix := helper.GenerateApiKey()
+
+	apiKey := domain.ApiKey{
+		GachaSystemId: request.GachaSystemId,
+		Name:          request.Name,
+		Prefix:        prefix,
+		KeyHash:       helper.HashApiKey(key),
+		ExpiresAt:     request.ExpiresAt,
+	}
+
+	service.ApiKeyRepository.Save(ctx, &apiKey)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+
+	return &web.ApiKeyCreateResponse{
+		ApiKeyResponse: *web.ToApiKeyResponse(&apiKey),
+		Key:            key,
+	}
+}
+
+func (service *ApiKeyServiceImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []web.ApiKeyResponse {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	apiKeys := service.ApiKeyRepository.FindAllByGachaSystemId(ctx, gachaSystemId)
+	if apiKeys == nil {
+		return nil
+	}
+
+	return web.ToApiKeysResponse(apiKeys)
+}
+
+func (service *ApiKeyServiceImpl) Revoke(ctx context.Context, id int, gachaSystemId int) *web.ApiKeyResponse {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	apiKey := service.ApiKeyRepository.FindByIdAndGachaSystemId(ctx, id, gachaSystemId)
+	if apiKey == nil {
+		panic(exception.NewNotFoundError(helper.ErrApiKeyNotFound))
+	}
+
+	if apiKey.RevokedAt != nil {
+		panic(exception.NewConflictError("API key is already revoked"))
+	}
+
+	service.ApiKeyRepository.Revoke(ctx, apiKey)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+
+	return web.ToApiKeyResponse(apiKey)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-master/exception"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"gacha-master/model/web"
+	"gacha-master/repository"
+	"github.com/go-playground/validator/v10"
+	"time"
+)
+
+const DefaultBannerTimezone = "UTC"
+
+var bannerTimeLayouts = []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+type BannerService interface {
+	Create(ctx context.Context, request *web.BannerCreateRequest) *web.BannerResponse
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []web.BannerResponse
+	Update(ctx context.Context, request *web.BannerUpdateRequest) *web.BannerResponse
+	Delete(ctx context.Context, id int, gachaSystemId int)
+}
+
+type BannerServiceImpl struct {
+	BannerRepository      repository.BannerRepository
+	CharacterRepository   repository.CharacterRepository
+	GachaSystemRepository repository.GachaSystemRepository
+	CurrencyRepository    repository.CurrencyRepository
+	Validate              *validator.Validate
+}
+
+func NewBannerService(
+	bannerRepository repository.BannerRepository,
+	characterRepository repository.CharacterRepository,
+	gachaSystemRepository repository.GachaSystemRepository,
+	currencyRepository repository.CurrencyRepository,
+	validate *validator.Validate,
+) BannerService {
+	return &BannerServiceImpl{
+		BannerRepository:      bannerRepository,
+		CharacterRepository:   characterRepository,
+		GachaSystemRepository: gachaSystemRepository,
+		CurrencyRepository:    currencyRepository,
+		Validate:              validate,
+	}
+}
+
+func (service *BannerServiceImpl) Create(ctx context.Context, request *web.BannerCreateRequest) *web.BannerResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	banner := domain.Banner{
+		GachaSystemId:        request.GachaSystemId,
+		Name:                 request.Name,
+		Timezone:             request.Timezone,
+		FeaturedCharacterIds: request.FeaturedCharacterIds,
+		SparkThreshold:       request.SparkThreshold,
+		SparkCurrencyId:      request.SparkCurrencyId,
+		SparkConversion:      request.SparkConversion,
+	}
+	service.scheduleBanner(ctx, &banner, request.StartsAt, request.EndsAt)
+	service.validateSpark(ctx, &banner)
+
+	if !banner.EndsAt.After(time.Now()) {
+		panic(exception.NewBadRequestError("Banner must end in the future"))
+	}
+
+	service.BannerRepository.Save(ctx, &banner)
+
+	return web.ToBannerResponse(&banner)
+}
+
+func (service *BannerServiceImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []web.BannerResponse {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	banners := service.BannerRepository.FindAllByGachaSystemId(ctx, gachaSystemId)
+	if banners == nil {
+		return nil
+	}
+
+	return web.ToBannersResponse(banners)
+}
+
+func (service *BannerServiceImpl) Update(ctx context.Context, request *web.BannerUpdateRequest) *web.BannerResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	banner := service.BannerRepository.FindByIdAndGachaSystemId(ctx, request.Id, request.GachaSystemId)
+	if banner == nil {
+		panic(exception.NewNotFoundError(helper.ErrBannerNotFound))
+	}
+
+	banner.Name = request.Name
+	banner.Timezone = request.Timezone
+	banner.FeaturedCharacterIds = request.FeaturedCharacterIds
+	banner.SparkThreshold = request.SparkThreshold
+	banner.SparkCurrencyId = request.SparkCurrencyId
+	banner.SparkConversion = request.SparkConversion
+	service.scheduleBanner(ctx, banner, request.StartsAt, request.EndsAt)
+	service.validateSpark(ctx, banner)
+
+	service.BannerRepository.Update(ctx, banner)
+
+	return web.ToBannerResponse(banner)
+}
+
+func (service *BannerServiceImpl) Delete(ctx context.Context, id int, gachaSystemId int) {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	banner := service.BannerRepository.FindByIdAndGachaSystemId(ctx, id, gachaSystemId)
+	if banner == nil {
+		panic(exception.NewNotFoundError(helper.ErrBannerNotFound))
+	}
+
+	service.BannerRepository.Delete(ctx, id, gachaSystemId)
+}
+
+// scheduleBanner sets the window of a banner from local times in its timezone and checks that
+// the window does not overlap another banner and that its featured characters exist.
+func (service *BannerServiceImpl) scheduleBanner(ctx context.Context, banner *domain.Banner, startsAt string, endsAt string) {
+	if banner.Timezone == "" {
+		banner.Timezone = DefaultBannerTimezone
+	}
+
+	location, err := time.LoadLocation(banner.Timezone)
+	if err != nil {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Unknown timezone %s", banner.Timezone)))
+	}
+
+	banner.StartsAt = parseBannerTime(startsAt, location, "Invalid banner start time")
+	banner.EndsAt = parseBannerTime(endsAt, location, "Invalid banner end time")
+	if !banner.EndsAt.After(banner.StartsAt) {
+		panic(exception.NewBadRequestError("Banner must end after it starts"))
+	}
+
+	if service.BannerRepository.ExistsOverlapping(ctx, banner.GachaSystemId, banner.StartsAt, banner.EndsAt, banner.Id) {
+		panic(exception.NewConflictError("Banner overlaps another banner of the gacha system"))
+	}
+
+	for _, characterId := range banner.FeaturedCharacterIds {
+		character := service.CharacterRepository.FindByIdAndGachaSystemId(ctx, characterId, banner.GachaSystemId)
+		if character == nil {
+			panic(exception.NewNotFoundError(helper.ErrCharacterNotFound))
+		}
+	}
+}
+
+// validateSpark checks that leftover spark points convert into an existing currency. Without a
+// spark threshold the spark settings are cleared.
+func (service *BannerServiceImpl) validateSpark(ctx context.Context, banner *domain.Banner) {
+	if banner.SparkThreshold == 0 {
+		banner.SparkCurrencyId = nil
+		banner.SparkConversion = 0
+		return
+	}
+
+	if banner.SparkCurrencyId == nil {
+		banner.SparkConversion = 0
+		return
+	}
+
+	if banner.SparkConversion == 0 {
+		panic(exception.NewBadRequestError("Spark conversion is required when a spark currency is set"))
+	}
+
+	currency := service.CurrencyRepository.FindByIdAndGachaSystemId(ctx, *banner.SparkCurrencyId, banner.GachaSystemId)
+	if currency == nil {
+		panic(exception.NewNotFoundError(helper.ErrCurrencyNotFound))
+	}
+}
+
+func parseBannerTime(value string, location *time.Location, message string) time.Time {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed
+	}
+
+	for _, layout := range bannerTimeLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, location); err == nil {
+			return parsed
+		}
+	}
+
+	panic(exception.NewBadRequestError(message))
+}
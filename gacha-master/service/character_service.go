@@ -68,6 +68,10 @@ func (service *CharacterServiceImpl) Create(ctx context.Context, request *web.Ch
 		Name:          request.Name,
 		GachaSystemId: request.GachaSystemId,
 		RarityId:      request.RarityId,
+		Featured:      request.Featured,
+		Weight:        request.Weight,
+		BoxQuantity:   request.BoxQuantity,
+		BoxKey:        request.BoxKey,
 	}
 
 	service.CharacterRepository.Save(ctx, &character)
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"gacha-master/client"
+	"gacha-master/exception"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"gacha-master/model/web"
+	"gacha-master/repository"
+	"github.com/go-playground/validator/v10"
+)
+
+type CurrencyService interface {
+	Create(ctx context.Context, request *web.CurrencyCreateRequest) *web.CurrencyResponse
+	FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []web.CurrencyResponse
+	Update(ctx context.Context, request *web.CurrencyUpdateRequest) *web.CurrencyResponse
+	Delete(ctx context.Context, id int, gachaSystemId int)
+}
+
+type CurrencyServiceImpl struct {
+	CurrencyRepository    repository.CurrencyRepository
+	GachaSystemRepository repository.GachaSystemRepository
+	GachaPullClient       client.GachaPullClient
+	Validate              *validator.Validate
+}
+
+func NewCurrencyService(
+	currencyRepository repository.CurrencyRepository,
+	gachaSystemRepository repository.GachaSystemRepository,
+	gachaPullClient client.GachaPullClient,
+	validate *validator.Validate,
+) CurrencyService {
+	return &CurrencyServiceImpl{
+		CurrencyRepository:    currencyRepository,
+		GachaSystemRepository: gachaSystemRepository,
+		GachaPullClient:       gachaPullClient,
+		Validate:              validate,
+	}
+}
+
+func (service *CurrencyServiceImpl) Create(ctx context.Context, request *web.CurrencyCreateRequest) *web.CurrencyResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	existingCurrency := service.CurrencyRepository.FindByNameAndGachaSystemId(ctx, request.Name, request.GachaSystemId)
+	if existingCurrency != nil {
+		panic(exception.NewConflictError("Currency with the same name already exists"))
+	}
+
+	currency := domain.Currency{
+		Name:          request.Name,
+		PullCost:      request.PullCost,
+		GachaSystemId: request.GachaSystemId,
+	}
+
+	service.CurrencyRepository.Save(ctx, &currency)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+
+	return web.ToCurrencyResponse(&currency)
+}
+
+func (service *CurrencyServiceImpl) FindAllByGachaSystemId(ctx context.Context, gachaSystemId int) []web.CurrencyResponse {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	currencies := service.CurrencyRepository.FindAllByGachaSystemId(ctx, gachaSystemId)
+	if currencies == nil {
+		return nil
+	}
+
+	return web.ToCurrenciesResponse(currencies)
+}
+
+func (service *CurrencyServiceImpl) Update(ctx context.Context, request *web.CurrencyUpdateRequest) *web.CurrencyResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	currency := service.CurrencyRepository.FindByIdAndGachaSystemId(ctx, request.Id, request.GachaSystemId)
+	if currency == nil {
+		panic(exception.NewNotFoundError(helper.ErrCurrencyNotFound))
+	}
+
+	request.UpdateCurrency(currency)
+
+	existingCurrency := service.CurrencyRepository.FindByNameAndGachaSystemId(ctx, currency.Name, currency.GachaSystemId)
+	if existingCurrency != nil && existingCurrency.Id != currency.Id {
+		panic(exception.NewConflictError("Currency with the same name already exists"))
+	}
+
+	service.CurrencyRepository.Update(ctx, currency)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+
+	return web.ToCurrencyResponse(currency)
+}
+
+func (service *CurrencyServiceImpl) Delete(ctx context.Context, id int, gachaSystemId int) {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	currency := service.CurrencyRepository.FindByIdAndGachaSystemId(ctx, id, gachaSystemId)
+	if currency == nil {
+		panic(exception.NewNotFoundError(helper.ErrCurrencyNotFound))
+	}
+
+	service.CurrencyRepository.Delete(ctx, id, gachaSystemId)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"reflect"
+	"sort"
+)
+
+// diffGachaConfigFields lists the fields that differ between two parts of a config snapshot, by
+// their JSON name.
+func diffGachaConfigFields(from interface{}, to interface{}) []web.GachaConfigFieldChange {
+	return diffJsonFields(toJsonObject(from), toJsonObject(to))
+}
+
+// diffGachaConfigItems matches two lists of snapshot items by id and lists the items added,
+// removed or changed between them, by id.
+func diffGachaConfigItems(from interface{}, to interface{}) []web.GachaConfigItemChange {
+	fromItems := toJsonObjectsById(from)
+	toItems := toJsonObjectsById(to)
+
+	var ids []int
+	for id := range fromItems {
+		ids = append(ids, id)
+	}
+	for id := range toItems {
+		if _, ok := fromItems[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	itemChanges := []web.GachaConfigItemChange{}
+	for _, id := range ids {
+		fromItem, inFrom := fromItems[id]
+		toItem, inTo := toItems[id]
+
+		switch {
+		case !inFrom:
+			itemChanges = append(itemChanges, web.GachaConfigItemChange{Id: id, Change: web.GachaConfigChangeAdded})
+		case !inTo:
+			itemChanges = append(itemChanges, web.GachaConfigItemChange{Id: id, Change: web.GachaConfigChangeRemoved})
+		default:
+			if fieldChanges := diffJsonFields(fromItem, toItem); len(fieldChanges) > 0 {
+				itemChanges = append(itemChanges, web.GachaConfigItemChange{Id: id, Change: web.GachaConfigChangeChanged, Fields: fieldChanges})
+			}
+		}
+	}
+
+	return itemChanges
+}
+
+func diffJsonFields(from map[string]interface{}, to map[string]interface{}) []web.GachaConfigFieldChange {
+	var fields []string
+	for field := range from {
+		fields = append(fields, field)
+	}
+	for field := range to {
+		if _, ok := from[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+
+	fieldChanges := []web.GachaConfigFieldChange{}
+	for _, field := range fields {
+		if !reflect.DeepEqual(from[field], to[field]) {
+			fieldChanges = append(fieldChanges, web.GachaConfigFieldChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+
+	return fieldChanges
+}
+
+func toJsonObject(value interface{}) map[string]interface{} {
+	content, err := json.Marshal(value)
+	helper.PanicIfError(err, "Failed to encode gacha config")
+
+	var object map[string]interface{}
+	err = json.Unmarshal(content, &object)
+	helper.PanicIfError(err, "Failed to decode gacha config")
+
+	return object
+}
+
+func toJsonObjectsById(values interface{}) map[int]map[string]interface{} {
+	content, err := json.Marshal(values)
+	helper.PanicIfError(err, "Failed to encode gacha config")
+
+	var objects []map[string]interface{}
+	err = json.Unmarshal(content, &objects)
+	helper.PanicIfError(err, "Failed to decode gacha config")
+
+	objectsById := make(map[int]map[string]interface{}, len(objects))
+	for _, object := range objects {
+		id, _ := object["id"].(float64)
+		objectsById[int(id)] = object
+	}
+
+	return objectsById
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gacha-master/client"
+	"gacha-master/exception"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"gacha-master/model/web"
+	"gacha-master/repository"
+	"github.com/go-playground/validator/v10"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+// GachaConfigSchemaVersion is raised whenever the layout of the config snapshot changes in a way
+// older readers cannot handle.
+const GachaConfigSchemaVersion = 1
+
+type GachaConfigService interface {
+	FindByEndpointId(ctx context.Context, endpointId string) *web.GachaConfigResponse
+	FindVersionByEndpointId(ctx context.Context, endpointId string, versionId int) *web.GachaConfigResponse
+	Publish(ctx context.Context, request *web.GachaConfigPublishRequest) *web.GachaConfigVersionResponse
+	PublishPending(ctx context.Context)
+	Discard(ctx context.Context, request *web.GachaConfigDiscardRequest) *web.GachaConfigVersionResponse
+	FindAllVersions(ctx context.Context, gachaSystemId int) []web.GachaConfigVersionResponse
+	FindVersion(ctx context.Context, gachaSystemId int, number int) *web.GachaConfigVersionResponse
+	Diff(ctx context.Context, gachaSystemId int, from int, to int) *web.GachaConfigDiffResponse
+}
+
+type GachaConfigServiceImpl struct {
+	GachaSystemRepository        repository.GachaSystemRepository
+	RarityRepository             repository.RarityRepository
+	CharacterRepository          repository.CharacterRepository
+	BannerRepository             repository.BannerRepository
+	GachaConfigVersionRepository repository.GachaConfigVersionRepository
+	CurrencyRepository           repository.CurrencyRepository
+	ApiKeyRepository             repository.ApiKeyRepository
+	GachaPullClient              client.GachaPullClient
+	Validate                     *validator.Validate
+}
+
+func NewGachaConfigService(
+	gachaSystemRepository repository.GachaSystemRepository,
+	rarityRepository repository.RarityRepository,
+	characterRepository repository.CharacterRepository,
+	bannerRepository repository.BannerRepository,
+	gachaConfigVersionRepository repository.GachaConfigVersionRepository,
+	currencyRepository repository.CurrencyRepository,
+	apiKeyRepository repository.ApiKeyRepository,
+	gachaPullClient client.GachaPullClient,
+	validate *validator.Validate,
+) GachaConfigService {
+	return &GachaConfigServiceImpl{
+		GachaSystemRepository:        gachaSystemRepository,
+		RarityRepository:             rarityRepository,
+		CharacterRepository:          characterRepository,
+		BannerRepository:             bannerRepository,
+		GachaConfigVersionRepository: gachaConfigVersionRepository,
+		CurrencyRepository:           currencyRepository,
+		ApiKeyRepository:             apiKeyRepository,
+		GachaPullClient:              gachaPullClient,
+		Validate:                     validate,
+	}
+}
+
+// FindByEndpointId answers gacha-pull with the active config version of a gacha system, so edits
+// only reach players once they are published. Name, privacy, rate limits, currencies and API keys
+// control access and payment rather than pulls and are always taken live, so gacha-pull needs
+// nothing but this snapshot to serve a gacha system. A gacha system never published is not served
+// at all.
+func (service *GachaConfigServiceImpl) FindByEndpointId(ctx context.Context, endpointId string) *web.GachaConfigResponse {
+	gachaSystem := service.GachaSystemRepository.FindByEndpointId(ctx, endpointId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	version := service.GachaConfigVersionRepository.FindActiveByGachaSystemId(ctx, gachaSystem.Id)
+	if version == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotPublished))
+	}
+
+	configResponse := toVersionConfig(version)
+	overlayLiveSettings(configResponse, gachaSystem)
+	configResponse.Currencies, configResponse.ApiKeys = web.ToGachaConfigAccess(
+		service.CurrencyRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id),
+		service.ApiKeyRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id))
+
+	configResponse.Version = hashGachaConfig(configResponse)
+
+	return configResponse
+}
+
+// FindVersionByEndpointId answers gacha-pull with a config version of the gacha system behind an
+// endpoint, active or not, so pulls can be replayed with the configuration they were made with.
+// Versions never change, the snapshot is returned without currencies and API keys.
+func (service *GachaConfigServiceImpl) FindVersionByEndpointId(ctx context.Context, endpointId string, versionId int) *web.GachaConfigResponse {
+	gachaSystem := service.GachaSystemRepository.FindByEndpointId(ctx, endpointId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	version := service.GachaConfigVersionRepository.FindByIdAndGachaSystemId(ctx, versionId, gachaSystem.Id)
+	if version == nil {
+		panic(exception.NewNotFoundError(helper.ErrConfigVersionNotFound))
+	}
+
+	configResponse := toVersionConfig(version)
+	overlayLiveSettings(configResponse, gachaSystem)
+
+	return configResponse
+}
+
+// Publish freezes the draft of a gacha system as its next config version, once the draft is
+// found fit for pulls. Publishing a draft that did not change since the active version keeps that
+// version.
+func (service *GachaConfigServiceImpl) Publish(ctx context.Context, request *web.GachaConfigPublishRequest) *web.GachaConfigVersionResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	gachaSystem := service.findGachaSystem(ctx, request.GachaSystemId)
+
+	configResponse := service.draftConfig(ctx, gachaSystem)
+	if problems := validateDraftConfig(configResponse); len(problems) > 0 {
+		panic(exception.NewBadRequestError("Draft cannot be published: " + strings.Join(problems, "; ")))
+	}
+
+	version := service.publish(ctx, gachaSystem, configResponse)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+
+	return web.ToGachaConfigVersionResponse(version)
+}
+
+// PublishPending publishes the first version of the gacha systems that were served straight from
+// their draft before config versions existed, so they stay available after the upgrade. A draft
+// that is not fit for pulls is left unpublished for its owner to fix and publish.
+func (service *GachaConfigServiceImpl) PublishPending(ctx context.Context) {
+	for _, gachaSystem := range service.GachaSystemRepository.FindAllPendingInitialPublish(ctx) {
+		configResponse := service.draftConfig(ctx, &gachaSystem)
+		if problems := validateDraftConfig(configResponse); len(problems) > 0 {
+			log.Printf("Gacha system %d is left unpublished: %s", gachaSystem.Id, strings.Join(problems, "; "))
+			service.GachaSystemRepository.ClearPendingInitialPublish(ctx, gachaSystem.Id)
+			continue
+		}
+
+		version := service.publish(ctx, &gachaSystem, configResponse)
+		log.Printf("Gacha system %d is published as version %d", gachaSystem.Id, version.Number)
+	}
+}
+
+// publish freezes a validated draft snapshot as the next config version, or returns the active
+// version when the draft did not change since.
+func (service *GachaConfigServiceImpl) publish(ctx context.Context, gachaSystem *domain.GachaSystem, configResponse *web.GachaConfigResponse) *domain.GachaConfigVersion {
+	contentHash := hashGachaConfig(configResponse)
+
+	active := service.GachaConfigVersionRepository.FindActiveByGachaSystemId(ctx, gachaSystem.Id)
+	if active != nil && active.ContentHash == contentHash {
+		return active
+	}
+
+	snapshot, err := json.Marshal(configResponse)
+	helper.PanicIfError(err, "Failed to encode gacha config")
+
+	version := domain.GachaConfigVersion{
+		GachaSystemId: gachaSystem.Id,
+		ContentHash:   contentHash,
+		Snapshot:      snapshot,
+	}
+	service.GachaConfigVersionRepository.Publish(ctx, &version)
+
+	return &version
+}
+
+// Discard throws the draft of a gacha system away by restoring the active config version into the
+// rarities, characters, banners and pull settings being edited.
+func (service *GachaConfigServiceImpl) Discard(ctx context.Context, request *web.GachaConfigDiscardRequest) *web.GachaConfigVersionResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	gachaSystem := service.findGachaSystem(ctx, request.GachaSystemId)
+
+	version := service.GachaConfigVersionRepository.FindActiveByGachaSystemId(ctx, gachaSystem.Id)
+	if version == nil {
+		panic(exception.NewBadRequestError("Gacha system was never published, there is no version to go back to"))
+	}
+
+	configResponse := toVersionConfig(version)
+	restored, rarities, characters, banners := toDraft(gachaSystem, configResponse)
+	service.GachaConfigVersionRepository.RestoreDraft(ctx, restored, rarities, characters, banners)
+
+	return web.ToGachaConfigVersionResponse(version)
+}
+
+func (service *GachaConfigServiceImpl) FindAllVersions(ctx context.Context, gachaSystemId int) []web.GachaConfigVersionResponse {
+	service.findGachaSystem(ctx, gachaSystemId)
+
+	versions := service.GachaConfigVersionRepository.FindAllByGachaSystemId(ctx, gachaSystemId)
+
+	return web.ToGachaConfigVersionsResponse(versions)
+}
+
+func (service *GachaConfigServiceImpl) FindVersion(ctx context.Context, gachaSystemId int, number int) *web.GachaConfigVersionResponse {
+	service.findGachaSystem(ctx, gachaSystemId)
+
+	version := service.findVersion(ctx, gachaSystemId, number)
+
+	versionResponse := web.ToGachaConfigVersionResponse(version)
+	versionResponse.Config = toVersionConfig(version)
+
+	return versionResponse
+}
+
+// Diff compares config version from with config version to, or with the draft when to is 0.
+func (service *GachaConfigServiceImpl) Diff(ctx context.Context, gachaSystemId int, from int, to int) *web.GachaConfigDiffResponse {
+	if from < 1 || to < 0 {
+		panic(exception.NewBadRequestError("Versions to compare must be positive, to may be 0 for the draft"))
+	}
+
+	gachaSystem := service.findGachaSystem(ctx, gachaSystemId)
+
+	fromConfig := toVersionConfig(service.findVersion(ctx, gachaSystemId, from))
+
+	var toConfig *web.GachaConfigResponse
+	if to == 0 {
+		toConfig = service.draftConfig(ctx, gachaSystem)
+	} else {
+		toConfig = toVersionConfig(service.findVersion(ctx, gachaSystemId, to))
+	}
+
+	return &web.GachaConfigDiffResponse{
+		From:       from,
+		To:         to,
+		Settings:   diffGachaConfigFields(fromConfig.GachaSystem, toConfig.GachaSystem),
+		Rarities:   diffGachaConfigItems(fromConfig.Rarities, toConfig.Rarities),
+		Characters: diffGachaConfigItems(fromConfig.Characters, toConfig.Characters),
+		Banners:    diffGachaConfigItems(fromConfig.Banners, toConfig.Banners),
+	}
+}
+
+func (service *GachaConfigServiceImpl) findGachaSystem(ctx context.Context, gachaSystemId int) *domain.GachaSystem {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	return gachaSystem
+}
+
+func (service *GachaConfigServiceImpl) findVersion(ctx context.Context, gachaSystemId int, number int) *domain.GachaConfigVersion {
+	version := service.GachaConfigVersionRepository.FindByNumberAndGachaSystemId(ctx, number, gachaSystemId)
+	if version == nil {
+		panic(exception.NewNotFoundError(helper.ErrConfigVersionNotFound))
+	}
+
+	return version
+}
+
+// draftConfig takes a snapshot of the draft of a gacha system, the configuration as it is edited
+// right now. The snapshot is laid out the same way every time, so its hash only changes with its
+// content.
+func (service *GachaConfigServiceImpl) draftConfig(ctx context.Context, gachaSystem *domain.GachaSystem) *web.GachaConfigResponse {
+	return findDraftConfig(ctx, service.RarityRepository, service.CharacterRepository, service.BannerRepository, gachaSystem)
+}
+
+func findDraftConfig(
+	ctx context.Context,
+	rarityRepository repository.RarityRepository,
+	characterRepository repository.CharacterRepository,
+	bannerRepository repository.BannerRepository,
+	gachaSystem *domain.GachaSystem,
+) *web.GachaConfigResponse {
+	rarities := rarityRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id)
+	sort.Slice(rarities, func(i, j int) bool { return rarities[i].Id < rarities[j].Id })
+
+	characters := characterRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id)
+	sort.Slice(characters, func(i, j int) bool { return characters[i].Id < characters[j].Id })
+
+	banners := bannerRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id)
+
+	configResponse := web.ToGachaConfigResponse(gachaSystem, rarities, characters, banners)
+	configResponse.SchemaVersion = GachaConfigSchemaVersion
+
+	return configResponse
+}
+
+// toVersionConfig reads the snapshot of a config version back and marks it with the version.
+func toVersionConfig(version *domain.GachaConfigVersion) *web.GachaConfigResponse {
+	configResponse := &web.GachaConfigResponse{}
+	err := json.Unmarshal(version.Snapshot, configResponse)
+	helper.PanicIfError(err, "Failed to decode gacha config version")
+
+	configResponse.Version = version.ContentHash
+	configResponse.VersionId = version.Id
+	configResponse.VersionNumber = version.Number
+
+	return configResponse
+}
+
+// overlayLiveSettings takes the settings that are not part of a config version from the gacha
+// system as it is now.
+func overlayLiveSettings(configResponse *web.GachaConfigResponse, gachaSystem *domain.GachaSystem) {
+	configResponse.GachaSystem.Name = gachaSystem.Name
+	configResponse.GachaSystem.EndpointId = gachaSystem.EndpointId
+	configResponse.GachaSystem.Private = gachaSystem.Private
+	configResponse.GachaSystem.EndpointRateLimit = gachaSystem.EndpointRateLimit
+	configResponse.GachaSystem.ApiKeyRateLimit = gachaSystem.ApiKeyRateLimit
+	configResponse.GachaSystem.PlayerRateLimit = gachaSystem.PlayerRateLimit
+}
+
+// validateDraftConfig lists what keeps a draft from being pulled from: rarity chances that do not
+// add up to 100, rarities without characters, a box that cannot be drawn fairly and references to
+// rarities or characters that are not there.
+func validateDraftConfig(configResponse *web.GachaConfigResponse) []string {
+	var problems []string
+
+	if len(configResponse.Rarities) == 0 {
+		problems = append(problems, "there are no rarities")
+	}
+
+	rarityIds := make(map[int]bool)
+	charactersPerRarity := make(map[int]int)
+	characterIds := make(map[int]bool)
+	for _, character := range configResponse.Characters {
+		charactersPerRarity[character.RarityId]++
+		characterIds[character.Id] = true
+	}
+
+	var chanceSum float64
+	for _, rarity := range configResponse.Rarities {
+		rarityIds[rarity.Id] = true
+		chanceSum += float64(rarity.Chance)
+		if charactersPerRarity[rarity.Id] == 0 {
+			problems = append(problems, fmt.Sprintf("rarity %s has no characters", rarity.Name))
+		}
+	}
+
+	// A box draws by the quantities in the box, so only standard pulls care about the chances
+	gachaSystem := configResponse.GachaSystem
+	if gachaSystem.Mode != domain.GachaSystemModeBox && len(configResponse.Rarities) > 0 && math.Abs(chanceSum-100) > 0.005 {
+		problems = append(problems, fmt.Sprintf("rarity chances must sum to 100, they sum to %.2f", chanceSum))
+	}
+
+	if gachaSystem.Mode == domain.GachaSystemModeBox {
+		boxQuantity := 0
+		for _, character := range configResponse.Characters {
+			boxQuantity += character.BoxQuantity
+		}
+		if boxQuantity == 0 {
+			problems = append(problems, "the box holds no characters")
+		}
+		if gachaSystem.ProvablyFair {
+			problems = append(problems, "provably fair mode is not available for box gacha systems")
+		}
+	}
+
+	if gachaSystem.GuaranteedRarityId != 0 && !rarityIds[gachaSystem.GuaranteedRarityId] {
+		problems = append(problems, fmt.Sprintf("guaranteed rarity %d does not exist", gachaSystem.GuaranteedRarityId))
+	}
+
+	for _, banner := range configResponse.Banners {
+		for _, characterId := range banner.FeaturedCharacterIds {
+			if !characterIds[characterId] {
+				problems = append(problems, fmt.Sprintf("banner %s features character %d that does not exist", banner.Name, characterId))
+			}
+		}
+	}
+
+	return problems
+}
+
+// toDraft turns a config snapshot back into the gacha system settings and items it was taken from.
+// Name, privacy and rate limits are not part of the draft and stay as they are.
+func toDraft(gachaSystem *domain.GachaSystem, configResponse *web.GachaConfigResponse) (*domain.GachaSystem, []domain.Rarity, []domain.Character, []domain.Banner) {
+	restored := *gachaSystem
+	restored.GuaranteedRarityId = configResponse.GachaSystem.GuaranteedRarityId
+	restored.GuaranteePullCount = configResponse.GachaSystem.GuaranteePullCount
+	restored.ProvablyFair = configResponse.GachaSystem.ProvablyFair
+	restored.MultiPullDiscount = configResponse.GachaSystem.MultiPullDiscount
+	restored.MultiPullDiscountCount = configResponse.GachaSystem.MultiPullDiscountCount
+	restored.Mode = configResponse.GachaSystem.Mode
+
+	var rarities []domain.Rarity
+	for _, rarity := range configResponse.Rarities {
+		rarities = append(rarities, domain.Rarity{
+			Id:                  rarity.Id,
+			Name:                rarity.Name,
+			Chance:              rarity.Chance,
+			PityThreshold:       rarity.PityThreshold,
+			SoftPityStart:       rarity.SoftPityStart,
+			SoftPityStep:        rarity.SoftPityStep,
+			FeaturedChance:      rarity.FeaturedChance,
+			GachaSystemId:       gachaSystem.Id,
+			DuplicateConversion: rarity.DuplicateConversion,
+			DuplicateCurrencyId: rarity.DuplicateCurrencyId,
+			DuplicateShards:     rarity.DuplicateShards,
+			MaxConstellation:    rarity.MaxConstellation,
+		})
+	}
+
+	var characters []domain.Character
+	for _, character := range configResponse.Characters {
+		characters = append(characters, domain.Character{
+			Id:            character.Id,
+			Name:          character.Name,
+			ImageUrl:      character.ImageUrl,
+			Featured:      character.Featured,
+			Weight:        character.Weight,
+			RarityId:      character.RarityId,
+			GachaSystemId: gachaSystem.Id,
+			BoxQuantity:   character.BoxQuantity,
+			BoxKey:        character.BoxKey,
+		})
+	}
+
+	var banners []domain.Banner
+	for _, banner := range configResponse.Banners {
+		banners = append(banners, domain.Banner{
+			Id:                   banner.Id,
+			GachaSystemId:        gachaSystem.Id,
+			Name:                 banner.Name,
+			StartsAt:             banner.StartsAt,
+			EndsAt:               banner.EndsAt,
+			Timezone:             banner.Timezone,
+			FeaturedCharacterIds: banner.FeaturedCharacterIds,
+			SparkThreshold:       banner.SparkThreshold,
+			SparkCurrencyId:      banner.SparkCurrencyId,
+			SparkConversion:      banner.SparkConversion,
+		})
+	}
+
+	return &restored, rarities, characters, banners
+}
+
+func hashGachaConfig(configResponse *web.GachaConfigResponse) string {
+	unversioned := *configResponse
+	unversioned.Version = ""
+
+	content, err := json.Marshal(unversioned)
+	helper.PanicIfError(err, "Failed to encode gacha config")
+
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
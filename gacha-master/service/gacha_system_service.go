@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha1"
 	"fmt"
+	"gacha-master/client"
 	"gacha-master/exception"
 	"gacha-master/helper"
 	"gacha-master/model/domain"
@@ -21,12 +22,14 @@ type GachaSystemService interface {
 	Delete(ctx context.Context, id int)
 	FindAllByUserId(ctx context.Context) []web.GachaSystemResponse
 	FindByNameAndUserId(ctx context.Context, name string, userId int) *web.GachaSystemDetailResponse
+	UpdateSettings(ctx context.Context, request *web.GachaSystemSettingsUpdateRequest) *web.GachaSystemSettingsResponse
 }
 
 type GachaSystemServiceImpl struct {
 	GachaSystemRepository repository.GachaSystemRepository
 	RarityRepository      repository.RarityRepository
 	CharacterRepository   repository.CharacterRepository
+	GachaPullClient       client.GachaPullClient
 	Validate              *validator.Validate
 }
 
@@ -34,12 +37,14 @@ func NewGachaSystemService(
 	gachaSystemRepository repository.GachaSystemRepository,
 	rarityRepository repository.RarityRepository,
 	characterRepository repository.CharacterRepository,
+	gachaPullClient client.GachaPullClient,
 	validate *validator.Validate,
 ) GachaSystemService {
 	return &GachaSystemServiceImpl{
 		GachaSystemRepository: gachaSystemRepository,
 		RarityRepository:      rarityRepository,
 		CharacterRepository:   characterRepository,
+		GachaPullClient:       gachaPullClient,
 		Validate:              validate,
 	}
 }
@@ -88,6 +93,7 @@ func (service *GachaSystemServiceImpl) FindById(ctx context.Context, id int) *we
 		Id:       gachaSystem.Id,
 		Name:     gachaSystem.Name,
 		Endpoint: endpoint,
+		Settings: web.ToGachaSystemSettingsResponse(gachaSystem),
 	}
 }
 
@@ -100,7 +106,7 @@ func (service *GachaSystemServiceImpl) Delete(ctx context.Context, id int) {
 	}
 
 	service.GachaSystemRepository.Delete(ctx, id)
-
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
 }
 
 func (service *GachaSystemServiceImpl) FindAllByUserId(ctx context.Context) []web.GachaSystemResponse {
@@ -128,6 +134,39 @@ func (service *GachaSystemServiceImpl) FindByNameAndUserId(ctx context.Context,
 	}
 }
 
+func (service *GachaSystemServiceImpl) UpdateSettings(ctx context.Context, request *web.GachaSystemSettingsUpdateRequest) *web.GachaSystemSettingsResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	if request.GuaranteedRarityId != 0 {
+		rarity := service.RarityRepository.FindByIdAndGachaSystemId(ctx, request.GuaranteedRarityId, gachaSystem.Id)
+		if rarity == nil {
+			panic(exception.NewNotFoundError(helper.ErrRarityNotFound))
+		}
+	}
+
+	request.UpdateGachaSystem(gachaSystem)
+
+	// Box draws depend on what is left in the box of a player, which the fairness proofs do not cover
+	if gachaSystem.Mode == domain.GachaSystemModeBox && gachaSystem.ProvablyFair {
+		panic(exception.NewBadRequestError("Provably fair mode is not available for box gacha systems"))
+	}
+
+	service.GachaSystemRepository.UpdateSettings(ctx, gachaSystem)
+	service.GachaPullClient.InvalidateConfig(ctx, gachaSystem.EndpointId)
+
+	return web.ToGachaSystemSettingsResponse(gachaSystem)
+}
+
 func shiftString(input string, shiftCount int) string {
 	n := len(input)
 	shiftCount = shiftCount % n
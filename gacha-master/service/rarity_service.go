@@ -21,17 +21,20 @@ type RarityService interface {
 type RarityServiceImpl struct {
 	RarityRepository      repository.RarityRepository
 	GachaSystemRepository repository.GachaSystemRepository
+	CurrencyRepository    repository.CurrencyRepository
 	Validate              *validator.Validate
 }
 
 func NewRarityService(
 	rarityRepository repository.RarityRepository,
 	gachaSystemRepository repository.GachaSystemRepository,
+	currencyRepository repository.CurrencyRepository,
 	validate *validator.Validate,
 ) RarityService {
 	return &RarityServiceImpl{
 		RarityRepository:      rarityRepository,
 		GachaSystemRepository: gachaSystemRepository,
+		CurrencyRepository:    currencyRepository,
 		Validate:              validate,
 	}
 }
@@ -54,12 +57,28 @@ func (service *RarityServiceImpl) Create(ctx context.Context, request *web.Rarit
 		panic(exception.NewConflictError("Rarity with the same name already exists"))
 	}
 
+	featuredChance := float32(web.DefaultFeaturedChance)
+	if request.FeaturedChance != nil {
+		featuredChance = *request.FeaturedChance
+	}
+
 	rarity := domain.Rarity{
-		Name:          request.Name,
-		Chance:        request.Chance,
-		GachaSystemId: request.GachaSystemId,
+		Name:           request.Name,
+		Chance:         request.Chance,
+		PityThreshold:  request.PityThreshold,
+		SoftPityStart:  request.SoftPityStart,
+		SoftPityStep:   request.SoftPityStep,
+		FeaturedChance: featuredChance,
+		GachaSystemId:  request.GachaSystemId,
+
+		DuplicateConversion: request.DuplicateConversionOrNone(),
+		DuplicateCurrencyId: request.DuplicateCurrencyId,
+		DuplicateShards:     request.DuplicateShards,
+		MaxConstellation:    request.MaxConstellation,
 	}
 
+	service.validateDuplicateConversion(ctx, &rarity)
+
 	service.RarityRepository.Save(ctx, &rarity)
 
 	return web.ToRarityResponse(&rarity)
@@ -117,6 +136,8 @@ func (service *RarityServiceImpl) Update(ctx context.Context, request *web.Rarit
 
 	request.UpdateRarity(rarity)
 
+	service.validateDuplicateConversion(ctx, rarity)
+
 	existingRarity := service.RarityRepository.FindByNameAndGachaSystemId(ctx, rarity.Name, rarity.GachaSystemId)
 	if existingRarity != nil && existingRarity.Id != rarity.Id {
 		panic(exception.NewConflictError("Rarity with the same name already exists"))
@@ -142,3 +163,32 @@ func (service *RarityServiceImpl) Delete(ctx context.Context, id int, gachaSyste
 
 	service.RarityRepository.Delete(ctx, id, gachaSystemId)
 }
+
+// validateDuplicateConversion checks that a rarity converting duplicates into shards names a
+// currency of its gacha system and an amount to credit.
+func (service *RarityServiceImpl) validateDuplicateConversion(ctx context.Context, rarity *domain.Rarity) {
+	switch rarity.DuplicateConversion {
+	case domain.DuplicateConversionShards:
+		if rarity.DuplicateCurrencyId == nil || rarity.DuplicateShards == 0 {
+			panic(exception.NewBadRequestError("Shards conversion requires a duplicate currency and duplicate shards"))
+		}
+	case domain.DuplicateConversionConstellation:
+		if rarity.MaxConstellation == 0 {
+			panic(exception.NewBadRequestError("Constellation conversion requires a max constellation"))
+		}
+		if rarity.DuplicateCurrencyId != nil && rarity.DuplicateShards == 0 {
+			panic(exception.NewBadRequestError("Duplicate shards are required when a duplicate currency is set"))
+		}
+	default:
+		rarity.DuplicateCurrencyId = nil
+		rarity.DuplicateShards = 0
+		rarity.MaxConstellation = 0
+	}
+
+	if rarity.DuplicateCurrencyId != nil {
+		currency := service.CurrencyRepository.FindByIdAndGachaSystemId(ctx, *rarity.DuplicateCurrencyId, rarity.GachaSystemId)
+		if currency == nil {
+			panic(exception.NewNotFoundError(helper.ErrCurrencyNotFound))
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gacha-master/client"
+	"gacha-master/exception"
+	"gacha-master/helper"
+	"gacha-master/model/web"
+	"gacha-master/repository"
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+type SimulationService interface {
+	Simulate(ctx context.Context, request *web.SimulationRequest) json.RawMessage
+}
+
+type SimulationServiceImpl struct {
+	GachaSystemRepository repository.GachaSystemRepository
+	RarityRepository      repository.RarityRepository
+	CharacterRepository   repository.CharacterRepository
+	BannerRepository      repository.BannerRepository
+	GachaPullClient       client.GachaPullClient
+	Validate              *validator.Validate
+}
+
+func NewSimulationService(
+	gachaSystemRepository repository.GachaSystemRepository,
+	rarityRepository repository.RarityRepository,
+	characterRepository repository.CharacterRepository,
+	bannerRepository repository.BannerRepository,
+	gachaPullClient client.GachaPullClient,
+	validate *validator.Validate,
+) SimulationService {
+	return &SimulationServiceImpl{
+		GachaSystemRepository: gachaSystemRepository,
+		RarityRepository:      rarityRepository,
+		CharacterRepository:   characterRepository,
+		BannerRepository:      bannerRepository,
+		GachaPullClient:       gachaPullClient,
+		Validate:              validate,
+	}
+}
+
+// Simulate runs the simulation in gacha-pull, so it uses the very selection algorithm players
+// pull with, and returns its result as is. The draft is simulated rather than the published
+// version, so changes can be tried out before they are published.
+func (service *SimulationServiceImpl) Simulate(ctx context.Context, request *web.SimulationRequest) json.RawMessage {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	if request.BannerId != 0 {
+		banner := service.BannerRepository.FindByIdAndGachaSystemId(ctx, request.BannerId, request.GachaSystemId)
+		if banner == nil {
+			panic(exception.NewNotFoundError(helper.ErrBannerNotFound))
+		}
+	}
+
+	configResponse := findDraftConfig(ctx, service.RarityRepository, service.CharacterRepository, service.BannerRepository, gachaSystem)
+	if problems := validateDraftConfig(configResponse); len(problems) > 0 {
+		panic(exception.NewBadRequestError("Draft cannot be simulated: " + strings.Join(problems, "; ")))
+	}
+
+	return service.GachaPullClient.Simulate(ctx, gachaSystem.EndpointId, &web.GachaPullSimulationRequest{
+		SimulationRequest: request,
+		Config:            configResponse,
+	})
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"gacha-master/client"
+	"gacha-master/exception"
+	"gacha-master/helper"
+	"gacha-master/model/domain"
+	"gacha-master/model/web"
+	"gacha-master/repository"
+	"github.com/go-playground/validator/v10"
+)
+
+type WalletService interface {
+	Grant(ctx context.Context, request *web.WalletAdjustRequest) *web.WalletLedgerEntryResponse
+	Revoke(ctx context.Context, request *web.WalletAdjustRequest) *web.WalletLedgerEntryResponse
+	FindByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) *web.WalletResponse
+}
+
+// WalletServiceImpl manages the wallets of players from the dashboard. Wallets are kept by
+// gacha-pull with the rest of the player data, so they are read and changed through its internal
+// API.
+type WalletServiceImpl struct {
+	CurrencyRepository    repository.CurrencyRepository
+	GachaSystemRepository repository.GachaSystemRepository
+	GachaPullClient       client.GachaPullClient
+	Validate              *validator.Validate
+}
+
+func NewWalletService(
+	currencyRepository repository.CurrencyRepository,
+	gachaSystemRepository repository.GachaSystemRepository,
+	gachaPullClient client.GachaPullClient,
+	validate *validator.Validate,
+) WalletService {
+	return &WalletServiceImpl{
+		CurrencyRepository:    currencyRepository,
+		GachaSystemRepository: gachaSystemRepository,
+		GachaPullClient:       gachaPullClient,
+		Validate:              validate,
+	}
+}
+
+func (service *WalletServiceImpl) Grant(ctx context.Context, request *web.WalletAdjustRequest) *web.WalletLedgerEntryResponse {
+	return service.adjust(ctx, request, request.Amount, web.WalletReasonGrant)
+}
+
+func (service *WalletServiceImpl) Revoke(ctx context.Context, request *web.WalletAdjustRequest) *web.WalletLedgerEntryResponse {
+	return service.adjust(ctx, request, -request.Amount, web.WalletReasonRevoke)
+}
+
+func (service *WalletServiceImpl) adjust(ctx context.Context, request *web.WalletAdjustRequest, amount int64, reason string) *web.WalletLedgerEntryResponse {
+	err := service.Validate.Struct(request)
+	if err != nil {
+		panic(exception.NewBadRequestError(err.Error()))
+	}
+
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, request.GachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	currency := service.CurrencyRepository.FindByIdAndGachaSystemId(ctx, request.CurrencyId, request.GachaSystemId)
+	if currency == nil {
+		panic(exception.NewNotFoundError(helper.ErrCurrencyNotFound))
+	}
+
+	entry := domain.WalletLedgerEntry{
+		GachaSystemId: request.GachaSystemId,
+		PlayerId:      request.PlayerId,
+		CurrencyId:    request.CurrencyId,
+		Amount:        amount,
+		Reason:        reason,
+		Note:          request.Note,
+	}
+
+	service.GachaPullClient.ApplyWalletEntry(ctx, &entry)
+
+	return web.ToWalletLedgerEntryResponse(&entry, currency.Name)
+}
+
+func (service *WalletServiceImpl) FindByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) *web.WalletResponse {
+	userId := helper.ExtractUserID(ctx)
+
+	gachaSystem := service.GachaSystemRepository.FindByIdAndUserId(ctx, gachaSystemId, userId)
+	if gachaSystem == nil {
+		panic(exception.NewNotFoundError(helper.ErrGachaSystemNotFound))
+	}
+
+	currencies := service.CurrencyRepository.FindAllByGachaSystemId(ctx, gachaSystemId)
+	wallets, entries := service.GachaPullClient.FindWallet(ctx, gachaSystemId, playerId)
+
+	return web.ToWalletResponse(playerId, currencies, wallets, entries)
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"gacha-pull/poolcache"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"time"
+)
+
+const ApiKeyHeader = "X-API-Key"
+
+// NewApiKeyMiddleware checks the API key of requests to a gacha system endpoint. Private gacha
+// systems can only be reached with an active key issued for them, public ones accept requests
+// with or without a key. The keys come with the cached configuration of the gacha system, so a
+// revoked key stops working once gacha-master invalidates it, or the configuration is revalidated.
+// A valid key is stored in the request context.
+func NewApiKeyMiddleware(gachaPoolCache *poolcache.Cache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			gachaSystem := helper.ExtractGachaSystem(request.Context())
+			requestKey := request.Header.Get(ApiKeyHeader)
+
+			if !gachaSystem.Private && requestKey == "" {
+				next.ServeHTTP(writer, request)
+				return
+			}
+
+			if requestKey == "" {
+				panic(exception.NewUnauthorizedError("API key is required for this gacha system"))
+			}
+
+			var apiKey *domain.ApiKey
+			if entry := gachaPoolCache.Find(request.Context(), chi.URLParam(request, "endpointId")); entry != nil && entry.GachaSystem.Id == gachaSystem.Id {
+				apiKey = entry.FindApiKey(helper.HashApiKey(requestKey), time.Now())
+			}
+			if apiKey == nil {
+				panic(exception.NewUnauthorizedError("Invalid, expired or revoked API key"))
+			}
+
+			next.ServeHTTP(writer, request.WithContext(helper.WithApiKey(request.Context(), apiKey)))
+		})
+	}
+}
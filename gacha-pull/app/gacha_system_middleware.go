@@ -0,0 +1,29 @@
+package app
+
+import (
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/poolcache"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+// NewGachaSystemMiddleware loads the gacha system of the endpoint a request is made to, so the
+// access checks after it work on the same gacha system. Unknown endpoints are remembered by the
+// cache for a while, so requests to them are turned away before rate limiting without calling
+// gacha-master each time.
+func NewGachaSystemMiddleware(gachaPoolCache *poolcache.Cache) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			endpointId := chi.URLParam(request, "endpointId")
+
+			entry := gachaPoolCache.Find(request.Context(), endpointId)
+			if entry == nil {
+				panic(exception.NewNotFoundError("Gacha system not found"))
+			}
+
+			gachaSystem := entry.GachaSystem
+			next.ServeHTTP(writer, request.WithContext(helper.WithGachaSystem(request.Context(), &gachaSystem)))
+		})
+	}
+}
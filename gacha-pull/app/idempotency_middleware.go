@@ -0,0 +1,139 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"gacha-pull/repository"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	MaxIdempotencyKeyLength   = 255
+	IdempotencyKeyRetention   = 24 * time.Hour
+	IdempotencySweepInterval  = time.Hour
+	anonymousIdempotencyScope = "anonymous"
+)
+
+// idempotentResponseWriter passes a response through while keeping a copy of it.
+type idempotentResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (writer *idempotentResponseWriter) WriteHeader(statusCode int) {
+	writer.statusCode = statusCode
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *idempotentResponseWriter) Write(data []byte) (int, error) {
+	writer.body.Write(data)
+	return writer.ResponseWriter.Write(data)
+}
+
+// NewIdempotencyMiddleware makes requests sent with an idempotency key safe to retry. The first
+// request with a key is handled and its response kept for the retention window, a repeat of it
+// gets the kept response back instead of being handled again. Keys belong to the player, or else
+// the API key, that sent them, and a key reused for a request with other parameters is rejected.
+// A request that fails gives its key up, so it can be retried with the same key.
+func NewIdempotencyMiddleware(idempotencyKeyRepository repository.IdempotencyKeyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			key := request.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(writer, request)
+				return
+			}
+			if len(key) > MaxIdempotencyKeyLength {
+				panic(exception.NewBadRequestError(fmt.Sprintf("Idempotency key must be at most %d characters", MaxIdempotencyKeyLength)))
+			}
+
+			idempotencyKey := &domain.IdempotencyKey{
+				GachaSystemId: helper.ExtractGachaSystem(request.Context()).Id,
+				Scope:         idempotencyScope(request.Context()),
+				Key:           key,
+				RequestHash:   hashIdempotentRequest(request),
+				ExpiresAt:     time.Now().Add(IdempotencyKeyRetention),
+			}
+
+			storedKey := idempotencyKeyRepository.Claim(request.Context(), idempotencyKey)
+			if storedKey != nil {
+				replayIdempotentResponse(writer, idempotencyKey, storedKey)
+				return
+			}
+
+			defer func() {
+				if err := recover(); err != nil {
+					idempotencyKeyRepository.Release(context.WithoutCancel(request.Context()), idempotencyKey)
+					panic(err)
+				}
+			}()
+
+			responseWriter := &idempotentResponseWriter{ResponseWriter: writer, statusCode: http.StatusOK}
+			next.ServeHTTP(responseWriter, request)
+
+			idempotencyKey.StatusCode = responseWriter.statusCode
+			idempotencyKey.ResponseBody = responseWriter.body.Bytes()
+			idempotencyKeyRepository.SaveResponse(context.WithoutCancel(request.Context()), idempotencyKey)
+		})
+	}
+}
+
+// SweepIdempotencyKeys deletes the idempotency keys whose retention window is over.
+func SweepIdempotencyKeys(idempotencyKeyRepository repository.IdempotencyKeyRepository) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		if deleted := idempotencyKeyRepository.DeleteExpired(ctx); deleted > 0 {
+			log.Printf("Deleted %d expired idempotency key(s)", deleted)
+		}
+	}
+}
+
+func replayIdempotentResponse(writer http.ResponseWriter, idempotencyKey *domain.IdempotencyKey, storedKey *domain.IdempotencyKey) {
+	if storedKey.RequestHash != idempotencyKey.RequestHash {
+		panic(exception.NewBadRequestError("Idempotency key was already used for a request with other parameters"))
+	}
+	if storedKey.StatusCode == 0 {
+		panic(exception.NewConflictError("A request with this idempotency key is still being handled, please retry later"))
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	writer.Header().Set(IdempotentReplayedHeader, "true")
+	writer.WriteHeader(storedKey.StatusCode)
+	writer.Write(storedKey.ResponseBody)
+}
+
+func idempotencyScope(ctx context.Context) string {
+	if player := helper.ExtractPlayer(ctx); player != nil {
+		return "player:" + player.Id
+	}
+	if apiKey := helper.ExtractApiKey(ctx); apiKey != nil {
+		return fmt.Sprintf("api-key:%d", apiKey.Id)
+	}
+	return anonymousIdempotencyScope
+}
+
+// hashIdempotentRequest fingerprints the parameters of a request: its method, path, query and
+// body. The body is read and put back for the handler.
+func hashIdempotentRequest(request *http.Request) string {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		panic(exception.NewBadRequestError("Failed to read request body"))
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n", request.Method, request.URL.Path, request.URL.Query().Encode())
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
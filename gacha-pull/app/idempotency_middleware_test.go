@@ -0,0 +1,144 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// memoryIdempotencyKeys keeps idempotency keys in memory the way the repository keeps them in the
+// database, without expiry.
+type memoryIdempotencyKeys struct {
+	keys map[string]domain.IdempotencyKey
+}
+
+func (repository *memoryIdempotencyKeys) id(idempotencyKey *domain.IdempotencyKey) string {
+	return fmt.Sprintf("%d/%s/%s", idempotencyKey.GachaSystemId, idempotencyKey.Scope, idempotencyKey.Key)
+}
+
+func (repository *memoryIdempotencyKeys) Claim(ctx context.Context, idempotencyKey *domain.IdempotencyKey) *domain.IdempotencyKey {
+	if storedKey, ok := repository.keys[repository.id(idempotencyKey)]; ok {
+		return &storedKey
+	}
+	repository.keys[repository.id(idempotencyKey)] = *idempotencyKey
+	return nil
+}
+
+func (repository *memoryIdempotencyKeys) SaveResponse(ctx context.Context, idempotencyKey *domain.IdempotencyKey) {
+	repository.keys[repository.id(idempotencyKey)] = *idempotencyKey
+}
+
+func (repository *memoryIdempotencyKeys) Release(ctx context.Context, idempotencyKey *domain.IdempotencyKey) {
+	delete(repository.keys, repository.id(idempotencyKey))
+}
+
+func (repository *memoryIdempotencyKeys) DeleteExpired(ctx context.Context) int64 {
+	return 0
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	type step struct {
+		player   string
+		key      string
+		body     string
+		fail     bool
+		status   int
+		handled  bool
+		replayed bool
+	}
+
+	tests := []struct {
+		name    string
+		claimed []string
+		steps   []step
+	}{
+		{"repeat gets the kept response", nil, []step{
+			{key: "a", body: "1", status: http.StatusOK, handled: true},
+			{key: "a", body: "1", status: http.StatusOK, replayed: true},
+		}},
+		{"key reused with other parameters is rejected", nil, []step{
+			{key: "a", body: "1", status: http.StatusOK, handled: true},
+			{key: "a", body: "10", status: http.StatusBadRequest},
+		}},
+		{"keys belong to their player", nil, []step{
+			{player: "one", key: "a", body: "1", status: http.StatusOK, handled: true},
+			{player: "two", key: "a", body: "1", status: http.StatusOK, handled: true},
+			{player: "one", key: "a", body: "1", status: http.StatusOK, replayed: true},
+		}},
+		{"failed request gives its key up", nil, []step{
+			{key: "a", body: "1", fail: true, status: http.StatusBadRequest, handled: true},
+			{key: "a", body: "1", status: http.StatusOK, handled: true},
+		}},
+		{"requests without a key are always handled", nil, []step{
+			{body: "1", status: http.StatusOK, handled: true},
+			{body: "1", status: http.StatusOK, handled: true},
+		}},
+		{"key still being handled conflicts", []string{"a"}, []step{
+			{key: "a", body: "1", status: http.StatusConflict},
+		}},
+		{"key too long", nil, []step{
+			{key: strings.Repeat("a", MaxIdempotencyKeyLength+1), body: "1", status: http.StatusBadRequest},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &memoryIdempotencyKeys{keys: make(map[string]domain.IdempotencyKey)}
+			// Keys claimed by a request with body 1 that is still being handled
+			for _, key := range test.claimed {
+				requestHash := hashIdempotentRequest(httptest.NewRequest(http.MethodPost, "/?count=10", strings.NewReader("1")))
+				repository.Claim(context.Background(), &domain.IdempotencyKey{
+					GachaSystemId: 1,
+					Scope:         anonymousIdempotencyScope,
+					Key:           key,
+					RequestHash:   requestHash,
+				})
+			}
+
+			handled := 0
+			var responses []string
+			for i, step := range test.steps {
+				handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+					handled++
+					if step.fail {
+						panic(exception.NewBadRequestError("Pull failed"))
+					}
+					fmt.Fprintf(writer, `{"pull":%d}`, handled)
+				})
+
+				request := httptest.NewRequest(http.MethodPost, "/?count=10", strings.NewReader(step.body))
+				if step.key != "" {
+					request.Header.Set(IdempotencyKeyHeader, step.key)
+				}
+				ctx := helper.WithGachaSystem(request.Context(), &domain.GachaSystem{Id: 1})
+				if step.player != "" {
+					ctx = helper.WithPlayer(ctx, &domain.User{Id: step.player})
+				}
+
+				recorder := httptest.NewRecorder()
+				handledBefore := handled
+				RecoverMiddleware(NewIdempotencyMiddleware(repository)(handler)).ServeHTTP(recorder, request.WithContext(ctx))
+
+				if recorder.Code != step.status {
+					t.Errorf("step %d: responded %d, expected %d", i, recorder.Code, step.status)
+				}
+				if wasHandled := handled > handledBefore; wasHandled != step.handled {
+					t.Errorf("step %d: handled %v, expected %v", i, wasHandled, step.handled)
+				}
+				if replayed := recorder.Header().Get(IdempotentReplayedHeader) == "true"; replayed != step.replayed {
+					t.Errorf("step %d: replayed %v, expected %v", i, replayed, step.replayed)
+				}
+				if step.replayed && recorder.Body.String() != responses[0] {
+					t.Errorf("step %d: replayed %s, expected the first response %s", i, recorder.Body.String(), responses[0])
+				}
+				responses = append(responses, recorder.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"crypto/subtle"
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/go-chi/jwtauth/v5"
+	"net/http"
+	"strconv"
+)
+
+const (
+	PlayerIdHeader      = "X-Player-Id"
+	PlayerNameHeader    = "X-Player-Name"
+	GameServerKeyHeader = "X-Game-Server-Key"
+)
+
+// PlayerMiddleware resolves the player a request is made for and stores it in the request
+// context. A trusted game server names the player with the player id header next to the shared
+// game server key, a game client sends a player token signed with the player token secret.
+// Requests with neither stay anonymous. A bearer token that cannot be verified as a player token,
+// because it expired, was tampered with or is not a player token at all, is rejected rather than
+// pulling anonymously without pity or history.
+func PlayerMiddleware(gameServerKey string, playerTokenAuth *jwtauth.JWTAuth) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			player := playerFromGameServer(request, gameServerKey)
+			if player == nil {
+				player = playerFromToken(request, playerTokenAuth)
+			}
+
+			if player != nil {
+				request = request.WithContext(helper.WithPlayer(request.Context(), player))
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
+
+func playerFromGameServer(request *http.Request, gameServerKey string) *domain.User {
+	playerId := request.Header.Get(PlayerIdHeader)
+	if playerId == "" {
+		return nil
+	}
+
+	requestKey := request.Header.Get(GameServerKeyHeader)
+	if gameServerKey == "" || subtle.ConstantTimeCompare([]byte(requestKey), []byte(gameServerKey)) != 1 {
+		panic(exception.NewUnauthorizedError("Player id must be sent by a trusted game server"))
+	}
+
+	return &domain.User{
+		Id:         playerId,
+		Name:       request.Header.Get(PlayerNameHeader),
+		GameServer: true,
+	}
+}
+
+func playerFromToken(request *http.Request, playerTokenAuth *jwtauth.JWTAuth) *domain.User {
+	if jwtauth.TokenFromHeader(request) == "" {
+		return nil
+	}
+	if playerTokenAuth == nil {
+		panic(exception.NewUnauthorizedError("Player tokens are not accepted by this service"))
+	}
+
+	token, err := jwtauth.VerifyRequest(playerTokenAuth, request, jwtauth.TokenFromHeader)
+	if err != nil {
+		panic(exception.NewUnauthorizedError("Player token is invalid or expired"))
+	}
+
+	claims := token.PrivateClaims()
+
+	var playerId string
+	switch value := claims["playerId"].(type) {
+	case string:
+		playerId = value
+	case float64:
+		playerId = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	if playerId == "" {
+		panic(exception.NewUnauthorizedError("playerId is missing or invalid in player token"))
+	}
+
+	player := &domain.User{Id: playerId}
+	if name, ok := claims["name"].(string); ok {
+		player.Name = name
+	}
+
+	return player
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"errors"
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/go-chi/jwtauth/v5"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// servePlayer runs a request through PlayerMiddleware and returns the player it resolved, or the
+// error it panicked with.
+func servePlayer(playerTokenAuth *jwtauth.JWTAuth, authorization string) (player *domain.User, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = recovered.(error)
+		}
+	}()
+
+	request := httptest.NewRequest(http.MethodPost, "/", nil)
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+
+	handler := PlayerMiddleware("", playerTokenAuth)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		player = helper.ExtractPlayer(request.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	return player, nil
+}
+
+func TestPlayerMiddlewareTokens(t *testing.T) {
+	playerTokenAuth := jwtauth.New("HS256", []byte("player secret"), nil)
+	otherTokenAuth := jwtauth.New("HS256", []byte("dashboard secret"), nil)
+
+	sign := func(tokenAuth *jwtauth.JWTAuth, claims map[string]interface{}) string {
+		_, token, err := tokenAuth.Encode(claims)
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		return "Bearer " + token
+	}
+
+	valid := sign(playerTokenAuth, map[string]interface{}{"playerId": "player", "name": "Player"})
+	expired := sign(playerTokenAuth, map[string]interface{}{"playerId": "player", "exp": time.Now().Add(-time.Hour).Unix()})
+
+	tests := []struct {
+		name            string
+		playerTokenAuth *jwtauth.JWTAuth
+		authorization   string
+		expectedPlayer  string
+		unauthorized    bool
+	}{
+		{"no token is anonymous", playerTokenAuth, "", "", false},
+		{"valid player token", playerTokenAuth, valid, "player", false},
+		{"expired player token", playerTokenAuth, expired, "", true},
+		{"tampered player token", playerTokenAuth, valid + "x", "", true},
+		{"token signed with another secret", playerTokenAuth, sign(otherTokenAuth, map[string]interface{}{"playerId": "player"}), "", true},
+		{"token without player id", playerTokenAuth, sign(playerTokenAuth, map[string]interface{}{"name": "Player"}), "", true},
+		{"token while player tokens are disabled", nil, valid, "", true},
+		{"no token while player tokens are disabled", nil, "", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			player, err := servePlayer(test.playerTokenAuth, test.authorization)
+
+			var unauthorizedError *exception.UnauthorizedError
+			if unauthorized := errors.As(err, &unauthorizedError); unauthorized != test.unauthorized {
+				t.Fatalf("middleware returned error %v, expected unauthorized %v", err, test.unauthorized)
+			}
+
+			playerId := ""
+			if player != nil {
+				playerId = player.Id
+			}
+			if playerId != test.expectedPlayer {
+				t.Errorf("resolved player %q, expected %q", playerId, test.expectedPlayer)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/ratelimit"
+	"net/http"
+)
+
+// NewRateLimitMiddleware applies the rate limits configured for a gacha system: one bucket for
+// the whole endpoint, one per API key and one per player.
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			gachaSystem := helper.ExtractGachaSystem(request.Context())
+
+			limits := []ratelimit.Limit{
+				{Key: fmt.Sprintf("endpoint:%d", gachaSystem.Id), PerMinute: gachaSystem.EndpointRateLimit},
+			}
+			if apiKey := helper.ExtractApiKey(request.Context()); apiKey != nil {
+				limits = append(limits, ratelimit.Limit{Key: fmt.Sprintf("api-key:%d", apiKey.Id), PerMinute: gachaSystem.ApiKeyRateLimit})
+			}
+			if player := helper.ExtractPlayer(request.Context()); player != nil {
+				limits = append(limits, ratelimit.Limit{Key: fmt.Sprintf("player:%d:%s", gachaSystem.Id, player.Id), PerMinute: gachaSystem.PlayerRateLimit})
+			}
+
+			if allowed, retryAfter := limiter.Allow(limits...); !allowed {
+				panic(exception.NewTooManyRequestsError("Rate limit exceeded, please retry later", retryAfter))
+			}
+
+			next.ServeHTTP(writer, request)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package app
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/receipt"
+	"log"
+	"os"
+)
+
+// NewReceiptSigner loads the key pull receipts are signed with. Every instance must sign with the
+// same key, or a game server holding the keys of one instance rejects the receipts of another, so
+// the service refuses to start without a configured key. Only with ENVIRONMENT=dev is a temporary
+// key generated instead, whose receipts no longer verify once the service restarts.
+func NewReceiptSigner() *receipt.Signer {
+	signingKey := os.Getenv("RECEIPT_SIGNING_KEY")
+	if signingKey == "" {
+		if os.Getenv("ENVIRONMENT") != "dev" {
+			panic("RECEIPT_SIGNING_KEY must be set, a temporary key is only used with ENVIRONMENT=dev")
+		}
+
+		log.Printf("RECEIPT_SIGNING_KEY is not set, signing pull receipts with a temporary key")
+		return receipt.GenerateSigner()
+	}
+
+	signer, err := receipt.NewSignerFromSeed(signingKey)
+	helper.PanicIfError(err, "Failed to load receipt signing key")
+
+	return signer
+}
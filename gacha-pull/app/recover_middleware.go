@@ -6,7 +6,9 @@ import (
 	"gacha-pull/exception"
 	"gacha-pull/helper"
 	"gacha-pull/model/web"
+	"math"
 	"net/http"
+	"strconv"
 )
 
 type errorData struct {
@@ -33,6 +35,24 @@ func ErrorHandler(writer http.ResponseWriter, request *http.Request, err interfa
 		if badRequestError(writer, request, actualErr) {
 			return
 		}
+		if unauthorizedError(writer, request, actualErr) {
+			return
+		}
+		if tooManyRequestsError(writer, request, actualErr) {
+			return
+		}
+		if paymentRequiredError(writer, request, actualErr) {
+			return
+		}
+		if bannerNotActiveError(writer, request, actualErr) {
+			return
+		}
+		if conflictError(writer, request, actualErr) {
+			return
+		}
+		if badGatewayError(writer, request, actualErr) {
+			return
+		}
 		internalServerError(writer, request, actualErr)
 	} else {
 		internalServerError(writer, request, errors.New(fmt.Sprintf("%v", err)))
@@ -69,6 +89,62 @@ func badRequestError(writer http.ResponseWriter, request *http.Request, err erro
 	return false
 }
 
+func unauthorizedError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var unauthorizedErr *exception.UnauthorizedError
+	if errors.As(err, &unauthorizedErr) {
+		writeErrorResponse(writer, http.StatusUnauthorized, "UNAUTHORIZED", unauthorizedErr.Error())
+		return true
+	}
+	return false
+}
+
+func tooManyRequestsError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var tooManyRequestsErr *exception.TooManyRequestsError
+	if errors.As(err, &tooManyRequestsErr) {
+		retryAfter := int(math.Ceil(tooManyRequestsErr.RetryAfter.Seconds()))
+		writer.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		writeErrorResponse(writer, http.StatusTooManyRequests, "TOO MANY REQUESTS", tooManyRequestsErr.Error())
+		return true
+	}
+	return false
+}
+
+func paymentRequiredError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var paymentRequiredErr *exception.PaymentRequiredError
+	if errors.As(err, &paymentRequiredErr) {
+		writeErrorResponse(writer, http.StatusPaymentRequired, "PAYMENT REQUIRED", paymentRequiredErr.Error())
+		return true
+	}
+	return false
+}
+
+func bannerNotActiveError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var bannerNotActiveErr *exception.BannerNotActiveError
+	if errors.As(err, &bannerNotActiveErr) {
+		writeErrorResponse(writer, http.StatusForbidden, "BANNER NOT ACTIVE", bannerNotActiveErr.Error())
+		return true
+	}
+	return false
+}
+
+func conflictError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var conflictErr *exception.ConflictError
+	if errors.As(err, &conflictErr) {
+		writeErrorResponse(writer, http.StatusConflict, "CONFLICT", conflictErr.Error())
+		return true
+	}
+	return false
+}
+
+func badGatewayError(writer http.ResponseWriter, request *http.Request, err error) bool {
+	var badGatewayErr *exception.BadGatewayError
+	if errors.As(err, &badGatewayErr) {
+		writeErrorResponse(writer, http.StatusBadGateway, "BAD GATEWAY", badGatewayErr.Error())
+		return true
+	}
+	return false
+}
+
 func internalServerError(writer http.ResponseWriter, request *http.Request, err error) {
 	writeErrorResponse(writer, http.StatusInternalServerError, "INTERNAL SERVER ERROR", err.Error())
 }
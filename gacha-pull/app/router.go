@@ -5,27 +5,110 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
+	"github.com/go-chi/jwtauth/v5"
 	"net/http"
+	"os"
+	"strings"
 )
 
 func NewRouter(
 	characterController controller.CharacterController,
+	fairnessController controller.FairnessController,
+	pullHistoryController controller.PullHistoryController,
+	walletController controller.WalletController,
+	inventoryController controller.InventoryController,
+	ratesController controller.RatesController,
+	simulationController controller.SimulationController,
+	boxController controller.BoxController,
+	sparkController controller.SparkController,
+	receiptController controller.ReceiptController,
+	gachaConfigController controller.GachaConfigController,
+	gachaSystemMiddleware func(http.Handler) http.Handler,
+	apiKeyMiddleware func(http.Handler) http.Handler,
+	rateLimitMiddleware func(http.Handler) http.Handler,
+	idempotencyMiddleware func(http.Handler) http.Handler,
 ) http.Handler {
 	router := chi.NewRouter()
 
 	router.Use(middleware.Logger)
 
+	// Browsers are only let in from the configured origins, server-to-server callers use API keys
+	allowedOrigins := []string{"https://*", "http://*"}
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		allowedOrigins = strings.Split(origins, ",")
+	}
+
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedOrigins:   allowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", PlayerIdHeader, PlayerNameHeader, GameServerKeyHeader, ApiKeyHeader, IdempotencyKeyHeader},
+		ExposedHeaders:   []string{IdempotentReplayedHeader},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
 
 	router.Use(RecoverMiddleware)
 
-	router.Get("/api/v1/gacha/{endpointId}", characterController.Pull)
+	var playerTokenAuth *jwtauth.JWTAuth
+	if playerTokenSecret := os.Getenv("PLAYER_JWT_SECRET_KEY"); playerTokenSecret != "" {
+		playerTokenAuth = jwtauth.New("HS256", []byte(playerTokenSecret), nil)
+	}
+	router.Use(PlayerMiddleware(os.Getenv("GAME_SERVER_KEY"), playerTokenAuth))
+
+	router.Get("/.well-known/jwks.json", receiptController.FindKeys)
+
+	router.Route("/api/v1/gacha/{endpointId}", func(subRouter chi.Router) {
+		subRouter.Use(gachaSystemMiddleware)
+		subRouter.Use(apiKeyMiddleware)
+		subRouter.Use(rateLimitMiddleware)
+
+		subRouter.Get("/", pullWithGet)
+		subRouter.Get("/multi", pullWithGet)
+		subRouter.With(idempotencyMiddleware).Post("/", characterController.Pull)
+		subRouter.With(idempotencyMiddleware).Post("/multi", characterController.MultiPull)
+		subRouter.With(idempotencyMiddleware).Post("/reserve", characterController.Reserve)
+		subRouter.With(idempotencyMiddleware).Post("/reserve/{token}/confirm", characterController.Confirm)
+		subRouter.Post("/reserve/{token}/cancel", characterController.Cancel)
+		subRouter.Get("/history", pullHistoryController.FindAll)
+		subRouter.Get("/wallet", walletController.FindByPlayer)
+		subRouter.Get("/inventory", inventoryController.FindByPlayer)
+		subRouter.Get("/box", boxController.FindByPlayer)
+		subRouter.Post("/box/reset", boxController.Reset)
+		subRouter.Get("/spark", sparkController.FindByPlayer)
+		subRouter.Post("/spark/redeem", sparkController.Redeem)
+		subRouter.Get("/rates", ratesController.FindRates)
+		subRouter.Get("/rates/page", ratesController.RenderRates)
+
+		subRouter.Get("/fair/seed", fairnessController.FindActiveSeed)
+		subRouter.Post("/fair/seed/rotate", fairnessController.RotateSeed)
+		subRouter.Post("/fair/verify", fairnessController.Verify)
+	})
+
+	// Internal routes are called by the other services of the platform, never by players
+	router.Route("/internal/v1/gacha/{endpointId}", func(subRouter chi.Router) {
+		subRouter.Use(InternalApiKeyMiddleware(os.Getenv("INTERNAL_API_KEY")))
+
+		// Simulations run on the draft sent with them, so the gacha system need not be published
+		subRouter.Post("/simulate", simulationController.Simulate)
+		// gacha-master calls this whenever it changes what the cached configuration holds
+		subRouter.Post("/config/invalidate", gachaConfigController.Invalidate)
+	})
+
+	// Player data is kept by gacha system, so it is reached before the gacha system is published
+	router.Route("/internal/v1/gacha-system/{gachaSystemId}", func(subRouter chi.Router) {
+		subRouter.Use(InternalApiKeyMiddleware(os.Getenv("INTERNAL_API_KEY")))
+
+		subRouter.Get("/wallet/{playerId}", walletController.FindLedger)
+		subRouter.Post("/wallet/entries", walletController.ApplyEntry)
+	})
 
 	return router
 }
+
+// pullWithGet answers the former GET pull routes. A GET could be retried or prefetched and roll
+// and charge again without an idempotency key, so pulls are only made with POST.
+func pullWithGet(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Allow", http.MethodPost)
+	writeErrorResponse(writer, http.StatusMethodNotAllowed, "METHOD NOT ALLOWED",
+		"Pulls are made with POST and an "+IdempotencyKeyHeader+" header")
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartSweeper runs sweep in the background every interval. A sweep that panics is logged and the
+// next one runs as planned.
+func StartSweeper(name string, interval time.Duration, sweep func(ctx context.Context)) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			runSweep(name, sweep)
+		}
+	}()
+}
+
+func runSweep(name string, sweep func(ctx context.Context)) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Error while sweeping %s: %v", name, err)
+		}
+	}()
+
+	sweep(context.Background())
+}
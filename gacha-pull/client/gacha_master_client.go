@@ -0,0 +1,137 @@
+// Package client calls the internal endpoints of gacha-master, which owns the configuration of
+// gacha systems.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gacha-pull/model/web"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	InternalApiKeyHeader = "X-Internal-Api-Key"
+	// GachaConfigSchemaVersion is the layout of the config snapshot this client understands
+	GachaConfigSchemaVersion = 1
+)
+
+var (
+	ErrNotModified         = errors.New("gacha config not modified")
+	ErrGachaSystemNotFound = errors.New("gacha system not found")
+	ErrVersionNotFound     = errors.New("gacha config version not found")
+)
+
+type GachaMasterClient interface {
+	FindConfig(ctx context.Context, endpointId string, version string) (*web.GachaConfigResponse, error)
+	FindConfigVersion(ctx context.Context, endpointId string, versionId int) (*web.GachaConfigResponse, error)
+}
+
+type GachaMasterClientImpl struct {
+	BaseUrl        string
+	InternalApiKey string
+	HttpClient     *http.Client
+}
+
+// NewGachaMasterClient calls the gacha-master service at baseUrl, like http://gacha-master:8001,
+// with the internal API key shared by both services.
+func NewGachaMasterClient(baseUrl string, internalApiKey string) GachaMasterClient {
+	return &GachaMasterClientImpl{
+		BaseUrl:        baseUrl,
+		InternalApiKey: internalApiKey,
+		HttpClient:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type gachaMasterResponse struct {
+	Code   int             `json:"code"`
+	Status string          `json:"status"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// FindConfig returns the config snapshot of the gacha system behind an endpoint. When version is
+// the version of the current snapshot, ErrNotModified is returned instead.
+func (client *GachaMasterClientImpl) FindConfig(ctx context.Context, endpointId string, version string) (*web.GachaConfigResponse, error) {
+	if client.BaseUrl == "" || client.InternalApiKey == "" {
+		return nil, errors.New("gacha-master internal API is not configured")
+	}
+
+	path := fmt.Sprintf("/internal/v1/gacha/%s/config", url.PathEscape(endpointId))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, client.BaseUrl+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(InternalApiKeyHeader, client.InternalApiKey)
+	if version != "" {
+		request.Header.Set("If-None-Match", `"`+version+`"`)
+	}
+
+	response, err := client.HttpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, ErrNotModified
+	case http.StatusNotFound:
+		return nil, ErrGachaSystemNotFound
+	default:
+		return nil, fmt.Errorf("gacha-master responded %d", response.StatusCode)
+	}
+
+	return decodeConfig(response)
+}
+
+// FindConfigVersion returns the config snapshot of a published version of the gacha system behind
+// an endpoint, whether it is still active or not.
+func (client *GachaMasterClientImpl) FindConfigVersion(ctx context.Context, endpointId string, versionId int) (*web.GachaConfigResponse, error) {
+	if client.BaseUrl == "" || client.InternalApiKey == "" {
+		return nil, errors.New("gacha-master internal API is not configured")
+	}
+
+	path := fmt.Sprintf("/internal/v1/gacha/%s/config/version/%d", url.PathEscape(endpointId), versionId)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, client.BaseUrl+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set(InternalApiKeyHeader, client.InternalApiKey)
+
+	response, err := client.HttpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, ErrVersionNotFound
+	default:
+		return nil, fmt.Errorf("gacha-master responded %d", response.StatusCode)
+	}
+
+	return decodeConfig(response)
+}
+
+func decodeConfig(response *http.Response) (*web.GachaConfigResponse, error) {
+	var masterResponse gachaMasterResponse
+	if err := json.NewDecoder(response.Body).Decode(&masterResponse); err != nil {
+		return nil, err
+	}
+
+	var configResponse web.GachaConfigResponse
+	if err := json.Unmarshal(masterResponse.Data, &configResponse); err != nil {
+		return nil, err
+	}
+	if configResponse.SchemaVersion != GachaConfigSchemaVersion {
+		return nil, fmt.Errorf("gacha config schema version %d is not supported", configResponse.SchemaVersion)
+	}
+
+	return &configResponse, nil
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type BoxController interface {
+	FindByPlayer(writer http.ResponseWriter, request *http.Request)
+	Reset(writer http.ResponseWriter, request *http.Request)
+}
+
+type BoxControllerImpl struct {
+	BoxService service.BoxService
+}
+
+func NewBoxController(boxService service.BoxService) BoxController {
+	return &BoxControllerImpl{
+		BoxService: boxService,
+	}
+}
+
+func (controller *BoxControllerImpl) FindByPlayer(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	boxResponse := controller.BoxService.FindByPlayer(request.Context(), endpointId, player)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   boxResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *BoxControllerImpl) Reset(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	boxResponse := controller.BoxService.Reset(request.Context(), endpointId, player)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   boxResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
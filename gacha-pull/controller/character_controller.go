@@ -1,15 +1,22 @@
 package controller
 
 import (
+	"gacha-pull/exception"
 	"gacha-pull/helper"
 	"gacha-pull/model/web"
 	"gacha-pull/service"
 	"github.com/go-chi/chi/v5"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type CharacterController interface {
 	Pull(writer http.ResponseWriter, request *http.Request)
+	MultiPull(writer http.ResponseWriter, request *http.Request)
+	Reserve(writer http.ResponseWriter, request *http.Request)
+	Confirm(writer http.ResponseWriter, request *http.Request)
+	Cancel(writer http.ResponseWriter, request *http.Request)
 }
 
 type CharacterControllerImpl struct {
@@ -25,7 +32,13 @@ func NewCharacterController(rarityService service.CharacterService) CharacterCon
 func (controller *CharacterControllerImpl) Pull(writer http.ResponseWriter, request *http.Request) {
 	endpointId := chi.URLParam(request, "endpointId")
 
-	selectedCharacter := controller.CharacterService.Pull(request.Context(), endpointId)
+	player := helper.ExtractPlayer(request.Context())
+
+	pullRequest := web.PullRequest{
+		Currency: request.URL.Query().Get("currency"),
+	}
+
+	selectedCharacter := controller.CharacterService.Pull(request.Context(), endpointId, player, &pullRequest)
 
 	webResponse := web.WebResponse{
 		Code:   200,
@@ -35,3 +48,107 @@ func (controller *CharacterControllerImpl) Pull(writer http.ResponseWriter, requ
 
 	helper.WriteToResponseBody(writer, webResponse)
 }
+
+func (controller *CharacterControllerImpl) MultiPull(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	count := service.DefaultMultiPullCount
+	countStr := request.URL.Query().Get("count")
+	if countStr != "" {
+		var err error
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid pull count"))
+		}
+	}
+
+	player := helper.ExtractPlayer(request.Context())
+
+	pullRequest := web.PullRequest{
+		Count:    count,
+		Currency: request.URL.Query().Get("currency"),
+	}
+
+	selectedCharacters := controller.CharacterService.MultiPull(request.Context(), endpointId, player, &pullRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   selectedCharacters,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CharacterControllerImpl) Reserve(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	count := 1
+	if countStr := request.URL.Query().Get("count"); countStr != "" {
+		var err error
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid pull count"))
+		}
+	}
+
+	var ttl time.Duration
+	if ttlStr := request.URL.Query().Get("ttl"); ttlStr != "" {
+		seconds, err := strconv.Atoi(ttlStr)
+		if err != nil {
+			panic(exception.NewBadRequestError("Invalid reservation ttl"))
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	player := helper.ExtractPlayer(request.Context())
+
+	reservationRequest := web.PullReservationRequest{
+		Count: count,
+		Ttl:   ttl,
+	}
+
+	reservationResponse := controller.CharacterService.Reserve(request.Context(), endpointId, player, &reservationRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   reservationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CharacterControllerImpl) Confirm(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+	token := chi.URLParam(request, "token")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	reservationResponse := controller.CharacterService.Confirm(request.Context(), endpointId, player, token)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   reservationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *CharacterControllerImpl) Cancel(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+	token := chi.URLParam(request, "token")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	reservationResponse := controller.CharacterService.Cancel(request.Context(), endpointId, player, token)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   reservationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
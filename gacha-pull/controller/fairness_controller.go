@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type FairnessController interface {
+	FindActiveSeed(writer http.ResponseWriter, request *http.Request)
+	RotateSeed(writer http.ResponseWriter, request *http.Request)
+	Verify(writer http.ResponseWriter, request *http.Request)
+}
+
+type FairnessControllerImpl struct {
+	FairnessService service.FairnessService
+}
+
+func NewFairnessController(fairnessService service.FairnessService) FairnessController {
+	return &FairnessControllerImpl{
+		FairnessService: fairnessService,
+	}
+}
+
+func (controller *FairnessControllerImpl) FindActiveSeed(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	fairSeedResponse := controller.FairnessService.FindActiveSeed(request.Context(), endpointId, player)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   fairSeedResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *FairnessControllerImpl) RotateSeed(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	rotateRequest := web.FairSeedRotateRequest{}
+	helper.ReadFromRequestBody(request, &rotateRequest)
+
+	rotateResponse := controller.FairnessService.RotateSeed(request.Context(), endpointId, player, &rotateRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   rotateResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *FairnessControllerImpl) Verify(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	verifyRequest := web.FairVerifyRequest{}
+	helper.ReadFromRequestBody(request, &verifyRequest)
+
+	verifyResponse := controller.FairnessService.Verify(request.Context(), endpointId, &verifyRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   verifyResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type GachaConfigController interface {
+	Invalidate(writer http.ResponseWriter, request *http.Request)
+}
+
+type GachaConfigControllerImpl struct {
+	GachaConfigService service.GachaConfigService
+}
+
+func NewGachaConfigController(gachaConfigService service.GachaConfigService) GachaConfigController {
+	return &GachaConfigControllerImpl{
+		GachaConfigService: gachaConfigService,
+	}
+}
+
+func (controller *GachaConfigControllerImpl) Invalidate(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	controller.GachaConfigService.Invalidate(request.Context(), endpointId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type InventoryController interface {
+	FindByPlayer(writer http.ResponseWriter, request *http.Request)
+}
+
+type InventoryControllerImpl struct {
+	InventoryService service.InventoryService
+}
+
+func NewInventoryController(inventoryService service.InventoryService) InventoryController {
+	return &InventoryControllerImpl{
+		InventoryService: inventoryService,
+	}
+}
+
+func (controller *InventoryControllerImpl) FindByPlayer(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	inventoryResponse := controller.InventoryService.FindByPlayer(request.Context(), endpointId, player)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   inventoryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type PullHistoryController interface {
+	FindAll(writer http.ResponseWriter, request *http.Request)
+}
+
+type PullHistoryControllerImpl struct {
+	PullHistoryService service.PullHistoryService
+}
+
+func NewPullHistoryController(pullHistoryService service.PullHistoryService) PullHistoryController {
+	return &PullHistoryControllerImpl{
+		PullHistoryService: pullHistoryService,
+	}
+}
+
+func (controller *PullHistoryControllerImpl) FindAll(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	query := request.URL.Query()
+	pullHistoryRequest := web.PullHistoryRequest{
+		Page:   parseIntQuery(query.Get("page"), "Invalid page"),
+		Size:   parseIntQuery(query.Get("size"), "Invalid page size"),
+		Rarity: query.Get("rarity"),
+		From:   parseTimeQuery(query.Get("from"), false),
+		To:     parseTimeQuery(query.Get("to"), true),
+	}
+
+	pullHistoryResponse := controller.PullHistoryService.FindAll(request.Context(), endpointId, player, &pullHistoryRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   pullHistoryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func parseIntQuery(value string, message string) int {
+	if value == "" {
+		return 0
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		panic(exception.NewBadRequestError(message))
+	}
+
+	return number
+}
+
+// parseTimeQuery accepts an RFC 3339 timestamp or a plain date. A plain date used as an upper
+// bound includes the whole day.
+func parseTimeQuery(value string, endOfDay bool) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return &parsed
+	}
+
+	parsed, err := time.Parse(dateLayout, value)
+	if err != nil {
+		panic(exception.NewBadRequestError("Invalid date, use YYYY-MM-DD or RFC 3339"))
+	}
+	if endOfDay {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+
+	return &parsed
+}
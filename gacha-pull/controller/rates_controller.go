@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"html/template"
+	"net/http"
+)
+
+type RatesController interface {
+	FindRates(writer http.ResponseWriter, request *http.Request)
+	RenderRates(writer http.ResponseWriter, request *http.Request)
+}
+
+type RatesControllerImpl struct {
+	RatesService service.RatesService
+}
+
+func NewRatesController(ratesService service.RatesService) RatesController {
+	return &RatesControllerImpl{
+		RatesService: ratesService,
+	}
+}
+
+func (controller *RatesControllerImpl) FindRates(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	ratesResponse := controller.RatesService.FindRates(request.Context(), endpointId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   ratesResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+// RenderRates serves the rates as a page that can be linked from a store listing.
+func (controller *RatesControllerImpl) RenderRates(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	ratesResponse := controller.RatesService.FindRates(request.Context(), endpointId)
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := ratesPageTemplate.Execute(writer, ratesResponse)
+	helper.PanicIfError(err, "Failed to render rates page")
+}
+
+var ratesPageTemplate = template.Must(template.New("rates").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.GachaSystem}} drop rates</title>
+<style>
+body { font-family: sans-serif; margin: 2rem auto; max-width: 48rem; padding: 0 1rem; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2rem; }
+th, td { border-bottom: 1px solid #ddd; padding: .4rem; text-align: left; }
+td.rate, th.rate { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.GachaSystem}} drop rates</h1>
+{{if .Banner}}<p>Banner: {{.Banner}}</p>{{end}}
+<p>The base rate is the chance of a single pull without pity. The consolidated rate is the long-run
+share of pulls, including pity and featured guarantees.</p>
+{{if .Approximate}}<p>{{.Note}}</p>{{end}}
+{{if .GuaranteedRarity}}<p>Every {{.GuaranteePullCount}} pulls in a multi-pull include at least one {{.GuaranteedRarity}} or rarer character.</p>{{end}}
+<table>
+<tr><th>Rarity</th><th class="rate">Base rate</th><th class="rate">Consolidated rate</th><th class="rate">Pity</th></tr>
+{{range .Rarities}}<tr><td>{{.Name}}</td><td class="rate">{{.BaseRate}}%</td><td class="rate">{{.ConsolidatedRate}}%</td><td class="rate">{{if .PityThreshold}}{{.PityThreshold}} pulls{{else}}-{{end}}</td></tr>
+{{end}}</table>
+{{range .Rarities}}<h2>{{.Name}}</h2>
+<table>
+<tr><th>Character</th><th class="rate">Base rate</th><th class="rate">Consolidated rate</th></tr>
+{{range .Characters}}<tr><td>{{.Name}}{{if .Featured}} (featured){{end}}</td><td class="rate">{{.BaseRate}}%</td><td class="rate">{{.ConsolidatedRate}}%</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
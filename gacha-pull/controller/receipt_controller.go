@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/service"
+	"net/http"
+)
+
+type ReceiptController interface {
+	FindKeys(writer http.ResponseWriter, request *http.Request)
+}
+
+type ReceiptControllerImpl struct {
+	ReceiptService service.ReceiptService
+}
+
+func NewReceiptController(receiptService service.ReceiptService) ReceiptController {
+	return &ReceiptControllerImpl{
+		ReceiptService: receiptService,
+	}
+}
+
+// FindKeys answers with a bare JWKS document, as expected by JWKS clients.
+func (controller *ReceiptControllerImpl) FindKeys(writer http.ResponseWriter, request *http.Request) {
+	writer.Header().Set("Cache-Control", "public, max-age=3600")
+
+	helper.WriteToResponseBody(writer, controller.ReceiptService.FindKeys())
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type SimulationController interface {
+	Simulate(writer http.ResponseWriter, request *http.Request)
+}
+
+type SimulationControllerImpl struct {
+	SimulationService service.SimulationService
+}
+
+func NewSimulationController(simulationService service.SimulationService) SimulationController {
+	return &SimulationControllerImpl{
+		SimulationService: simulationService,
+	}
+}
+
+func (controller *SimulationControllerImpl) Simulate(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	simulationRequest := web.SimulationRequest{}
+	helper.ReadFromRequestBody(request, &simulationRequest)
+
+	simulationResponse := controller.SimulationService.Simulate(request.Context(), endpointId, &simulationRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   simulationResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+)
+
+type SparkController interface {
+	FindByPlayer(writer http.ResponseWriter, request *http.Request)
+	Redeem(writer http.ResponseWriter, request *http.Request)
+}
+
+type SparkControllerImpl struct {
+	SparkService service.SparkService
+}
+
+func NewSparkController(sparkService service.SparkService) SparkController {
+	return &SparkControllerImpl{
+		SparkService: sparkService,
+	}
+}
+
+func (controller *SparkControllerImpl) FindByPlayer(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	sparkResponse := controller.SparkService.FindByPlayer(request.Context(), endpointId, player)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   sparkResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *SparkControllerImpl) Redeem(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	redeemRequest := web.SparkRedeemRequest{}
+	helper.ReadFromRequestBody(request, &redeemRequest)
+
+	redeemResponse := controller.SparkService.Redeem(request.Context(), endpointId, player, &redeemRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   redeemResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"gacha-pull/exception"
+	"gacha-pull/helper"
+	"gacha-pull/model/web"
+	"gacha-pull/service"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+)
+
+type WalletController interface {
+	FindByPlayer(writer http.ResponseWriter, request *http.Request)
+	FindLedger(writer http.ResponseWriter, request *http.Request)
+	ApplyEntry(writer http.ResponseWriter, request *http.Request)
+}
+
+type WalletControllerImpl struct {
+	WalletService service.WalletService
+}
+
+func NewWalletController(walletService service.WalletService) WalletController {
+	return &WalletControllerImpl{
+		WalletService: walletService,
+	}
+}
+
+func (controller *WalletControllerImpl) FindByPlayer(writer http.ResponseWriter, request *http.Request) {
+	endpointId := chi.URLParam(request, "endpointId")
+
+	player := helper.ExtractPlayer(request.Context())
+
+	walletResponse := controller.WalletService.FindByPlayer(request.Context(), endpointId, player)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   walletResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *WalletControllerImpl) FindLedger(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemId := gachaSystemIdParam(request)
+	playerId := chi.URLParam(request, "playerId")
+
+	walletLedgerResponse := controller.WalletService.FindLedger(request.Context(), gachaSystemId, playerId)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   walletLedgerResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func (controller *WalletControllerImpl) ApplyEntry(writer http.ResponseWriter, request *http.Request) {
+	gachaSystemId := gachaSystemIdParam(request)
+
+	walletEntryRequest := web.WalletEntryRequest{}
+	helper.ReadFromRequestBody(request, &walletEntryRequest)
+
+	walletLedgerEntryResponse := controller.WalletService.ApplyEntry(request.Context(), gachaSystemId, &walletEntryRequest)
+
+	webResponse := web.WebResponse{
+		Code:   200,
+		Status: "OK",
+		Data:   walletLedgerEntryResponse,
+	}
+
+	helper.WriteToResponseBody(writer, webResponse)
+}
+
+func gachaSystemIdParam(request *http.Request) int {
+	gachaSystemId, err := strconv.Atoi(chi.URLParam(request, "gachaSystemId"))
+	if err != nil {
+		panic(exception.NewBadRequestError("Invalid gacha system id"))
+	}
+	return gachaSystemId
+}
@@ -0,0 +1,13 @@
+package exception
+
+type BadGatewayError struct {
+	message string
+}
+
+func NewBadGatewayError(error string) *BadGatewayError {
+	return &BadGatewayError{message: error}
+}
+
+func (e *BadGatewayError) Error() string {
+	return e.message
+}
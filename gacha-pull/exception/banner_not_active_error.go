@@ -0,0 +1,13 @@
+package exception
+
+type BannerNotActiveError struct {
+	message string
+}
+
+func NewBannerNotActiveError(error string) *BannerNotActiveError {
+	return &BannerNotActiveError{message: error}
+}
+
+func (e *BannerNotActiveError) Error() string {
+	return e.message
+}
@@ -0,0 +1,13 @@
+package exception
+
+type PaymentRequiredError struct {
+	message string
+}
+
+func NewPaymentRequiredError(error string) *PaymentRequiredError {
+	return &PaymentRequiredError{message: error}
+}
+
+func (e *PaymentRequiredError) Error() string {
+	return e.message
+}
@@ -0,0 +1,16 @@
+package exception
+
+import "time"
+
+type TooManyRequestsError struct {
+	message    string
+	RetryAfter time.Duration
+}
+
+func NewTooManyRequestsError(error string, retryAfter time.Duration) *TooManyRequestsError {
+	return &TooManyRequestsError{message: error, RetryAfter: retryAfter}
+}
+
+func (e *TooManyRequestsError) Error() string {
+	return e.message
+}
@@ -0,0 +1,13 @@
+package exception
+
+type UnauthorizedError struct {
+	message string
+}
+
+func NewUnauthorizedError(error string) *UnauthorizedError {
+	return &UnauthorizedError{message: error}
+}
+
+func (e *UnauthorizedError) Error() string {
+	return e.message
+}
@@ -0,0 +1,92 @@
+// Package fairness implements the commit-reveal scheme behind provably fair pulls.
+//
+// Before pulling, a player receives the SHA-256 hash of a secret server seed. Every pull draws its
+// random numbers from HMAC-SHA256(server seed, "clientSeed:nonce:round"), where the client seed is
+// chosen by the player and the nonce counts the pulls made with the seed pair. Once the server
+// seed is revealed anyone can check it against the published hash and replay every pull with
+// Replay, using nothing but this package and the published gacha configuration.
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"gacha-pull/model/domain"
+	"gacha-pull/selection"
+)
+
+const (
+	ServerSeedBytes = 32
+	ClientSeedBytes = 16
+)
+
+// NewServerSeed returns a random hex encoded server seed.
+func NewServerSeed() string {
+	return randomHex(ServerSeedBytes)
+}
+
+// NewClientSeed returns a random hex encoded client seed, used until the player picks their own.
+func NewClientSeed() string {
+	return randomHex(ClientSeedBytes)
+}
+
+func randomHex(size int) string {
+	seed := make([]byte, size)
+	if _, err := rand.Read(seed); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(seed)
+}
+
+// HashServerSeed returns the hex encoded SHA-256 hash published before the seed is used.
+func HashServerSeed(serverSeed string) string {
+	hash := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(hash[:])
+}
+
+// VerifyServerSeed reports whether a revealed server seed matches its published hash.
+func VerifyServerSeed(serverSeed string, serverSeedHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashServerSeed(serverSeed)), []byte(serverSeedHash)) == 1
+}
+
+// Random is a deterministic random source derived from a server seed, a client seed and a nonce.
+// Each HMAC round yields 32 bytes, consumed 8 bytes per number.
+type Random struct {
+	serverSeed string
+	clientSeed string
+	nonce      int
+	round      int
+	buffer     []byte
+}
+
+func NewRandom(serverSeed string, clientSeed string, nonce int) *Random {
+	return &Random{
+		serverSeed: serverSeed,
+		clientSeed: clientSeed,
+		nonce:      nonce,
+	}
+}
+
+// Float64 returns the next number in [0, 1) built from the top 53 bits of the next 8 bytes.
+func (random *Random) Float64() float64 {
+	if len(random.buffer) < 8 {
+		mac := hmac.New(sha256.New, []byte(random.serverSeed))
+		mac.Write([]byte(fmt.Sprintf("%s:%d:%d", random.clientSeed, random.nonce, random.round)))
+		random.buffer = mac.Sum(nil)
+		random.round++
+	}
+
+	value := binary.BigEndian.Uint64(random.buffer[:8])
+	random.buffer = random.buffer[8:]
+	return float64(value>>11) / (1 << 53)
+}
+
+// Replay repeats the pulls made with a seed pair and nonce. pities holds the pity state of the
+// player before the pulls, or nil when the pulls were made without pity.
+func Replay(pool *selection.Pool, serverSeed string, clientSeed string, nonce int, count int, pities map[int]*domain.Pity) []selection.Result {
+	return pool.Draw(count, pities, NewRandom(serverSeed, clientSeed, nonce))
+}
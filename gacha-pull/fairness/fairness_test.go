@@ -0,0 +1,156 @@
+package fairness
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"gacha-pull/model/domain"
+	"gacha-pull/selection"
+	"reflect"
+	"testing"
+)
+
+func TestRandomKnownValues(t *testing.T) {
+	tests := []struct {
+		name       string
+		serverSeed string
+		clientSeed string
+		nonce      int
+		expected   []float64
+	}{
+		{"first nonce", "server", "client", 0, []float64{0.8782961694284506, 0.631285010648874, 0.4784264779336599, 0.045596995618299885}},
+		{"next nonce", "server", "client", 1, []float64{0.5346024100057738, 0.9821543973529523}},
+		{"other client seed", "server", "client2", 0, []float64{0.8524030337957529}},
+		{"other server seed", "server2", "client", 0, []float64{0.6872577553514909}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			random := NewRandom(test.serverSeed, test.clientSeed, test.nonce)
+			for i, expected := range test.expected {
+				if number := random.Float64(); number != expected {
+					t.Errorf("number %d is %v, expected %v", i, number, expected)
+				}
+			}
+		})
+	}
+}
+
+// TestRandomMatchesHmac derives the numbers as described in the package documentation, so a
+// change of the derivation that breaks verification by third parties is caught.
+func TestRandomMatchesHmac(t *testing.T) {
+	const serverSeed, clientSeed, nonce = "a3f1", "player chosen", 7
+
+	random := NewRandom(serverSeed, clientSeed, nonce)
+	for round := 0; round < 3; round++ {
+		mac := hmac.New(sha256.New, []byte(serverSeed))
+		mac.Write([]byte(fmt.Sprintf("%s:%d:%d", clientSeed, nonce, round)))
+		digest := mac.Sum(nil)
+
+		for offset := 0; offset < len(digest); offset += 8 {
+			expected := float64(binary.BigEndian.Uint64(digest[offset:])>>11) / (1 << 53)
+			if number := random.Float64(); number != expected {
+				t.Fatalf("round %d offset %d is %v, expected %v", round, offset, number, expected)
+			}
+		}
+	}
+}
+
+func TestRandomRange(t *testing.T) {
+	random := NewRandom(NewServerSeed(), NewClientSeed(), 0)
+	for i := 0; i < 10_000; i++ {
+		if number := random.Float64(); number < 0 || number >= 1 {
+			t.Fatalf("number %d is %v, outside [0, 1)", i, number)
+		}
+	}
+}
+
+func TestVerifyServerSeed(t *testing.T) {
+	const serverSeedHash = "b3eacd33433b31b5252351032c9b3e7a2e7aa7738d5decdf0dd6c62680853c06"
+
+	if hash := HashServerSeed("server"); hash != serverSeedHash {
+		t.Errorf("HashServerSeed returned %s, expected %s", hash, serverSeedHash)
+	}
+	if !VerifyServerSeed("server", serverSeedHash) {
+		t.Error("the revealed server seed does not match its hash")
+	}
+	if VerifyServerSeed("server2", serverSeedHash) {
+		t.Error("another server seed matches the hash")
+	}
+}
+
+func newTestPool(t *testing.T) *selection.Pool {
+	rarities := []domain.Rarity{
+		{Id: 1, Name: "SSR", Chance: 1.6, PityThreshold: 20, SoftPityStart: 10, SoftPityStep: 6, FeaturedChance: 50},
+		{Id: 2, Name: "SR", Chance: 13, PityThreshold: 10},
+		{Id: 3, Name: "R", Chance: 85.4},
+	}
+	characters := []domain.Character{
+		{Id: 1, RarityId: 1, Featured: true, Weight: 1},
+		{Id: 2, RarityId: 1, Weight: 1},
+		{Id: 3, RarityId: 1, Weight: 2},
+		{Id: 4, RarityId: 2, Weight: 1},
+		{Id: 5, RarityId: 2, Weight: 3},
+		{Id: 6, RarityId: 3, Weight: 1},
+		{Id: 7, RarityId: 3, Weight: 1},
+	}
+	gachaSystem := &domain.GachaSystem{Id: 1, GuaranteedRarityId: 2, GuaranteePullCount: 10, ProvablyFair: true}
+
+	pool, err := selection.NewPool(gachaSystem, nil, rarities, characters)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	return pool
+}
+
+func copyPities(pities map[int]*domain.Pity) map[int]*domain.Pity {
+	copied := make(map[int]*domain.Pity)
+	for rarityId, pity := range pities {
+		pityCopy := *pity
+		copied[rarityId] = &pityCopy
+	}
+	return copied
+}
+
+// TestReplayRoundTrip draws pulls the way a provably fair pull does and checks that replaying the
+// revealed seeds gives the same pulls, while a wrong seed or nonce does not.
+func TestReplayRoundTrip(t *testing.T) {
+	pool := newTestPool(t)
+	const serverSeed, clientSeed = "9c1e5f0d", "lucky"
+
+	pities := pool.NewPities("player", nil)
+	var draws [][]selection.Result
+	var pitiesBefore []map[int]*domain.Pity
+	for nonce := 0; nonce < 20; nonce++ {
+		pitiesBefore = append(pitiesBefore, copyPities(pities))
+		draws = append(draws, pool.Draw(10, pities, NewRandom(serverSeed, clientSeed, nonce)))
+	}
+
+	for nonce, results := range draws {
+		replayed := Replay(pool, serverSeed, clientSeed, nonce, 10, copyPities(pitiesBefore[nonce]))
+		if !reflect.DeepEqual(replayed, results) {
+			t.Fatalf("replay of nonce %d differs from the draw", nonce)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		serverSeed string
+		clientSeed string
+		nonce      int
+	}{
+		{"other server seed", "9c1e5f0e", clientSeed, 0},
+		{"other client seed", serverSeed, "lucky!", 0},
+		{"other nonce", serverSeed, clientSeed, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replayed := Replay(pool, test.serverSeed, test.clientSeed, test.nonce, 10, copyPities(pitiesBefore[0]))
+			if reflect.DeepEqual(replayed, draws[0]) {
+				t.Error("replay with a wrong seed or nonce matches the draw")
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package helper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"gacha-pull/model/domain"
+)
+
+type apiKeyContextKey struct{}
+
+// HashApiKey returns the hex encoded SHA-256 hash under which gacha-master stores an API key.
+func HashApiKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func WithApiKey(ctx context.Context, apiKey *domain.ApiKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, apiKey)
+}
+
+// ExtractApiKey returns the API key the request was made with, or nil when none was sent.
+func ExtractApiKey(ctx context.Context) *domain.ApiKey {
+	apiKey, _ := ctx.Value(apiKeyContextKey{}).(*domain.ApiKey)
+	return apiKey
+}
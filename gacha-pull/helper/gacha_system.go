@@ -0,0 +1,18 @@
+package helper
+
+import (
+	"context"
+	"gacha-pull/model/domain"
+)
+
+type gachaSystemContextKey struct{}
+
+func WithGachaSystem(ctx context.Context, gachaSystem *domain.GachaSystem) context.Context {
+	return context.WithValue(ctx, gachaSystemContextKey{}, gachaSystem)
+}
+
+// ExtractGachaSystem returns the gacha system of the endpoint the request was made to.
+func ExtractGachaSystem(ctx context.Context) *domain.GachaSystem {
+	gachaSystem, _ := ctx.Value(gachaSystemContextKey{}).(*domain.GachaSystem)
+	return gachaSystem
+}
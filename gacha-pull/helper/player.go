@@ -0,0 +1,27 @@
+package helper
+
+import (
+	"context"
+	"gacha-pull/model/domain"
+)
+
+type playerContextKey struct{}
+
+func WithPlayer(ctx context.Context, player *domain.User) context.Context {
+	return context.WithValue(ctx, playerContextKey{}, player)
+}
+
+// ExtractPlayer returns the player resolved for the request, or nil for anonymous requests.
+func ExtractPlayer(ctx context.Context) *domain.User {
+	player, _ := ctx.Value(playerContextKey{}).(*domain.User)
+	return player
+}
+
+// ExtractPlayerId returns the id of the player resolved for the request, or an empty string for
+// anonymous requests.
+func ExtractPlayerId(ctx context.Context) string {
+	if player := ExtractPlayer(ctx); player != nil {
+		return player.Id
+	}
+	return ""
+}
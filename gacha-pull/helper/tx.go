@@ -3,8 +3,31 @@ package helper
 import (
 	"context"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+type txKey struct{}
+
+// BeginTx begins a transaction on the pool. When ctx carries a transaction started by RunInTx, a
+// savepoint inside that transaction is begun instead, so the work joins it.
+func BeginTx(ctx context.Context, dbpool *pgxpool.Pool) (pgx.Tx, error) {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx.Begin(ctx)
+	}
+	return dbpool.Begin(ctx)
+}
+
+// RunInTx calls fn with a context carrying one transaction. Repositories called with that context
+// join the transaction, which commits when fn returns and rolls back when it panics.
+func RunInTx(ctx context.Context, dbpool *pgxpool.Pool, fn func(ctx context.Context)) {
+	tx, err := BeginTx(ctx, dbpool)
+	PanicIfError(err, ErrBeginTransaction)
+
+	defer CommitOrRollback(tx, ctx)
+
+	fn(context.WithValue(ctx, txKey{}, tx))
+}
+
 func CommitOrRollback(tx pgx.Tx, ctx context.Context) {
 	err := recover()
 	if err != nil {
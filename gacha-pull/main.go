@@ -2,26 +2,84 @@ package main
 
 import (
 	"gacha-pull/app"
+	"gacha-pull/client"
 	"gacha-pull/controller"
 	"gacha-pull/helper"
+	"gacha-pull/poolcache"
+	"gacha-pull/ratelimit"
 	"gacha-pull/repository"
 	"gacha-pull/service"
 	_ "github.com/joho/godotenv/autoload"
 	"net/http"
+	"os"
 )
 
 func main() {
 	dbpool := app.NewDB()
 	defer dbpool.Close()
 
-	gachaSystemRepository := repository.NewGachaSystemRepository(dbpool)
-	rarityRepository := repository.NewRarityRepository(dbpool)
-	characterRepository := repository.NewCharacterRepository(dbpool)
+	pityRepository := repository.NewPityRepository(dbpool)
+	fairSeedRepository := repository.NewFairSeedRepository(dbpool)
+	pullHistoryRepository := repository.NewPullHistoryRepository(dbpool)
+	walletRepository := repository.NewWalletRepository(dbpool)
+	inventoryRepository := repository.NewInventoryRepository(dbpool)
+	boxRepository := repository.NewBoxRepository(dbpool)
+	sparkRepository := repository.NewSparkRepository(dbpool)
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository(dbpool)
+	pullReservationRepository := repository.NewPullReservationRepository(dbpool)
+	transaction := repository.NewTransaction(dbpool)
 
-	characterService := service.NewCharacterService(characterRepository, rarityRepository, gachaSystemRepository)
+	gachaMasterClient := client.NewGachaMasterClient(os.Getenv("GACHA_MASTER_INTERNAL_URL"), os.Getenv("INTERNAL_API_KEY"))
+	gachaPoolCache := poolcache.NewCache(poolcache.NewClientLoader(gachaMasterClient),
+		poolcache.NewClientVersionLoader(gachaMasterClient), poolcache.DefaultTtl)
+	receiptSigner := app.NewReceiptSigner()
+
+	characterService := service.NewCharacterService(gachaPoolCache, pityRepository, fairSeedRepository, pullHistoryRepository,
+		walletRepository, inventoryRepository, boxRepository, sparkRepository, receiptSigner, pullReservationRepository, transaction)
 	characterController := controller.NewCharacterController(characterService)
 
-	router := app.NewRouter(characterController)
+	fairnessService := service.NewFairnessService(gachaPoolCache, fairSeedRepository, pityRepository, pullReservationRepository, transaction)
+	fairnessController := controller.NewFairnessController(fairnessService)
+
+	pullHistoryService := service.NewPullHistoryService(gachaPoolCache, pullHistoryRepository)
+	pullHistoryController := controller.NewPullHistoryController(pullHistoryService)
+
+	walletService := service.NewWalletService(gachaPoolCache, walletRepository)
+	walletController := controller.NewWalletController(walletService)
+
+	inventoryService := service.NewInventoryService(gachaPoolCache, inventoryRepository)
+	inventoryController := controller.NewInventoryController(inventoryService)
+
+	ratesService := service.NewRatesService(gachaPoolCache)
+	ratesController := controller.NewRatesController(ratesService)
+
+	simulationService := service.NewSimulationService(gachaPoolCache)
+	simulationController := controller.NewSimulationController(simulationService)
+
+	boxService := service.NewBoxService(gachaPoolCache, boxRepository)
+	boxController := controller.NewBoxController(boxService)
+
+	sparkService := service.NewSparkService(gachaPoolCache, sparkRepository, walletRepository,
+		inventoryRepository, transaction)
+	sparkController := controller.NewSparkController(sparkService)
+	app.StartSweeper("spark", service.SparkSettleInterval, sparkService.SettleEndedBanners)
+	app.StartSweeper("pull reservations", service.ReservationSweepInterval, characterService.ReleaseExpiredReservations)
+
+	receiptService := service.NewReceiptService(receiptSigner)
+	receiptController := controller.NewReceiptController(receiptService)
+
+	gachaConfigService := service.NewGachaConfigService(gachaPoolCache)
+	gachaConfigController := controller.NewGachaConfigController(gachaConfigService)
+
+	gachaSystemMiddleware := app.NewGachaSystemMiddleware(gachaPoolCache)
+	apiKeyMiddleware := app.NewApiKeyMiddleware(gachaPoolCache)
+	rateLimitMiddleware := app.NewRateLimitMiddleware(ratelimit.NewLimiter())
+	idempotencyMiddleware := app.NewIdempotencyMiddleware(idempotencyKeyRepository)
+	app.StartSweeper("idempotency keys", app.IdempotencySweepInterval, app.SweepIdempotencyKeys(idempotencyKeyRepository))
+
+	router := app.NewRouter(characterController, fairnessController, pullHistoryController, walletController,
+		inventoryController, ratesController, simulationController, boxController, sparkController, receiptController,
+		gachaConfigController, gachaSystemMiddleware, apiKeyMiddleware, rateLimitMiddleware, idempotencyMiddleware)
 
 	server := http.Server{
 		Addr:    ":8002",
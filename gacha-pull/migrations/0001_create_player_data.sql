@@ -0,0 +1,156 @@
+-- gacha-pull keeps the data of players in its own database, apart from the gacha systems of
+-- gacha-master. Gacha systems, rarities, characters and currencies are only known by id from the
+-- configs gacha-master serves, so nothing references them: the data of a deleted gacha system or
+-- currency is simply never read again. Create the database and apply the migrations in order:
+--
+--   CREATE DATABASE gacha_pull;
+--   psql gacha_pull -f migrations/0001_create_player_data.sql
+BEGIN;
+
+CREATE TABLE pity (
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   rarity_id INTEGER NOT NULL,
+   counter INTEGER DEFAULT 0 NOT NULL,
+   guaranteed_featured BOOLEAN DEFAULT FALSE NOT NULL,
+   updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   PRIMARY KEY (gacha_system_id, player_id, rarity_id)
+);
+
+CREATE TABLE fair_seed (
+   id SERIAL PRIMARY KEY,
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   server_seed VARCHAR(64) NOT NULL,
+   server_seed_hash VARCHAR(64) NOT NULL,
+   client_seed VARCHAR(64) NOT NULL,
+   nonce INTEGER DEFAULT 0 NOT NULL,
+   revealed_at TIMESTAMPTZ,
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE UNIQUE INDEX fair_seed_active_idx ON fair_seed (gacha_system_id, player_id) WHERE revealed_at IS NULL;
+
+CREATE TABLE pull_history (
+   id BIGSERIAL PRIMARY KEY,
+   pull_id VARCHAR(36) NOT NULL UNIQUE,
+   gacha_system_id INTEGER NOT NULL,
+   config_version_id INTEGER,
+   player_id VARCHAR(100) NOT NULL,
+   character_id INTEGER NOT NULL,
+   character_name VARCHAR(100) NOT NULL,
+   rarity_id INTEGER NOT NULL,
+   rarity_name VARCHAR(50) NOT NULL,
+   featured BOOLEAN DEFAULT FALSE NOT NULL,
+   pity JSONB DEFAULT '[]' NOT NULL,
+   pulled_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX pull_history_player_idx ON pull_history (gacha_system_id, player_id, pulled_at DESC);
+
+CREATE TABLE wallet (
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   currency_id INTEGER NOT NULL,
+   balance BIGINT DEFAULT 0 NOT NULL CHECK (balance >= 0),
+   updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   PRIMARY KEY (gacha_system_id, player_id, currency_id)
+);
+
+CREATE TABLE wallet_ledger (
+   id BIGSERIAL PRIMARY KEY,
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   currency_id INTEGER NOT NULL,
+   amount BIGINT NOT NULL,
+   balance_after BIGINT NOT NULL,
+   reason VARCHAR(50) NOT NULL,
+   note TEXT,
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE INDEX wallet_ledger_player_idx ON wallet_ledger (gacha_system_id, player_id, created_at DESC);
+
+CREATE TABLE inventory (
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   character_id INTEGER NOT NULL,
+   copies INTEGER DEFAULT 1 NOT NULL CHECK (copies > 0),
+   first_obtained_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   PRIMARY KEY (gacha_system_id, player_id, character_id)
+);
+
+-- A spark keeps the settlement terms of its banner as of the last pull, so editing or deleting the
+-- banner never loses unsettled points: they are settled on these terms once ends_at has passed,
+-- or expire when the spark currency was deleted since.
+CREATE TABLE spark (
+   banner_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   gacha_system_id INTEGER NOT NULL,
+   endpoint_id TEXT NOT NULL,
+   banner_name VARCHAR(100) NOT NULL,
+   ends_at TIMESTAMPTZ NOT NULL,
+   spark_currency_id INTEGER,
+   spark_conversion INTEGER DEFAULT 0 NOT NULL CHECK (spark_conversion >= 0),
+   points INTEGER DEFAULT 0 NOT NULL CHECK (points >= 0),
+   settled_at TIMESTAMPTZ,
+   updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   PRIMARY KEY (banner_id, player_id)
+);
+
+CREATE INDEX spark_unsettled_idx ON spark (ends_at) WHERE settled_at IS NULL;
+
+CREATE TABLE idempotency_key (
+   gacha_system_id INTEGER NOT NULL,
+   scope VARCHAR(150) NOT NULL,
+   key VARCHAR(255) NOT NULL,
+   request_hash CHAR(64) NOT NULL,
+   status_code INTEGER,
+   response_body BYTEA,
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   expires_at TIMESTAMPTZ NOT NULL,
+   PRIMARY KEY (gacha_system_id, scope, key)
+);
+
+CREATE INDEX idempotency_key_expires_idx ON idempotency_key (expires_at);
+
+CREATE TABLE pull_reservation (
+   token VARCHAR(64) PRIMARY KEY,
+   gacha_system_id INTEGER NOT NULL,
+   config_version_id INTEGER,
+   player_id VARCHAR(100) NOT NULL,
+   banner_id INTEGER,
+   pulls JSONB NOT NULL,
+   pity_before JSONB DEFAULT '[]' NOT NULL,
+   fair_pull JSONB,
+   status VARCHAR(20) DEFAULT 'reserved' NOT NULL CHECK (status IN ('reserved', 'confirmed', 'cancelled', 'expired')),
+   created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   expires_at TIMESTAMPTZ NOT NULL,
+   resolved_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX pull_reservation_open_idx ON pull_reservation (gacha_system_id, player_id) WHERE status = 'reserved';
+CREATE INDEX pull_reservation_expires_idx ON pull_reservation (expires_at) WHERE status = 'reserved';
+
+CREATE TABLE box (
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   round INTEGER DEFAULT 1 NOT NULL CHECK (round > 0),
+   version INTEGER DEFAULT 0 NOT NULL,
+   updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+   PRIMARY KEY (gacha_system_id, player_id)
+);
+
+CREATE TABLE box_draw (
+   gacha_system_id INTEGER NOT NULL,
+   player_id VARCHAR(100) NOT NULL,
+   character_id INTEGER NOT NULL,
+   drawn INTEGER NOT NULL CHECK (drawn > 0),
+   PRIMARY KEY (gacha_system_id, player_id, character_id),
+   FOREIGN KEY (gacha_system_id, player_id)
+       REFERENCES box(gacha_system_id, player_id)
+       ON DELETE CASCADE
+);
+
+COMMIT;
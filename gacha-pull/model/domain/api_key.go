@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type ApiKey struct {
+	Id            int
+	GachaSystemId int
+	Name          string
+	KeyHash       string
+	ExpiresAt     *time.Time
+}
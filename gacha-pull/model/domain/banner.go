@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// A banner with a spark threshold gives one spark point per pull, and the threshold in points can
+// be exchanged for a featured character of choice. Points left when the banner ends convert into
+// SparkConversion of the spark currency each, or expire without a spark currency.
+type Banner struct {
+	Id                   int
+	GachaSystemId        int
+	Name                 string
+	StartsAt             time.Time
+	EndsAt               time.Time
+	Timezone             string
+	FeaturedCharacterIds []int
+	SparkThreshold       int
+	SparkCurrencyId      *int
+	SparkConversion      int
+}
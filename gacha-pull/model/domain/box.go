@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// Box is the box of a player in a box gacha system. Drawn maps a character id to the copies drawn
+// from the box since its last reset, and Version changes on every draw and reset.
+type Box struct {
+	GachaSystemId int
+	PlayerId      string
+	Round         int
+	Version       int
+	UpdatedAt     time.Time
+	Drawn         map[int]int
+}
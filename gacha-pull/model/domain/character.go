@@ -4,6 +4,10 @@ type Character struct {
 	Id            int
 	Name          string
 	ImageUrl      string
+	Featured      bool
+	Weight        float32
 	RarityId      int
 	GachaSystemId int
+	BoxQuantity   int
+	BoxKey        bool
 }
@@ -0,0 +1,11 @@
+package domain
+
+type FairSeed struct {
+	Id             int
+	GachaSystemId  int
+	PlayerId       string
+	ServerSeed     string
+	ServerSeedHash string
+	ClientSeed     string
+	Nonce          int
+}
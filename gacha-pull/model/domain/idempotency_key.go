@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// IdempotencyKey remembers the response to a request made with an idempotency key. The status
+// code stays 0 while the first request with the key is still being handled.
+type IdempotencyKey struct {
+	GachaSystemId int
+	Scope         string
+	Key           string
+	RequestHash   string
+	StatusCode    int
+	ResponseBody  []byte
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+type InventoryItem struct {
+	GachaSystemId   int
+	PlayerId        string
+	CharacterId     int
+	Copies          int
+	FirstObtainedAt time.Time
+	UpdatedAt       time.Time
+}
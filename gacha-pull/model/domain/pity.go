@@ -0,0 +1,9 @@
+package domain
+
+type Pity struct {
+	GachaSystemId      int    `json:"gachaSystemId"`
+	PlayerId           string `json:"playerId"`
+	RarityId           int    `json:"rarityId"`
+	Counter            int    `json:"counter"`
+	GuaranteedFeatured bool   `json:"guaranteedFeatured"`
+}
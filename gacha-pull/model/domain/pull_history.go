@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// PullHistory keeps the character and rarity names as they were at pull time, so the history
+// stays readable after the gacha system is edited. ConfigVersionId is the published config
+// version the pull was made with, nil for pulls made before the gacha system was published.
+type PullHistory struct {
+	Id              int64
+	PullId          string
+	GachaSystemId   int
+	ConfigVersionId *int
+	PlayerId        string
+	CharacterId     int
+	CharacterName   string
+	RarityId        int
+	RarityName      string
+	Featured        bool
+	Pity            []PullHistoryPity
+	PulledAt        time.Time
+}
+
+type PullHistoryPity struct {
+	RarityId           int    `json:"rarityId"`
+	RarityName         string `json:"rarityName"`
+	Counter            int    `json:"counter"`
+	Threshold          int    `json:"threshold"`
+	GuaranteedFeatured bool   `json:"guaranteedFeatured"`
+}
+
+type PullHistoryFilter struct {
+	GachaSystemId int
+	PlayerId      string
+	RarityName    string
+	From          *time.Time
+	To            *time.Time
+	Limit         int
+	Offset        int
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+const (
+	PullReservationReserved  = "reserved"
+	PullReservationConfirmed = "confirmed"
+	PullReservationCancelled = "cancelled"
+	PullReservationExpired   = "expired"
+)
+
+// PullReservation holds pulls rolled ahead of a payment. The results stay hidden until the
+// reservation is confirmed. PityBefore is the pity state of the player before the pulls, restored
+// when the reservation is cancelled or expires. ConfigVersionId is the config version the pulls
+// were rolled with.
+type PullReservation struct {
+	Token           string
+	GachaSystemId   int
+	ConfigVersionId int
+	PlayerId        string
+	BannerId        *int
+	Pulls           []ReservedPull
+	PityBefore      []Pity
+	FairPull        *ReservedFairPull
+	Status          string
+	CreatedAt       time.Time
+	ExpiresAt       time.Time
+	ResolvedAt      *time.Time
+}
+
+type ReservedPull struct {
+	PullId      string `json:"pullId"`
+	CharacterId int    `json:"characterId"`
+	RarityId    int    `json:"rarityId"`
+	Featured    bool   `json:"featured"`
+	Pities      []Pity `json:"pities,omitempty"`
+}
+
+// ReservedFairPull is the seed pair and nonce provably fair reserved pulls were rolled with.
+type ReservedFairPull struct {
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          int    `json:"nonce"`
+}
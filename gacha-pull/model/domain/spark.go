@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Spark holds the spark points a player earned on a banner, together with the settlement terms of
+// the banner as of the last pull. Once EndsAt has passed the points are settled, converted into
+// the spark currency or expired.
+type Spark struct {
+	BannerId        int
+	PlayerId        string
+	GachaSystemId   int
+	EndpointId      string
+	BannerName      string
+	EndsAt          time.Time
+	SparkCurrencyId *int
+	SparkConversion int
+	Points          int
+	SettledAt       *time.Time
+	UpdatedAt       time.Time
+}
@@ -1,6 +1,11 @@
 package domain
 
+// User is the player a request is made for, as vouched for by a trusted game server or a player
+// token.
 type User struct {
-	Id   int
+	Id   string
 	Name string
+
+	// GameServer is set when a trusted game server named the player
+	GameServer bool
 }
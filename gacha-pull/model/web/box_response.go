@@ -0,0 +1,62 @@
+package web
+
+import (
+	"gacha-pull/model/domain"
+	"gacha-pull/selection"
+)
+
+type BoxResponse struct {
+	PlayerId  string            `json:"playerId"`
+	Round     int               `json:"round"`
+	Remaining int               `json:"remaining"`
+	Total     int               `json:"total"`
+	CanReset  bool              `json:"canReset"`
+	Items     []BoxItemResponse `json:"items"`
+}
+
+type BoxItemResponse struct {
+	Name      string  `json:"name"`
+	ImageUrl  string  `json:"imageUrl"`
+	Rarity    string  `json:"rarity"`
+	Key       bool    `json:"key"`
+	Quantity  int     `json:"quantity"`
+	Remaining int     `json:"remaining"`
+	Chance    float64 `json:"chance"`
+}
+
+// ToBoxResponse lists what is left in the box of a player, with the chance in percent that each
+// character comes out of the next draw.
+func ToBoxResponse(pool *selection.Pool, box *domain.Box) *BoxResponse {
+	remaining := pool.BoxRemaining(box.Drawn)
+
+	boxResponse := &BoxResponse{
+		PlayerId:  box.PlayerId,
+		Round:     box.Round,
+		Remaining: remaining,
+		CanReset:  pool.CanResetBox(box.Drawn),
+		Items:     []BoxItemResponse{},
+	}
+
+	for _, character := range pool.BoxCharacters() {
+		if character.BoxQuantity == 0 {
+			continue
+		}
+
+		itemResponse := BoxItemResponse{
+			Name:      character.Name,
+			ImageUrl:  character.ImageUrl,
+			Rarity:    pool.RarityNameMap[character.RarityId],
+			Key:       character.BoxKey,
+			Quantity:  character.BoxQuantity,
+			Remaining: max(character.BoxQuantity-box.Drawn[character.Id], 0),
+		}
+		if remaining > 0 {
+			itemResponse.Chance = toPercentage(float64(itemResponse.Remaining) / float64(remaining))
+		}
+
+		boxResponse.Total += character.BoxQuantity
+		boxResponse.Items = append(boxResponse.Items, itemResponse)
+	}
+
+	return boxResponse
+}
@@ -5,9 +5,26 @@ import (
 )
 
 type CharacterResponse struct {
-	Name     string `json:"name"`
-	ImageUrl string `json:"imageUrl"`
-	Rarity   string `json:"rarity"`
+	PullId   string            `json:"pullId,omitempty"`
+	Receipt  string            `json:"receipt,omitempty"`
+	Name     string            `json:"name"`
+	ImageUrl string            `json:"imageUrl"`
+	Rarity   string            `json:"rarity"`
+	Featured bool              `json:"featured"`
+	Pity     []PityResponse    `json:"pity,omitempty"`
+	Fairness *FairPullResponse `json:"fairness,omitempty"`
+
+	IsNew     *bool              `json:"isNew,omitempty"`
+	Duplicate *DuplicateResponse `json:"duplicate,omitempty"`
+
+	SparkPoints *int `json:"sparkPoints,omitempty"`
+}
+
+type PityResponse struct {
+	Rarity             string `json:"rarity"`
+	Counter            int    `json:"counter"`
+	Threshold          int    `json:"threshold"`
+	GuaranteedFeatured bool   `json:"guaranteedFeatured"`
 }
 
 func ToCharacterResponse(character *domain.Character, rarityName string) *CharacterResponse {
@@ -15,5 +32,19 @@ func ToCharacterResponse(character *domain.Character, rarityName string) *Charac
 		Name:     character.Name,
 		ImageUrl: character.ImageUrl,
 		Rarity:   rarityName,
+		Featured: character.Featured,
+	}
+}
+
+func ToPityResponses(rarities []domain.Rarity, pities []domain.Pity) []PityResponse {
+	var pityResponses []PityResponse
+	for i, rarity := range rarities {
+		pityResponses = append(pityResponses, PityResponse{
+			Rarity:             rarity.Name,
+			Counter:            pities[i].Counter,
+			Threshold:          rarity.PityThreshold,
+			GuaranteedFeatured: pities[i].GuaranteedFeatured,
+		})
 	}
+	return pityResponses
 }
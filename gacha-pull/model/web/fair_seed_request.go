@@ -0,0 +1,25 @@
+package web
+
+type FairSeedRotateRequest struct {
+	ClientSeed string `json:"clientSeed"`
+}
+
+// FairVerifyRequest replays pulls. ConfigVersionId and BannerId are taken from the fair pull
+// response, so the pulls are replayed with the configuration and banner they were made with.
+// Without a config version the pulls are replayed with the active one.
+type FairVerifyRequest struct {
+	ServerSeed      string                  `json:"serverSeed"`
+	ServerSeedHash  string                  `json:"serverSeedHash"`
+	ClientSeed      string                  `json:"clientSeed"`
+	Nonce           int                     `json:"nonce"`
+	PullCount       int                     `json:"pullCount"`
+	ConfigVersionId int                     `json:"configVersionId"`
+	BannerId        *int                    `json:"bannerId"`
+	Pity            []FairVerifyPityRequest `json:"pity"`
+}
+
+type FairVerifyPityRequest struct {
+	Rarity             string `json:"rarity"`
+	Counter            int    `json:"counter"`
+	GuaranteedFeatured bool   `json:"guaranteedFeatured"`
+}
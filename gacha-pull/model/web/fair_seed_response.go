@@ -0,0 +1,54 @@
+package web
+
+import "gacha-pull/model/domain"
+
+type FairSeedResponse struct {
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          int    `json:"nonce"`
+}
+
+type RevealedFairSeedResponse struct {
+	ServerSeed     string `json:"serverSeed"`
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          int    `json:"nonce"`
+}
+
+type FairSeedRotateResponse struct {
+	Revealed *RevealedFairSeedResponse `json:"revealed"`
+	Active   *FairSeedResponse         `json:"active"`
+}
+
+type FairPullResponse struct {
+	ServerSeedHash  string `json:"serverSeedHash"`
+	ClientSeed      string `json:"clientSeed"`
+	Nonce           int    `json:"nonce"`
+	PullCount       int    `json:"pullCount"`
+	PullIndex       int    `json:"pullIndex"`
+	ConfigVersionId int    `json:"configVersionId,omitempty"`
+	BannerId        *int   `json:"bannerId,omitempty"`
+}
+
+type FairVerifyResponse struct {
+	ServerSeedHash string              `json:"serverSeedHash"`
+	HashMatches    bool                `json:"hashMatches"`
+	Characters     []CharacterResponse `json:"characters"`
+}
+
+func ToFairSeedResponse(fairSeed *domain.FairSeed) *FairSeedResponse {
+	return &FairSeedResponse{
+		ServerSeedHash: fairSeed.ServerSeedHash,
+		ClientSeed:     fairSeed.ClientSeed,
+		Nonce:          fairSeed.Nonce,
+	}
+}
+
+func ToRevealedFairSeedResponse(fairSeed *domain.FairSeed) *RevealedFairSeedResponse {
+	return &RevealedFairSeedResponse{
+		ServerSeed:     fairSeed.ServerSeed,
+		ServerSeedHash: fairSeed.ServerSeedHash,
+		ClientSeed:     fairSeed.ClientSeed,
+		Nonce:          fairSeed.Nonce,
+	}
+}
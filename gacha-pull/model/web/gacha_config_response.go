@@ -0,0 +1,86 @@
+package web
+
+import "time"
+
+// GachaConfigResponse is the snapshot of the configuration of a gacha system served by
+// gacha-master. Version identifies its content, SchemaVersion its layout. VersionId and
+// VersionNumber name the published config version it was taken from.
+type GachaConfigResponse struct {
+	SchemaVersion int                            `json:"schemaVersion"`
+	Version       string                         `json:"version"`
+	VersionId     int                            `json:"versionId,omitempty"`
+	VersionNumber int                            `json:"versionNumber,omitempty"`
+	GachaSystem   GachaConfigSystemResponse      `json:"gachaSystem"`
+	Rarities      []GachaConfigRarityResponse    `json:"rarities"`
+	Characters    []GachaConfigCharacterResponse `json:"characters"`
+	Banners       []GachaConfigBannerResponse    `json:"banners"`
+	Currencies    []GachaConfigCurrencyResponse  `json:"currencies"`
+	ApiKeys       []GachaConfigApiKeyResponse    `json:"apiKeys"`
+}
+
+type GachaConfigSystemResponse struct {
+	Id                     int     `json:"id"`
+	Name                   string  `json:"name"`
+	EndpointId             string  `json:"endpointId"`
+	GuaranteedRarityId     int     `json:"guaranteedRarityId"`
+	GuaranteePullCount     int     `json:"guaranteePullCount"`
+	ProvablyFair           bool    `json:"provablyFair"`
+	Private                bool    `json:"private"`
+	EndpointRateLimit      int     `json:"endpointRateLimit"`
+	ApiKeyRateLimit        int     `json:"apiKeyRateLimit"`
+	PlayerRateLimit        int     `json:"playerRateLimit"`
+	MultiPullDiscount      float32 `json:"multiPullDiscount"`
+	MultiPullDiscountCount int     `json:"multiPullDiscountCount"`
+	Mode                   string  `json:"mode"`
+}
+
+type GachaConfigRarityResponse struct {
+	Id                  int     `json:"id"`
+	Name                string  `json:"name"`
+	Chance              float32 `json:"chance"`
+	PityThreshold       int     `json:"pityThreshold"`
+	SoftPityStart       int     `json:"softPityStart"`
+	SoftPityStep        float32 `json:"softPityStep"`
+	FeaturedChance      float32 `json:"featuredChance"`
+	DuplicateConversion string  `json:"duplicateConversion"`
+	DuplicateCurrencyId *int    `json:"duplicateCurrencyId"`
+	DuplicateShards     int     `json:"duplicateShards"`
+	MaxConstellation    int     `json:"maxConstellation"`
+}
+
+type GachaConfigCharacterResponse struct {
+	Id          int     `json:"id"`
+	Name        string  `json:"name"`
+	ImageUrl    string  `json:"imageUrl"`
+	Featured    bool    `json:"featured"`
+	Weight      float32 `json:"weight"`
+	RarityId    int     `json:"rarityId"`
+	BoxQuantity int     `json:"boxQuantity"`
+	BoxKey      bool    `json:"boxKey"`
+}
+
+type GachaConfigBannerResponse struct {
+	Id                   int       `json:"id"`
+	Name                 string    `json:"name"`
+	StartsAt             time.Time `json:"startsAt"`
+	EndsAt               time.Time `json:"endsAt"`
+	Timezone             string    `json:"timezone"`
+	FeaturedCharacterIds []int     `json:"featuredCharacterIds"`
+	SparkThreshold       int       `json:"sparkThreshold"`
+	SparkCurrencyId      *int      `json:"sparkCurrencyId"`
+	SparkConversion      int       `json:"sparkConversion"`
+}
+
+type GachaConfigCurrencyResponse struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	PullCost int    `json:"pullCost"`
+}
+
+// GachaConfigApiKeyResponse is an API key of the gacha system that is not revoked, by its hash.
+type GachaConfigApiKeyResponse struct {
+	Id        int        `json:"id"`
+	Name      string     `json:"name"`
+	KeyHash   string     `json:"keyHash"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
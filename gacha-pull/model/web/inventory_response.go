@@ -0,0 +1,27 @@
+package web
+
+import "time"
+
+type DuplicateResponse struct {
+	Copies        int    `json:"copies"`
+	Constellation int    `json:"constellation,omitempty"`
+	Currency      string `json:"currency,omitempty"`
+	Shards        int    `json:"shards,omitempty"`
+}
+
+type InventoryResponse struct {
+	PlayerId        string                  `json:"playerId"`
+	Characters      []InventoryItemResponse `json:"characters"`
+	OwnedCharacters int                     `json:"ownedCharacters"`
+	TotalCharacters int                     `json:"totalCharacters"`
+}
+
+type InventoryItemResponse struct {
+	Name            string    `json:"name"`
+	ImageUrl        string    `json:"imageUrl"`
+	Rarity          string    `json:"rarity"`
+	Featured        bool      `json:"featured"`
+	Copies          int       `json:"copies"`
+	Constellation   int       `json:"constellation"`
+	FirstObtainedAt time.Time `json:"firstObtainedAt"`
+}
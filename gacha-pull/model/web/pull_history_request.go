@@ -0,0 +1,11 @@
+package web
+
+import "time"
+
+type PullHistoryRequest struct {
+	Page   int
+	Size   int
+	Rarity string
+	From   *time.Time
+	To     *time.Time
+}
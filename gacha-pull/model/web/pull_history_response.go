@@ -0,0 +1,56 @@
+package web
+
+import (
+	"gacha-pull/model/domain"
+	"time"
+)
+
+type PullHistoryPageResponse struct {
+	Pulls      []PullHistoryResponse `json:"pulls"`
+	Page       int                   `json:"page"`
+	Size       int                   `json:"size"`
+	TotalPulls int                   `json:"totalPulls"`
+	TotalPages int                   `json:"totalPages"`
+}
+
+type PullHistoryResponse struct {
+	Id              int64          `json:"id"`
+	PullId          string         `json:"pullId"`
+	Name            string         `json:"name"`
+	Rarity          string         `json:"rarity"`
+	Featured        bool           `json:"featured"`
+	Pity            []PityResponse `json:"pity,omitempty"`
+	PulledAt        time.Time      `json:"pulledAt"`
+	ConfigVersionId *int           `json:"configVersionId,omitempty"`
+}
+
+func ToPullHistoryResponse(pullHistory *domain.PullHistory) *PullHistoryResponse {
+	pullHistoryResponse := &PullHistoryResponse{
+		Id:              pullHistory.Id,
+		PullId:          pullHistory.PullId,
+		Name:            pullHistory.CharacterName,
+		Rarity:          pullHistory.RarityName,
+		Featured:        pullHistory.Featured,
+		PulledAt:        pullHistory.PulledAt,
+		ConfigVersionId: pullHistory.ConfigVersionId,
+	}
+
+	for _, pity := range pullHistory.Pity {
+		pullHistoryResponse.Pity = append(pullHistoryResponse.Pity, PityResponse{
+			Rarity:             pity.RarityName,
+			Counter:            pity.Counter,
+			Threshold:          pity.Threshold,
+			GuaranteedFeatured: pity.GuaranteedFeatured,
+		})
+	}
+
+	return pullHistoryResponse
+}
+
+func ToPullHistoryResponses(pullHistories []domain.PullHistory) []PullHistoryResponse {
+	pullHistoryResponses := []PullHistoryResponse{}
+	for _, pullHistory := range pullHistories {
+		pullHistoryResponses = append(pullHistoryResponses, *ToPullHistoryResponse(&pullHistory))
+	}
+	return pullHistoryResponses
+}
@@ -0,0 +1,6 @@
+package web
+
+type PullRequest struct {
+	Count    int
+	Currency string
+}
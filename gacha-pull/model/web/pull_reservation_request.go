@@ -0,0 +1,8 @@
+package web
+
+import "time"
+
+type PullReservationRequest struct {
+	Count int
+	Ttl   time.Duration
+}
@@ -0,0 +1,29 @@
+package web
+
+import (
+	"gacha-pull/model/domain"
+	"time"
+)
+
+type PullReservationResponse struct {
+	Token      string              `json:"token"`
+	Status     string              `json:"status"`
+	Count      int                 `json:"count"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	ExpiresAt  time.Time           `json:"expiresAt"`
+	ResolvedAt *time.Time          `json:"resolvedAt,omitempty"`
+	Characters []CharacterResponse `json:"characters,omitempty"`
+}
+
+// ToPullReservationResponse describes a reservation without its results, those are only
+// revealed once it is confirmed.
+func ToPullReservationResponse(reservation *domain.PullReservation) *PullReservationResponse {
+	return &PullReservationResponse{
+		Token:      reservation.Token,
+		Status:     reservation.Status,
+		Count:      len(reservation.Pulls),
+		CreatedAt:  reservation.CreatedAt,
+		ExpiresAt:  reservation.ExpiresAt,
+		ResolvedAt: reservation.ResolvedAt,
+	}
+}
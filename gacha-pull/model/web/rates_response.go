@@ -0,0 +1,91 @@
+package web
+
+import (
+	"gacha-pull/selection"
+	"math"
+)
+
+// ApproximateRatesNote explains consolidated rates that are an approximation
+const ApproximateRatesNote = "Consolidated rates are approximations: the pity of each rarity is followed on its own " +
+	"and the multi-pull guarantee is left out, so the actual share of rare pulls is slightly higher."
+
+// Rates are percentages rounded to four decimals.
+type RatesResponse struct {
+	GachaSystem        string               `json:"gachaSystem"`
+	Banner             string               `json:"banner,omitempty"`
+	GuaranteedRarity   string               `json:"guaranteedRarity,omitempty"`
+	GuaranteePullCount int                  `json:"guaranteePullCount,omitempty"`
+	Approximate        bool                 `json:"approximate"`
+	Note               string               `json:"note,omitempty"`
+	Rarities           []RarityRateResponse `json:"rarities"`
+}
+
+type RarityRateResponse struct {
+	Name             string                  `json:"name"`
+	BaseRate         float64                 `json:"baseRate"`
+	ConsolidatedRate float64                 `json:"consolidatedRate"`
+	ExpectedPulls    float64                 `json:"expectedPulls"`
+	PityThreshold    int                     `json:"pityThreshold"`
+	SoftPityStart    int                     `json:"softPityStart"`
+	SoftPityStep     float32                 `json:"softPityStep"`
+	FeaturedChance   float32                 `json:"featuredChance"`
+	Characters       []CharacterRateResponse `json:"characters"`
+}
+
+type CharacterRateResponse struct {
+	Name             string  `json:"name"`
+	ImageUrl         string  `json:"imageUrl"`
+	Featured         bool    `json:"featured"`
+	BaseRate         float64 `json:"baseRate"`
+	ConsolidatedRate float64 `json:"consolidatedRate"`
+}
+
+func ToRatesResponse(pool *selection.Pool) *RatesResponse {
+	ratesResponse := &RatesResponse{
+		GachaSystem: pool.GachaSystem.Name,
+		Rarities:    []RarityRateResponse{},
+	}
+	if pool.Banner != nil {
+		ratesResponse.Banner = pool.Banner.Name
+	}
+	if len(pool.GuaranteedRarities) > 0 {
+		ratesResponse.GuaranteedRarity = pool.RarityNameMap[pool.GachaSystem.GuaranteedRarityId]
+		ratesResponse.GuaranteePullCount = pool.GachaSystem.GuaranteePullCount
+	}
+	if pool.RatesApproximate() {
+		ratesResponse.Approximate = true
+		ratesResponse.Note = ApproximateRatesNote
+	}
+
+	for _, rarityRate := range pool.Rates() {
+		rarityRateResponse := RarityRateResponse{
+			Name:             rarityRate.Rarity.Name,
+			BaseRate:         toPercentage(rarityRate.BaseRate),
+			ConsolidatedRate: toPercentage(rarityRate.ConsolidatedRate),
+			ExpectedPulls:    math.Round(rarityRate.ExpectedPulls*100) / 100,
+			PityThreshold:    rarityRate.Rarity.PityThreshold,
+			SoftPityStart:    rarityRate.Rarity.SoftPityStart,
+			SoftPityStep:     rarityRate.Rarity.SoftPityStep,
+			FeaturedChance:   rarityRate.Rarity.FeaturedChance,
+			Characters:       []CharacterRateResponse{},
+		}
+
+		for _, characterRate := range rarityRate.Characters {
+			rarityRateResponse.Characters = append(rarityRateResponse.Characters, CharacterRateResponse{
+				Name:             characterRate.Character.Name,
+				ImageUrl:         characterRate.Character.ImageUrl,
+				Featured:         characterRate.Character.Featured,
+				BaseRate:         toPercentage(characterRate.BaseRate),
+				ConsolidatedRate: toPercentage(characterRate.ConsolidatedRate),
+			})
+		}
+
+		ratesResponse.Rarities = append(ratesResponse.Rarities, rarityRateResponse)
+	}
+
+	return ratesResponse
+}
+
+func toPercentage(rate float64) float64 {
+	return math.Round(rate*1e6) / 1e4
+}
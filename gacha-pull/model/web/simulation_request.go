@@ -0,0 +1,13 @@
+package web
+
+// SimulationRequest names the target by character or by rarity name. Config is the draft of the
+// gacha system sent by gacha-master, the published configuration is simulated without it.
+type SimulationRequest struct {
+	BannerId     int                  `json:"bannerId"`
+	Character    string               `json:"character"`
+	Rarity       string               `json:"rarity"`
+	Runs         int                  `json:"runs"`
+	MaxPulls     int                  `json:"maxPulls"`
+	PullsPerStep int                  `json:"pullsPerStep"`
+	Config       *GachaConfigResponse `json:"config"`
+}
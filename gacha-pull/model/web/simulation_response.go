@@ -0,0 +1,64 @@
+package web
+
+import (
+	"gacha-pull/simulation"
+	"math"
+)
+
+type SimulationResponse struct {
+	Target       string                     `json:"target"`
+	Banner       string                     `json:"banner,omitempty"`
+	Runs         int                        `json:"runs"`
+	Obtained     int                        `json:"obtained"`
+	ObtainedRate float64                    `json:"obtainedRate"`
+	MaxPulls     int                        `json:"maxPulls"`
+	PullsPerStep int                        `json:"pullsPerStep"`
+	Pulls        SimulationPullsResponse    `json:"pulls"`
+	Histogram    []SimulationBucketResponse `json:"histogram"`
+}
+
+type SimulationPullsResponse struct {
+	Mean   float64 `json:"mean"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Median *int    `json:"median"`
+	P90    *int    `json:"p90"`
+	P99    *int    `json:"p99"`
+}
+
+type SimulationBucketResponse struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+func ToSimulationResponse(target string, banner string, request *SimulationRequest, summary *simulation.Summary) *SimulationResponse {
+	simulationResponse := &SimulationResponse{
+		Target:       target,
+		Banner:       banner,
+		Runs:         summary.Runs,
+		Obtained:     summary.Obtained,
+		ObtainedRate: toPercentage(float64(summary.Obtained) / float64(summary.Runs)),
+		MaxPulls:     request.MaxPulls,
+		PullsPerStep: request.PullsPerStep,
+		Pulls: SimulationPullsResponse{
+			Mean:   math.Round(summary.MeanPulls*100) / 100,
+			Min:    summary.MinPulls,
+			Max:    summary.MaxPulls,
+			Median: summary.MedianPulls,
+			P90:    summary.P90Pulls,
+			P99:    summary.P99Pulls,
+		},
+		Histogram: []SimulationBucketResponse{},
+	}
+
+	for _, bucket := range summary.Histogram {
+		simulationResponse.Histogram = append(simulationResponse.Histogram, SimulationBucketResponse{
+			From:  bucket.From,
+			To:    bucket.To,
+			Count: bucket.Count,
+		})
+	}
+
+	return simulationResponse
+}
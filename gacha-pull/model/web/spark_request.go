@@ -0,0 +1,5 @@
+package web
+
+type SparkRedeemRequest struct {
+	Character string `json:"character"`
+}
@@ -0,0 +1,39 @@
+package web
+
+import "gacha-pull/model/domain"
+
+type SparkResponse struct {
+	PlayerId   string              `json:"playerId"`
+	Banner     string              `json:"banner"`
+	EndsAt     string              `json:"endsAt"`
+	Points     int                 `json:"points"`
+	Threshold  int                 `json:"threshold"`
+	CanRedeem  bool                `json:"canRedeem"`
+	Characters []CharacterResponse `json:"characters"`
+}
+
+type SparkRedeemResponse struct {
+	Character *CharacterResponse `json:"character"`
+	Points    int                `json:"points"`
+}
+
+// ToSparkResponse shows the spark points of a player on a banner and the featured characters they
+// can be redeemed for.
+func ToSparkResponse(playerId string, banner *domain.Banner, endsAt string, spark *domain.Spark, characters []CharacterResponse) *SparkResponse {
+	sparkResponse := &SparkResponse{
+		PlayerId:   playerId,
+		Banner:     banner.Name,
+		EndsAt:     endsAt,
+		Threshold:  banner.SparkThreshold,
+		Characters: characters,
+	}
+	if spark != nil {
+		sparkResponse.Points = spark.Points
+	}
+	if sparkResponse.Characters == nil {
+		sparkResponse.Characters = []CharacterResponse{}
+	}
+	sparkResponse.CanRedeem = sparkResponse.Points >= banner.SparkThreshold && len(characters) > 0
+
+	return sparkResponse
+}
@@ -0,0 +1,11 @@
+package web
+
+// WalletEntryRequest credits (positive amount) or debits (negative amount) the wallet of a player.
+// gacha-master sends it for the currency granted or revoked from the dashboard.
+type WalletEntryRequest struct {
+	PlayerId   string `json:"playerId"`
+	CurrencyId int    `json:"currencyId"`
+	Amount     int64  `json:"amount"`
+	Reason     string `json:"reason"`
+	Note       string `json:"note"`
+}
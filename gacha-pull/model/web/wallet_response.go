@@ -0,0 +1,97 @@
+package web
+
+import (
+	"gacha-pull/model/domain"
+	"time"
+)
+
+type WalletResponse struct {
+	PlayerId string                  `json:"playerId"`
+	Balances []WalletBalanceResponse `json:"balances"`
+}
+
+type WalletBalanceResponse struct {
+	Currency string `json:"currency"`
+	Balance  int64  `json:"balance"`
+	PullCost int    `json:"pullCost"`
+}
+
+// ToWalletResponse lists a balance for every currency of the gacha system, including the ones the
+// player never received.
+func ToWalletResponse(playerId string, currencies []domain.Currency, wallets []domain.Wallet) *WalletResponse {
+	balanceMap := make(map[int]int64)
+	for _, wallet := range wallets {
+		balanceMap[wallet.CurrencyId] = wallet.Balance
+	}
+
+	walletResponse := &WalletResponse{
+		PlayerId: playerId,
+		Balances: []WalletBalanceResponse{},
+	}
+
+	for _, currency := range currencies {
+		walletResponse.Balances = append(walletResponse.Balances, WalletBalanceResponse{
+			Currency: currency.Name,
+			Balance:  balanceMap[currency.Id],
+			PullCost: currency.PullCost,
+		})
+	}
+
+	return walletResponse
+}
+
+// WalletLedgerResponse is the wallet of a player as gacha-master shows it on the dashboard: the
+// balance of every currency the player holds, and the latest entries of the ledger.
+type WalletLedgerResponse struct {
+	PlayerId string                      `json:"playerId"`
+	Balances []WalletAccountResponse     `json:"balances"`
+	Ledger   []WalletLedgerEntryResponse `json:"ledger"`
+}
+
+type WalletAccountResponse struct {
+	CurrencyId int   `json:"currencyId"`
+	Balance    int64 `json:"balance"`
+}
+
+type WalletLedgerEntryResponse struct {
+	Id           int64     `json:"id"`
+	CurrencyId   int       `json:"currencyId"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balanceAfter"`
+	Reason       string    `json:"reason"`
+	Note         string    `json:"note"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+func ToWalletLedgerEntryResponse(entry *domain.WalletLedgerEntry) *WalletLedgerEntryResponse {
+	return &WalletLedgerEntryResponse{
+		Id:           entry.Id,
+		CurrencyId:   entry.CurrencyId,
+		Amount:       entry.Amount,
+		BalanceAfter: entry.BalanceAfter,
+		Reason:       entry.Reason,
+		Note:         entry.Note,
+		CreatedAt:    entry.CreatedAt,
+	}
+}
+
+func ToWalletLedgerResponse(playerId string, wallets []domain.Wallet, entries []domain.WalletLedgerEntry) *WalletLedgerResponse {
+	walletLedgerResponse := &WalletLedgerResponse{
+		PlayerId: playerId,
+		Balances: []WalletAccountResponse{},
+		Ledger:   []WalletLedgerEntryResponse{},
+	}
+
+	for _, wallet := range wallets {
+		walletLedgerResponse.Balances = append(walletLedgerResponse.Balances, WalletAccountResponse{
+			CurrencyId: wallet.CurrencyId,
+			Balance:    wallet.Balance,
+		})
+	}
+
+	for _, entry := range entries {
+		walletLedgerResponse.Ledger = append(walletLedgerResponse.Ledger, *ToWalletLedgerEntryResponse(&entry))
+	}
+
+	return walletLedgerResponse
+}
@@ -0,0 +1,427 @@
+// Package poolcache keeps the configuration of gacha systems and the pools compiled from it in
+// the process, so pulls do not fetch the configuration every time. gacha-master invalidates the
+// entry of a gacha system when its configuration changes. Entries older than the ttl are
+// revalidated against gacha-master on their next read as well, which answers with the
+// configuration only when its version changed, so changes missed by an instance reach it too.
+package poolcache
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"gacha-pull/client"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/selection"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTtl is how long a cached configuration is used before it is revalidated. It bounds
+	// how long a revoked API key or a newly published config version takes to reach pulls when the
+	// invalidation from gacha-master is missed.
+	DefaultTtl = 15 * time.Second
+	// MissingTtl is how long an endpoint without a gacha system is remembered, so requests to
+	// unknown endpoints do not each call gacha-master
+	MissingTtl = 5 * time.Second
+	// MaxVersionEntries is how many past config versions are kept for replaying pulls
+	MaxVersionEntries = 256
+	// MaxMissingEntries is how many endpoints without a gacha system are remembered
+	MaxMissingEntries = 1024
+)
+
+// Config is the configuration of a gacha system pools are compiled from, together with the
+// currencies pulls are paid with and the API keys that give access. Banners are ordered by start
+// time.
+type Config struct {
+	Version     string
+	GachaSystem domain.GachaSystem
+	Rarities    []domain.Rarity
+	Characters  []domain.Character
+	Banners     []domain.Banner
+	Currencies  []domain.Currency
+	ApiKeys     []domain.ApiKey
+}
+
+// Loader reads the configuration of the gacha system behind an endpoint, nil when there is none.
+// cached is the configuration held so far, or nil. A loader returns cached itself when it is
+// still current.
+type Loader func(ctx context.Context, endpointId string, cached *Config) *Config
+
+// VersionLoader reads a published config version of the gacha system behind an endpoint, nil when
+// there is none.
+type VersionLoader func(ctx context.Context, endpointId string, versionId int) *Config
+
+// Entry is the cached configuration of a gacha system. Entries are shared between requests and
+// must not be changed.
+type Entry struct {
+	*Config
+	loadedAt   time.Time
+	refreshing bool
+	mutex      sync.Mutex
+	pools      map[int]*compiledPool
+}
+
+type compiledPool struct {
+	pool      *selection.Pool
+	err       error
+	ratesOnce sync.Once
+	rates     *web.RatesResponse
+}
+
+// Pool returns the pool compiled with banner, or without a banner when it is nil. Each pool is
+// compiled once per entry, the error of selection.NewPool is returned when it cannot be compiled.
+func (entry *Entry) Pool(banner *domain.Banner) (*selection.Pool, error) {
+	compiled := entry.compile(banner)
+	return compiled.pool, compiled.err
+}
+
+// Rates returns the disclosed rates of the pool compiled with banner. Walking the pity of every
+// rarity is costly, so the rates are computed once per pool. They are shared between requests and
+// must not be changed.
+func (entry *Entry) Rates(banner *domain.Banner) (*web.RatesResponse, error) {
+	compiled := entry.compile(banner)
+	if compiled.err != nil {
+		return nil, compiled.err
+	}
+
+	compiled.ratesOnce.Do(func() {
+		compiled.rates = web.ToRatesResponse(compiled.pool)
+	})
+	return compiled.rates, nil
+}
+
+func (entry *Entry) compile(banner *domain.Banner) *compiledPool {
+	bannerId := 0
+	if banner != nil {
+		bannerId = banner.Id
+	}
+
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	if compiled, ok := entry.pools[bannerId]; ok {
+		return compiled
+	}
+
+	pool, err := selection.NewPool(&entry.GachaSystem, banner, entry.Rarities, entry.Characters)
+	compiled := &compiledPool{pool: pool, err: err}
+	entry.pools[bannerId] = compiled
+	return compiled
+}
+
+// FindBanner returns the banner of the gacha system with the given id, nil when there is none.
+func (entry *Entry) FindBanner(bannerId int) *domain.Banner {
+	for i := range entry.Banners {
+		if entry.Banners[i].Id == bannerId {
+			return &entry.Banners[i]
+		}
+	}
+	return nil
+}
+
+// FindApiKey returns the API key with the given hash that has not expired by now, nil when there
+// is none.
+func (entry *Entry) FindApiKey(keyHash string, now time.Time) *domain.ApiKey {
+	for i := range entry.ApiKeys {
+		apiKey := &entry.ApiKeys[i]
+		if subtle.ConstantTimeCompare([]byte(apiKey.KeyHash), []byte(keyHash)) != 1 {
+			continue
+		}
+		if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+			return nil
+		}
+		return apiKey
+	}
+	return nil
+}
+
+// Cache holds an entry per endpoint, and an entry per config version that was asked for. Endpoints
+// found to have no gacha system are remembered for a short while.
+type Cache struct {
+	mutex         sync.Mutex
+	entries       map[string]*Entry
+	versions      map[versionKey]*Entry
+	missing       map[string]time.Time
+	invalidations int
+	loader        Loader
+	versionLoader VersionLoader
+	ttl           time.Duration
+	now           func() time.Time
+}
+
+type versionKey struct {
+	endpointId string
+	versionId  int
+}
+
+func NewCache(loader Loader, versionLoader VersionLoader, ttl time.Duration) *Cache {
+	return &Cache{
+		entries:       make(map[string]*Entry),
+		versions:      make(map[versionKey]*Entry),
+		missing:       make(map[string]time.Time),
+		loader:        loader,
+		versionLoader: versionLoader,
+		ttl:           ttl,
+		now:           time.Now,
+	}
+}
+
+// Find returns the entry of the gacha system behind an endpoint, loading it on a miss. While an
+// expired entry is revalidated, other readers keep getting it. nil is returned for missing gacha
+// systems, which are looked up again after MissingTtl. At most MaxMissingEntries are remembered, an
+// arbitrary one is dropped to make room.
+func (cache *Cache) Find(ctx context.Context, endpointId string) *Entry {
+	cache.mutex.Lock()
+	entry := cache.entries[endpointId]
+	if entry != nil && (entry.refreshing || cache.now().Sub(entry.loadedAt) < cache.ttl) {
+		cache.mutex.Unlock()
+		return entry
+	}
+	if missingAt, ok := cache.missing[endpointId]; ok && cache.now().Sub(missingAt) < MissingTtl {
+		cache.mutex.Unlock()
+		return nil
+	}
+
+	var cached *Config
+	if entry != nil {
+		cached = entry.Config
+		entry.refreshing = true
+		defer func() {
+			cache.mutex.Lock()
+			entry.refreshing = false
+			cache.mutex.Unlock()
+		}()
+	}
+	invalidations := cache.invalidations
+	cache.mutex.Unlock()
+
+	// A load that overlapped an invalidation may have read the configuration before the change,
+	// its result is used for this read only
+	config := cache.loader(ctx, endpointId, cached)
+	if config == nil {
+		cache.mutex.Lock()
+		if invalidations != cache.invalidations {
+			cache.mutex.Unlock()
+			return nil
+		}
+		delete(cache.entries, endpointId)
+		if len(cache.missing) >= MaxMissingEntries {
+			for dropped := range cache.missing {
+				delete(cache.missing, dropped)
+				break
+			}
+		}
+		cache.missing[endpointId] = cache.now()
+		cache.mutex.Unlock()
+		return nil
+	}
+	if entry == nil || config != cached {
+		entry = &Entry{
+			Config: config,
+			pools:  make(map[int]*compiledPool),
+		}
+	}
+
+	cache.mutex.Lock()
+	if invalidations == cache.invalidations {
+		entry.loadedAt = cache.now()
+		cache.entries[endpointId] = entry
+		delete(cache.missing, endpointId)
+	}
+	cache.mutex.Unlock()
+
+	return entry
+}
+
+// Invalidate drops the entry of the gacha system behind an endpoint, so the next read loads its
+// configuration again. Config versions never change and are kept.
+func (cache *Cache) Invalidate(endpointId string) {
+	cache.mutex.Lock()
+	delete(cache.entries, endpointId)
+	delete(cache.missing, endpointId)
+	cache.invalidations++
+	cache.mutex.Unlock()
+}
+
+// FindVersion returns the entry of a published config version of the gacha system behind an
+// endpoint, loading it on a miss. Versions never change, so their entries are not revalidated. At
+// most MaxVersionEntries are kept, an arbitrary one is dropped to make room. Missing versions are
+// not cached, nil is returned for them.
+func (cache *Cache) FindVersion(ctx context.Context, endpointId string, versionId int) *Entry {
+	key := versionKey{endpointId: endpointId, versionId: versionId}
+
+	cache.mutex.Lock()
+	entry := cache.versions[key]
+	cache.mutex.Unlock()
+	if entry != nil {
+		return entry
+	}
+
+	config := cache.versionLoader(ctx, endpointId, versionId)
+	if config == nil {
+		return nil
+	}
+	entry = &Entry{
+		Config:   config,
+		loadedAt: cache.now(),
+		pools:    make(map[int]*compiledPool),
+	}
+
+	cache.mutex.Lock()
+	if len(cache.versions) >= MaxVersionEntries {
+		for dropped := range cache.versions {
+			delete(cache.versions, dropped)
+			break
+		}
+	}
+	cache.versions[key] = entry
+	cache.mutex.Unlock()
+
+	return entry
+}
+
+// NewEntry returns an entry for a config snapshot that is not cached, like a draft sent for a
+// simulation.
+func NewEntry(configResponse *web.GachaConfigResponse) *Entry {
+	return &Entry{
+		Config: toConfig(configResponse),
+		pools:  make(map[int]*compiledPool),
+	}
+}
+
+// NewClientLoader loads configurations from gacha-master. A cached configuration is revalidated
+// by its version, and kept as long as gacha-master cannot be reached.
+func NewClientLoader(gachaMasterClient client.GachaMasterClient) Loader {
+	return func(ctx context.Context, endpointId string, cached *Config) *Config {
+		var version string
+		if cached != nil {
+			version = cached.Version
+		}
+
+		configResponse, err := gachaMasterClient.FindConfig(ctx, endpointId, version)
+		switch {
+		case err == nil:
+			return toConfig(configResponse)
+		case errors.Is(err, client.ErrNotModified):
+			return cached
+		case errors.Is(err, client.ErrGachaSystemNotFound):
+			return nil
+		case cached != nil:
+			log.Printf("Failed to revalidate gacha config of %s, keeping the cached one: %v", endpointId, err)
+			return cached
+		default:
+			log.Printf("Failed to load gacha config of %s: %v", endpointId, err)
+			panic(exception.NewBadGatewayError("Gacha configuration could not be loaded"))
+		}
+	}
+}
+
+// NewClientVersionLoader loads config versions from gacha-master.
+func NewClientVersionLoader(gachaMasterClient client.GachaMasterClient) VersionLoader {
+	return func(ctx context.Context, endpointId string, versionId int) *Config {
+		configResponse, err := gachaMasterClient.FindConfigVersion(ctx, endpointId, versionId)
+		switch {
+		case err == nil:
+			return toConfig(configResponse)
+		case errors.Is(err, client.ErrVersionNotFound):
+			return nil
+		default:
+			log.Printf("Failed to load gacha config version %d of %s: %v", versionId, endpointId, err)
+			panic(exception.NewBadGatewayError("Gacha configuration could not be loaded"))
+		}
+	}
+}
+
+func toConfig(configResponse *web.GachaConfigResponse) *Config {
+	system := configResponse.GachaSystem
+	config := &Config{
+		Version: configResponse.Version,
+		GachaSystem: domain.GachaSystem{
+			Id:                     system.Id,
+			Name:                   system.Name,
+			EndpointId:             system.EndpointId,
+			GuaranteedRarityId:     system.GuaranteedRarityId,
+			GuaranteePullCount:     system.GuaranteePullCount,
+			ProvablyFair:           system.ProvablyFair,
+			Private:                system.Private,
+			EndpointRateLimit:      system.EndpointRateLimit,
+			ApiKeyRateLimit:        system.ApiKeyRateLimit,
+			PlayerRateLimit:        system.PlayerRateLimit,
+			MultiPullDiscount:      system.MultiPullDiscount,
+			MultiPullDiscountCount: system.MultiPullDiscountCount,
+			Mode:                   system.Mode,
+			ConfigVersionId:        configResponse.VersionId,
+		},
+	}
+
+	for _, rarity := range configResponse.Rarities {
+		config.Rarities = append(config.Rarities, domain.Rarity{
+			Id:                  rarity.Id,
+			Name:                rarity.Name,
+			Chance:              rarity.Chance,
+			PityThreshold:       rarity.PityThreshold,
+			SoftPityStart:       rarity.SoftPityStart,
+			SoftPityStep:        rarity.SoftPityStep,
+			FeaturedChance:      rarity.FeaturedChance,
+			GachaSystemId:       system.Id,
+			DuplicateConversion: rarity.DuplicateConversion,
+			DuplicateCurrencyId: rarity.DuplicateCurrencyId,
+			DuplicateShards:     rarity.DuplicateShards,
+			MaxConstellation:    rarity.MaxConstellation,
+		})
+	}
+
+	for _, character := range configResponse.Characters {
+		config.Characters = append(config.Characters, domain.Character{
+			Id:            character.Id,
+			Name:          character.Name,
+			ImageUrl:      character.ImageUrl,
+			Featured:      character.Featured,
+			Weight:        character.Weight,
+			RarityId:      character.RarityId,
+			GachaSystemId: system.Id,
+			BoxQuantity:   character.BoxQuantity,
+			BoxKey:        character.BoxKey,
+		})
+	}
+
+	for _, currency := range configResponse.Currencies {
+		config.Currencies = append(config.Currencies, domain.Currency{
+			Id:            currency.Id,
+			Name:          currency.Name,
+			PullCost:      currency.PullCost,
+			GachaSystemId: system.Id,
+		})
+	}
+
+	for _, apiKey := range configResponse.ApiKeys {
+		config.ApiKeys = append(config.ApiKeys, domain.ApiKey{
+			Id:            apiKey.Id,
+			GachaSystemId: system.Id,
+			Name:          apiKey.Name,
+			KeyHash:       apiKey.KeyHash,
+			ExpiresAt:     apiKey.ExpiresAt,
+		})
+	}
+
+	for _, banner := range configResponse.Banners {
+		config.Banners = append(config.Banners, domain.Banner{
+			Id:                   banner.Id,
+			GachaSystemId:        system.Id,
+			Name:                 banner.Name,
+			StartsAt:             banner.StartsAt,
+			EndsAt:               banner.EndsAt,
+			Timezone:             banner.Timezone,
+			FeaturedCharacterIds: banner.FeaturedCharacterIds,
+			SparkThreshold:       banner.SparkThreshold,
+			SparkCurrencyId:      banner.SparkCurrencyId,
+			SparkConversion:      banner.SparkConversion,
+		})
+	}
+
+	return config
+}
@@ -0,0 +1,109 @@
+package poolcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// countingLoader serves configs and counts the loads per endpoint. An endpoint without a config
+// has no gacha system.
+type countingLoader struct {
+	configs map[string]*Config
+	loads   map[string]int
+	onLoad  func()
+}
+
+func (loader *countingLoader) load(ctx context.Context, endpointId string, cached *Config) *Config {
+	loader.loads[endpointId]++
+	if loader.onLoad != nil {
+		loader.onLoad()
+	}
+	return loader.configs[endpointId]
+}
+
+func TestCacheFind(t *testing.T) {
+	type step struct {
+		advance    time.Duration
+		invalidate bool
+		found      bool
+		loads      int
+	}
+
+	tests := []struct {
+		name       string
+		endpointId string
+		steps      []step
+	}{
+		{"entry is reused within the ttl", "known", []step{
+			{found: true, loads: 1},
+			{advance: DefaultTtl - time.Second, found: true, loads: 1},
+			{advance: time.Second, found: true, loads: 2},
+		}},
+		{"invalidated entry is loaded again", "known", []step{
+			{found: true, loads: 1},
+			{invalidate: true, found: true, loads: 2},
+			{found: true, loads: 2},
+		}},
+		{"missing endpoint is remembered", "unknown", []step{
+			{found: false, loads: 1},
+			{advance: MissingTtl - time.Second, found: false, loads: 1},
+			{advance: time.Second, found: false, loads: 2},
+		}},
+		{"invalidated missing endpoint is looked up again", "unknown", []step{
+			{found: false, loads: 1},
+			{invalidate: true, found: false, loads: 2},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			loader := &countingLoader{
+				configs: map[string]*Config{"known": {Version: "1"}},
+				loads:   make(map[string]int),
+			}
+			now := time.Now()
+			cache := NewCache(loader.load, nil, DefaultTtl)
+			cache.now = func() time.Time { return now }
+
+			for i, step := range test.steps {
+				now = now.Add(step.advance)
+				if step.invalidate {
+					cache.Invalidate(test.endpointId)
+				}
+
+				entry := cache.Find(context.Background(), test.endpointId)
+				if found := entry != nil; found != step.found {
+					t.Errorf("step %d: found %v, expected %v", i, found, step.found)
+				}
+				if loads := loader.loads[test.endpointId]; loads != step.loads {
+					t.Errorf("step %d: %d load(s), expected %d", i, loads, step.loads)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheFindKeepsNoLoadOverlappingInvalidation(t *testing.T) {
+	var cache *Cache
+	loader := &countingLoader{
+		configs: map[string]*Config{"known": {Version: "1"}},
+		loads:   make(map[string]int),
+	}
+	loader.onLoad = func() {
+		// The configuration changes while the first load is on its way
+		if loader.loads["known"] == 1 {
+			cache.Invalidate("known")
+		}
+	}
+	cache = NewCache(loader.load, nil, DefaultTtl)
+
+	if entry := cache.Find(context.Background(), "known"); entry == nil {
+		t.Fatal("expected the overlapping load to be returned")
+	}
+	cache.Find(context.Background(), "known")
+
+	if loads := loader.loads["known"]; loads != 2 {
+		t.Errorf("%d load(s), expected the overlapping load not to be kept", loads)
+	}
+}
@@ -0,0 +1,107 @@
+// Package ratelimit implements in-memory token buckets. Buckets live in the process, so every
+// gacha-pull instance enforces the limits on its own share of the traffic.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTimeout is how long an untouched bucket is kept. A bucket left alone that long has
+// refilled completely, so dropping it does not change any decision.
+const idleBucketTimeout = 10 * time.Minute
+
+// Limit allows PerMinute requests per minute for a key, with bursts of up to PerMinute requests.
+// A PerMinute of zero means unlimited.
+type Limit struct {
+	Key       string
+	PerMinute int
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+type Limiter struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	sweptAt   time.Time
+	sweepEach time.Duration
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets:   make(map[string]*bucket),
+		now:       time.Now,
+		sweepEach: idleBucketTimeout,
+	}
+}
+
+// Allow takes one token from the bucket of every limit. Tokens are only taken when every bucket
+// has one, otherwise Allow reports false and how long to wait until every bucket has refilled
+// enough to let the request through.
+func (limiter *Limiter) Allow(limits ...Limit) (bool, time.Duration) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := limiter.now()
+	limiter.sweep(now)
+
+	var retryAfter time.Duration
+	for _, limit := range limits {
+		if limit.PerMinute <= 0 {
+			continue
+		}
+
+		bucket := limiter.refill(limit, now)
+		if bucket.tokens < 1 {
+			perSecond := float64(limit.PerMinute) / 60
+			wait := time.Duration(math.Ceil((1-bucket.tokens)/perSecond*1000)) * time.Millisecond
+			retryAfter = max(retryAfter, wait)
+		}
+	}
+
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+
+	for _, limit := range limits {
+		if limit.PerMinute > 0 {
+			limiter.buckets[limit.Key].tokens--
+		}
+	}
+
+	return true, 0
+}
+
+func (limiter *Limiter) refill(limit Limit, now time.Time) *bucket {
+	capacity := float64(limit.PerMinute)
+
+	existing, ok := limiter.buckets[limit.Key]
+	if !ok {
+		existing = &bucket{tokens: capacity, updatedAt: now}
+		limiter.buckets[limit.Key] = existing
+		return existing
+	}
+
+	elapsed := now.Sub(existing.updatedAt).Seconds()
+	existing.tokens = math.Min(capacity, existing.tokens+elapsed*capacity/60)
+	existing.updatedAt = now
+	return existing
+}
+
+func (limiter *Limiter) sweep(now time.Time) {
+	if now.Sub(limiter.sweptAt) < limiter.sweepEach {
+		return
+	}
+
+	for key, bucket := range limiter.buckets {
+		if now.Sub(bucket.updatedAt) > idleBucketTimeout {
+			delete(limiter.buckets, key)
+		}
+	}
+	limiter.sweptAt = now
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	type step struct {
+		advance    time.Duration
+		limits     []Limit
+		allowed    bool
+		retryAfter time.Duration
+	}
+
+	endpoint := Limit{Key: "endpoint", PerMinute: 2}
+	player := Limit{Key: "player", PerMinute: 1}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"bursts up to the limit", []step{
+			{limits: []Limit{endpoint}, allowed: true},
+			{limits: []Limit{endpoint}, allowed: true},
+			{limits: []Limit{endpoint}, allowed: false, retryAfter: 30 * time.Second},
+		}},
+		{"refills over time", []step{
+			{limits: []Limit{player}, allowed: true},
+			{advance: 30 * time.Second, limits: []Limit{player}, allowed: false, retryAfter: 30 * time.Second},
+			{advance: 30 * time.Second, limits: []Limit{player}, allowed: true},
+		}},
+		{"zero is unlimited", []step{
+			{limits: []Limit{{Key: "unlimited"}}, allowed: true},
+			{limits: []Limit{{Key: "unlimited"}}, allowed: true},
+			{limits: []Limit{{Key: "unlimited"}}, allowed: true},
+		}},
+		{"waits for the slowest bucket", []step{
+			{limits: []Limit{endpoint, player}, allowed: true},
+			{limits: []Limit{endpoint, player}, allowed: false, retryAfter: time.Minute},
+		}},
+		{"denied request takes no token from the other buckets", []step{
+			{limits: []Limit{player}, allowed: true},
+			{limits: []Limit{endpoint, player}, allowed: false, retryAfter: time.Minute},
+			{limits: []Limit{endpoint}, allowed: true},
+			{limits: []Limit{endpoint}, allowed: true},
+		}},
+		{"keys are limited apart", []step{
+			{limits: []Limit{{Key: "a", PerMinute: 1}}, allowed: true},
+			{limits: []Limit{{Key: "b", PerMinute: 1}}, allowed: true},
+			{limits: []Limit{{Key: "a", PerMinute: 1}}, allowed: false, retryAfter: time.Minute},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Now()
+			limiter := NewLimiter()
+			limiter.now = func() time.Time { return now }
+
+			for i, step := range test.steps {
+				now = now.Add(step.advance)
+
+				allowed, retryAfter := limiter.Allow(step.limits...)
+				if allowed != step.allowed || retryAfter != step.retryAfter {
+					t.Errorf("step %d: allowed %v after %v, expected %v after %v", i, allowed, retryAfter, step.allowed, step.retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterSweepsIdleBuckets(t *testing.T) {
+	now := time.Now()
+	limiter := NewLimiter()
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow(Limit{Key: "idle", PerMinute: 1})
+	now = now.Add(idleBucketTimeout + time.Second)
+	limiter.Allow(Limit{Key: "active", PerMinute: 1})
+
+	if _, ok := limiter.buckets["idle"]; ok {
+		t.Error("idle bucket was kept")
+	}
+	if _, ok := limiter.buckets["active"]; !ok {
+		t.Error("active bucket was dropped")
+	}
+}
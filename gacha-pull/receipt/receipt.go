@@ -0,0 +1,203 @@
+// Package receipt signs pull results so a game server can check, offline, that a result relayed
+// by a game client really came from gacha-pull.
+//
+// A receipt is a compact JWS signed with Ed25519 (alg EdDSA). Its payload names the pull, the gacha
+// system endpoint, the player, the pulled character and rarity and when the pull was made. Game
+// servers fetch the public keys once from the JWKS endpoint and check receipts with Verify, using
+// nothing but this package.
+package receipt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	Algorithm = "EdDSA"
+	KeyType   = "OKP"
+	Curve     = "Ed25519"
+)
+
+var (
+	ErrInvalidReceipt = errors.New("invalid receipt")
+	ErrUnknownKey     = errors.New("receipt is signed with an unknown key")
+	ErrBadSignature   = errors.New("receipt signature does not match")
+)
+
+// Claims is the payload of a receipt. IssuedAt is the pull time in Unix seconds.
+type Claims struct {
+	PullId      string `json:"pullId"`
+	EndpointId  string `json:"endpointId"`
+	PlayerId    string `json:"playerId,omitempty"`
+	CharacterId int    `json:"characterId"`
+	RarityId    int    `json:"rarityId"`
+	IssuedAt    int64  `json:"iat"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyId     string `json:"kid,omitempty"`
+}
+
+// JWK is the public part of a signing key, as published in a JWKS.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	KeyId     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Signer signs receipts with an Ed25519 private key.
+type Signer struct {
+	privateKey ed25519.PrivateKey
+	keyId      string
+}
+
+func NewSigner(privateKey ed25519.PrivateKey) *Signer {
+	return &Signer{
+		privateKey: privateKey,
+		keyId:      KeyId(privateKey.Public().(ed25519.PublicKey)),
+	}
+}
+
+// NewSignerFromSeed builds a Signer from a base64 encoded 32 byte Ed25519 seed.
+func NewSignerFromSeed(encodedSeed string) (*Signer, error) {
+	seed, err := base64.StdEncoding.DecodeString(encodedSeed)
+	if err != nil {
+		seed, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(encodedSeed, "="))
+	}
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("receipt signing key must be a base64 encoded %d byte Ed25519 seed", ed25519.SeedSize)
+	}
+
+	return NewSigner(ed25519.NewKeyFromSeed(seed)), nil
+}
+
+// GenerateSigner builds a Signer with a new random key.
+func GenerateSigner() *Signer {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return NewSigner(privateKey)
+}
+
+// KeyId derives the key id of a public key from its SHA-256 hash, so every instance signing with
+// the same key publishes the same id.
+func KeyId(publicKey ed25519.PublicKey) string {
+	hash := sha256.Sum256(publicKey)
+	return hex.EncodeToString(hash[:8])
+}
+
+// NewPullId returns a random id for a pull, formatted as a version 4 UUID.
+func NewPullId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
+
+// Sign returns the receipt of a pull as a compact JWS.
+func (signer *Signer) Sign(claims *Claims) string {
+	encodedHeader := encodeSegment(header{Algorithm: Algorithm, Type: "JWT", KeyId: signer.keyId})
+	signingInput := encodedHeader + "." + encodeSegment(claims)
+
+	signature := ed25519.Sign(signer.privateKey, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// JWKS returns the public key receipts are signed with.
+func (signer *Signer) JWKS() *JWKS {
+	return &JWKS{
+		Keys: []JWK{{
+			KeyType:   KeyType,
+			Curve:     Curve,
+			X:         base64.RawURLEncoding.EncodeToString(signer.privateKey.Public().(ed25519.PublicKey)),
+			KeyId:     signer.keyId,
+			Algorithm: Algorithm,
+			Use:       "sig",
+		}},
+	}
+}
+
+// Verify checks the signature of a receipt against the keys of a JWKS and returns its claims.
+func Verify(receipt string, jwks *JWKS) (*Claims, error) {
+	segments := strings.Split(receipt, ".")
+	if len(segments) != 3 {
+		return nil, ErrInvalidReceipt
+	}
+
+	var receiptHeader header
+	if err := decodeSegment(segments[0], &receiptHeader); err != nil || receiptHeader.Algorithm != Algorithm {
+		return nil, ErrInvalidReceipt
+	}
+
+	publicKey := findPublicKey(jwks, receiptHeader.KeyId)
+	if publicKey == nil {
+		return nil, ErrUnknownKey
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(segments[2])
+	if err != nil {
+		return nil, ErrInvalidReceipt
+	}
+	if !ed25519.Verify(publicKey, []byte(segments[0]+"."+segments[1]), signature) {
+		return nil, ErrBadSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(segments[1], &claims); err != nil {
+		return nil, ErrInvalidReceipt
+	}
+
+	return &claims, nil
+}
+
+func findPublicKey(jwks *JWKS, keyId string) ed25519.PublicKey {
+	for _, key := range jwks.Keys {
+		if key.KeyType != KeyType || key.Curve != Curve || key.KeyId != keyId {
+			continue
+		}
+
+		publicKey, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil || len(publicKey) != ed25519.PublicKeySize {
+			continue
+		}
+		return publicKey
+	}
+	return nil
+}
+
+func encodeSegment(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, value)
+}
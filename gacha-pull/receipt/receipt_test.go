@@ -0,0 +1,135 @@
+package receipt
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTestSigner(t *testing.T) *Signer {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+
+	signer, err := NewSignerFromSeed(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatalf("NewSignerFromSeed failed: %v", err)
+	}
+	return signer
+}
+
+func newTestClaims() *Claims {
+	return &Claims{
+		PullId:      "0f8fad5b-d9cb-469f-a165-70867728950e",
+		EndpointId:  "endpoint",
+		PlayerId:    "player",
+		CharacterId: 7,
+		RarityId:    2,
+		IssuedAt:    1700000000,
+	}
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	signer := newTestSigner(t)
+	claims := newTestClaims()
+
+	verified, err := Verify(signer.Sign(claims), signer.JWKS())
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if !reflect.DeepEqual(verified, claims) {
+		t.Errorf("Verify returned %+v, expected %+v", verified, claims)
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	signer := newTestSigner(t)
+
+	jwks := signer.JWKS()
+	if len(jwks.Keys) != 1 {
+		t.Fatalf("JWKS holds %d keys, expected 1", len(jwks.Keys))
+	}
+
+	key := jwks.Keys[0]
+	if key.KeyType != KeyType || key.Curve != Curve || key.Algorithm != Algorithm || key.Use != "sig" {
+		t.Errorf("unexpected key parameters %+v", key)
+	}
+
+	publicKey, err := base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		t.Fatalf("x is not a base64url encoded Ed25519 public key: %q", key.X)
+	}
+	if key.KeyId != KeyId(publicKey) {
+		t.Errorf("key id %s does not match the public key, expected %s", key.KeyId, KeyId(publicKey))
+	}
+
+	// Every instance loading the same seed must publish the same key id
+	if otherKeyId := newTestSigner(t).JWKS().Keys[0].KeyId; otherKeyId != key.KeyId {
+		t.Errorf("the same seed gave key ids %s and %s", key.KeyId, otherKeyId)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	signer := newTestSigner(t)
+	receipt := signer.Sign(newTestClaims())
+	segments := strings.Split(receipt, ".")
+
+	tamperedClaims := newTestClaims()
+	tamperedClaims.CharacterId = 8
+	tamperedPayload := segments[0] + "." + encodeSegment(tamperedClaims) + "." + segments[2]
+
+	signature, _ := base64.RawURLEncoding.DecodeString(segments[2])
+	signature[0] ^= 1
+	tamperedSignature := segments[0] + "." + segments[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+
+	otherKeyHeader := encodeSegment(header{Algorithm: Algorithm, Type: "JWT", KeyId: "unknown"})
+	otherAlgorithmHeader := encodeSegment(header{Algorithm: "none", Type: "JWT", KeyId: signer.keyId})
+
+	tests := []struct {
+		name     string
+		receipt  string
+		jwks     *JWKS
+		expected error
+	}{
+		{"tampered payload", tamperedPayload, signer.JWKS(), ErrBadSignature},
+		{"tampered signature", tamperedSignature, signer.JWKS(), ErrBadSignature},
+		{"signed by another key", GenerateSigner().Sign(newTestClaims()), signer.JWKS(), ErrUnknownKey},
+		{"unknown key id", otherKeyHeader + "." + segments[1] + "." + segments[2], signer.JWKS(), ErrUnknownKey},
+		{"empty key set", receipt, &JWKS{}, ErrUnknownKey},
+		{"other algorithm", otherAlgorithmHeader + "." + segments[1] + "." + segments[2], signer.JWKS(), ErrInvalidReceipt},
+		{"missing signature", segments[0] + "." + segments[1], signer.JWKS(), ErrInvalidReceipt},
+		{"not base64", segments[0] + "." + segments[1] + ".!!", signer.JWKS(), ErrInvalidReceipt},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := Verify(test.receipt, test.jwks); !errors.Is(err, test.expected) {
+				t.Errorf("Verify returned %v, expected %v", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewSignerFromSeedRejectsBadSeeds(t *testing.T) {
+	tests := []struct {
+		name string
+		seed string
+	}{
+		{"empty", ""},
+		{"not base64", "not a seed!"},
+		{"too short", base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize-1))},
+		{"too long", base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize+1))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewSignerFromSeed(test.seed); err == nil {
+				t.Error("NewSignerFromSeed accepted a bad seed")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type BoxRepository interface {
+	FindOrCreate(ctx context.Context, gachaSystemId int, playerId string) *domain.Box
+	SaveDraws(ctx context.Context, box *domain.Box, characterIds []int) bool
+	Reset(ctx context.Context, box *domain.Box) bool
+}
+
+type BoxRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewBoxRepository(dbpool *pgxpool.Pool) BoxRepository {
+	return &BoxRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+// FindOrCreate returns the box of a player with the copies drawn from it, handing out a full box
+// the first time.
+func (repository *BoxRepositoryImpl) FindOrCreate(ctx context.Context, gachaSystemId int, playerId string) *domain.Box {
+	createQuery := `INSERT INTO box (gacha_system_id, player_id)
+				VALUES ($1, $2)
+				ON CONFLICT (gacha_system_id, player_id) DO NOTHING`
+	boxQuery := `SELECT gacha_system_id, player_id, round, version, updated_at
+				FROM box
+				WHERE gacha_system_id = $1 AND player_id = $2`
+	drawQuery := `SELECT character_id, drawn
+				FROM box_draw
+				WHERE gacha_system_id = $1 AND player_id = $2`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, createQuery, gachaSystemId, playerId)
+	helper.PanicIfError(err, "Failed to create box")
+
+	box := domain.Box{Drawn: make(map[int]int)}
+	err = tx.QueryRow(ctx, boxQuery, gachaSystemId, playerId).Scan(&box.GachaSystemId, &box.PlayerId, &box.Round, &box.Version, &box.UpdatedAt)
+	helper.PanicIfError(err, "Failed to find box")
+
+	rows, err := tx.Query(ctx, drawQuery, gachaSystemId, playerId)
+	if err != nil {
+		log.Printf("Error while querying box draws: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var characterId, drawn int
+		err = rows.Scan(&characterId, &drawn)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		box.Drawn[characterId] = drawn
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning box draws: %v", err)
+	}
+
+	return &box
+}
+
+// SaveDraws takes one copy of every character out of the box. Nothing is stored and false is
+// returned when the box was drawn from or reset since it was read.
+func (repository *BoxRepositoryImpl) SaveDraws(ctx context.Context, box *domain.Box, characterIds []int) bool {
+	versionQuery := `UPDATE box
+				SET version = version + 1, updated_at = CURRENT_TIMESTAMP
+				WHERE gacha_system_id = $1 AND player_id = $2 AND version = $3`
+	drawQuery := `INSERT INTO box_draw (gacha_system_id, player_id, character_id, drawn)
+				VALUES ($1, $2, $3, 1)
+				ON CONFLICT (gacha_system_id, player_id, character_id)
+				DO UPDATE SET drawn = box_draw.drawn + 1`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	result, err := tx.Exec(ctx, versionQuery, box.GachaSystemId, box.PlayerId, box.Version)
+	helper.PanicIfError(err, "Failed to update box")
+	if result.RowsAffected() == 0 {
+		return false
+	}
+
+	for _, characterId := range characterIds {
+		_, err = tx.Exec(ctx, drawQuery, box.GachaSystemId, box.PlayerId, characterId)
+		helper.PanicIfError(err, "Failed to draw from box")
+	}
+
+	box.Version++
+	return true
+}
+
+// Reset refills the box and starts its next round. Like SaveDraws it returns false when the box
+// changed since it was read.
+func (repository *BoxRepositoryImpl) Reset(ctx context.Context, box *domain.Box) bool {
+	versionQuery := `UPDATE box
+				SET round = round + 1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+				WHERE gacha_system_id = $1 AND player_id = $2 AND version = $3`
+	drawQuery := `DELETE FROM box_draw WHERE gacha_system_id = $1 AND player_id = $2`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	result, err := tx.Exec(ctx, versionQuery, box.GachaSystemId, box.PlayerId, box.Version)
+	helper.PanicIfError(err, "Failed to reset box")
+	if result.RowsAffected() == 0 {
+		return false
+	}
+
+	_, err = tx.Exec(ctx, drawQuery, box.GachaSystemId, box.PlayerId)
+	helper.PanicIfError(err, "Failed to reset box")
+
+	box.Round++
+	box.Version++
+	box.Drawn = make(map[int]int)
+	return true
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FairSeedRepository interface {
+	FindActiveByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) *domain.FairSeed
+	Save(ctx context.Context, fairSeed *domain.FairSeed) *domain.FairSeed
+	UseNonce(ctx context.Context, fairSeedId int) (int, bool)
+	Rotate(ctx context.Context, fairSeedId int, nextFairSeed *domain.FairSeed) bool
+}
+
+type FairSeedRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewFairSeedRepository(dbpool *pgxpool.Pool) FairSeedRepository {
+	return &FairSeedRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+const findActiveFairSeedQuery = `SELECT id, gacha_system_id, player_id, server_seed, server_seed_hash, client_seed, nonce
+			FROM fair_seed
+			WHERE gacha_system_id = $1 AND player_id = $2 AND revealed_at IS NULL`
+
+func (repository *FairSeedRepositoryImpl) FindActiveByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) *domain.FairSeed {
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getFairSeedFromRow(tx.QueryRow(ctx, findActiveFairSeedQuery, gachaSystemId, playerId))
+}
+
+// Save stores a new active seed pair and returns the active one, which is the seed pair created
+// by a concurrent request if that one won the race.
+func (repository *FairSeedRepositoryImpl) Save(ctx context.Context, fairSeed *domain.FairSeed) *domain.FairSeed {
+	query := `INSERT INTO fair_seed (gacha_system_id, player_id, server_seed, server_seed_hash, client_seed)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (gacha_system_id, player_id) WHERE revealed_at IS NULL DO NOTHING`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, fairSeed.GachaSystemId, fairSeed.PlayerId, fairSeed.ServerSeed, fairSeed.ServerSeedHash, fairSeed.ClientSeed)
+	helper.PanicIfError(err, "Failed to save fair seed")
+
+	return getFairSeedFromRow(tx.QueryRow(ctx, findActiveFairSeedQuery, fairSeed.GachaSystemId, fairSeed.PlayerId))
+}
+
+// UseNonce reserves the next nonce of an active seed pair. It reports false when the seed pair
+// has been revealed in the meantime.
+func (repository *FairSeedRepositoryImpl) UseNonce(ctx context.Context, fairSeedId int) (int, bool) {
+	query := `UPDATE fair_seed SET nonce = nonce + 1
+			WHERE id = $1 AND revealed_at IS NULL
+			RETURNING nonce - 1`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	var nonce int
+	err = tx.QueryRow(ctx, query, fairSeedId).Scan(&nonce)
+	if err != nil {
+		return 0, false
+	}
+
+	return nonce, true
+}
+
+// Rotate reveals an active seed pair and replaces it with the next one. It reports false when the
+// seed pair has already been revealed.
+func (repository *FairSeedRepositoryImpl) Rotate(ctx context.Context, fairSeedId int, nextFairSeed *domain.FairSeed) bool {
+	revealQuery := `UPDATE fair_seed SET revealed_at = CURRENT_TIMESTAMP WHERE id = $1 AND revealed_at IS NULL`
+	insertQuery := `INSERT INTO fair_seed (gacha_system_id, player_id, server_seed, server_seed_hash, client_seed)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING id`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	result, err := tx.Exec(ctx, revealQuery, fairSeedId)
+	helper.PanicIfError(err, "Failed to reveal fair seed")
+	if result.RowsAffected() == 0 {
+		return false
+	}
+
+	err = tx.QueryRow(ctx, insertQuery, nextFairSeed.GachaSystemId, nextFairSeed.PlayerId, nextFairSeed.ServerSeed,
+		nextFairSeed.ServerSeedHash, nextFairSeed.ClientSeed).Scan(&nextFairSeed.Id)
+	helper.PanicIfError(err, "Failed to save fair seed")
+
+	return true
+}
+
+func getFairSeedFromRow(row pgx.Row) *domain.FairSeed {
+	var fairSeed domain.FairSeed
+
+	err := row.Scan(&fairSeed.Id, &fairSeed.GachaSystemId, &fairSeed.PlayerId, &fairSeed.ServerSeed,
+		&fairSeed.ServerSeedHash, &fairSeed.ClientSeed, &fairSeed.Nonce)
+	if err != nil {
+		return nil
+	}
+
+	return &fairSeed
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyKeyRepository interface {
+	Claim(ctx context.Context, idempotencyKey *domain.IdempotencyKey) *domain.IdempotencyKey
+	SaveResponse(ctx context.Context, idempotencyKey *domain.IdempotencyKey)
+	Release(ctx context.Context, idempotencyKey *domain.IdempotencyKey)
+	DeleteExpired(ctx context.Context) int64
+}
+
+type IdempotencyKeyRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewIdempotencyKeyRepository(dbpool *pgxpool.Pool) IdempotencyKeyRepository {
+	return &IdempotencyKeyRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+// Claim stores a new idempotency key, taking over an expired one. It returns nil when the key was
+// claimed, or the stored key when another request already holds it.
+func (repository *IdempotencyKeyRepositoryImpl) Claim(ctx context.Context, idempotencyKey *domain.IdempotencyKey) *domain.IdempotencyKey {
+	claimQuery := `INSERT INTO idempotency_key (gacha_system_id, scope, key, request_hash, expires_at)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (gacha_system_id, scope, key)
+				DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = NULL, response_body = NULL,
+					created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at
+				WHERE idempotency_key.expires_at <= CURRENT_TIMESTAMP
+				RETURNING created_at`
+	findQuery := `SELECT gacha_system_id, scope, key, request_hash, COALESCE(status_code, 0), response_body, created_at, expires_at
+				FROM idempotency_key
+				WHERE gacha_system_id = $1 AND scope = $2 AND key = $3`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, claimQuery, idempotencyKey.GachaSystemId, idempotencyKey.Scope, idempotencyKey.Key,
+		idempotencyKey.RequestHash, idempotencyKey.ExpiresAt).Scan(&idempotencyKey.CreatedAt)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		helper.PanicIfError(err, "Failed to claim idempotency key")
+	}
+
+	var storedKey domain.IdempotencyKey
+	err = tx.QueryRow(ctx, findQuery, idempotencyKey.GachaSystemId, idempotencyKey.Scope, idempotencyKey.Key).Scan(
+		&storedKey.GachaSystemId, &storedKey.Scope, &storedKey.Key, &storedKey.RequestHash, &storedKey.StatusCode,
+		&storedKey.ResponseBody, &storedKey.CreatedAt, &storedKey.ExpiresAt)
+	helper.PanicIfError(err, "Failed to find idempotency key")
+
+	return &storedKey
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) SaveResponse(ctx context.Context, idempotencyKey *domain.IdempotencyKey) {
+	query := `UPDATE idempotency_key SET status_code = $4, response_body = $5
+				WHERE gacha_system_id = $1 AND scope = $2 AND key = $3`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, idempotencyKey.GachaSystemId, idempotencyKey.Scope, idempotencyKey.Key,
+		idempotencyKey.StatusCode, idempotencyKey.ResponseBody)
+	helper.PanicIfError(err, "Failed to save idempotent response")
+}
+
+// Release gives up a claimed key whose request failed, so the request can be retried with it.
+func (repository *IdempotencyKeyRepositoryImpl) Release(ctx context.Context, idempotencyKey *domain.IdempotencyKey) {
+	query := `DELETE FROM idempotency_key
+				WHERE gacha_system_id = $1 AND scope = $2 AND key = $3 AND status_code IS NULL`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, idempotencyKey.GachaSystemId, idempotencyKey.Scope, idempotencyKey.Key)
+	helper.PanicIfError(err, "Failed to release idempotency key")
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) DeleteExpired(ctx context.Context) int64 {
+	query := `DELETE FROM idempotency_key WHERE expires_at <= CURRENT_TIMESTAMP`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	result, err := tx.Exec(ctx, query)
+	helper.PanicIfError(err, "Failed to delete expired idempotency keys")
+
+	return result.RowsAffected()
+}
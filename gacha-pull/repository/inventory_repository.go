@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type InventoryRepository interface {
+	FindAllByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) []domain.InventoryItem
+	AddAll(ctx context.Context, gachaSystemId int, playerId string, characterIds []int) []int
+}
+
+type InventoryRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewInventoryRepository(dbpool *pgxpool.Pool) InventoryRepository {
+	return &InventoryRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *InventoryRepositoryImpl) FindAllByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) []domain.InventoryItem {
+	query := `SELECT gacha_system_id, player_id, character_id, copies, first_obtained_at, updated_at
+				FROM inventory
+				WHERE gacha_system_id = $1 AND player_id = $2
+				ORDER BY first_obtained_at, character_id`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId, playerId)
+	if err != nil {
+		log.Printf("Error while querying inventory: %v", err)
+	}
+	defer rows.Close()
+
+	var inventoryItems []domain.InventoryItem
+	for rows.Next() {
+		var inventoryItem domain.InventoryItem
+		err = rows.Scan(&inventoryItem.GachaSystemId, &inventoryItem.PlayerId, &inventoryItem.CharacterId, &inventoryItem.Copies,
+			&inventoryItem.FirstObtainedAt, &inventoryItem.UpdatedAt)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		inventoryItems = append(inventoryItems, inventoryItem)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning inventory: %v", err)
+	}
+
+	return inventoryItems
+}
+
+// AddAll adds one copy of every character to the inventory of a player, in order, and returns
+// how many copies the player owns right after each one was added.
+func (repository *InventoryRepositoryImpl) AddAll(ctx context.Context, gachaSystemId int, playerId string, characterIds []int) []int {
+	query := `INSERT INTO inventory (gacha_system_id, player_id, character_id)
+				VALUES ($1, $2, $3)
+				ON CONFLICT (gacha_system_id, player_id, character_id)
+				DO UPDATE SET copies = inventory.copies + 1, updated_at = CURRENT_TIMESTAMP
+				RETURNING copies`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	copies := make([]int, len(characterIds))
+	for i, characterId := range characterIds {
+		err = tx.QueryRow(ctx, query, gachaSystemId, playerId, characterId).Scan(&copies[i])
+		helper.PanicIfError(err, "Failed to add character to inventory")
+	}
+
+	return copies
+}
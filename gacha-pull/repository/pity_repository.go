@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type PityRepository interface {
+	// LockByGachaSystemIdAndPlayerId holds a per-player lock until the surrounding transaction ends,
+	// so pity is read and written by one pull at a time.
+	LockByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string)
+	FindAllByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) []domain.Pity
+	SaveAll(ctx context.Context, pities []domain.Pity)
+}
+
+type PityRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewPityRepository(dbpool *pgxpool.Pool) PityRepository {
+	return &PityRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *PityRepositoryImpl) LockByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) {
+	query := `SELECT pg_advisory_xact_lock($1, hashtext($2))`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	_, err = tx.Exec(ctx, query, gachaSystemId, playerId)
+	helper.PanicIfError(err, "Failed to lock pity")
+}
+
+func (repository *PityRepositoryImpl) FindAllByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) []domain.Pity {
+	query := `SELECT gacha_system_id, player_id, rarity_id, counter, guaranteed_featured FROM pity WHERE gacha_system_id = $1 AND player_id = $2`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId, playerId)
+	if err != nil {
+		log.Printf("Error while querying pity: %v", err)
+	}
+	defer rows.Close()
+
+	var pities []domain.Pity
+	for rows.Next() {
+		var pity domain.Pity
+		err = rows.Scan(&pity.GachaSystemId, &pity.PlayerId, &pity.RarityId, &pity.Counter, &pity.GuaranteedFeatured)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		pities = append(pities, pity)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning pities: %v", err)
+	}
+
+	return pities
+}
+
+func (repository *PityRepositoryImpl) SaveAll(ctx context.Context, pities []domain.Pity) {
+	query := `INSERT INTO pity (gacha_system_id, player_id, rarity_id, counter, guaranteed_featured)
+				VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (gacha_system_id, player_id, rarity_id)
+				DO UPDATE SET counter = EXCLUDED.counter, guaranteed_featured = EXCLUDED.guaranteed_featured,
+					updated_at = CURRENT_TIMESTAMP`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	for _, pity := range pities {
+		_, err = tx.Exec(ctx, query, pity.GachaSystemId, pity.PlayerId, pity.RarityId, pity.Counter, pity.GuaranteedFeatured)
+		helper.PanicIfError(err, "Failed to save pity")
+	}
+}
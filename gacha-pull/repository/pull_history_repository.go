@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type PullHistoryRepository interface {
+	FindAll(ctx context.Context, filter *domain.PullHistoryFilter) ([]domain.PullHistory, int)
+	SaveAll(ctx context.Context, pullHistories []domain.PullHistory)
+}
+
+type PullHistoryRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewPullHistoryRepository(dbpool *pgxpool.Pool) PullHistoryRepository {
+	return &PullHistoryRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+// FindAll returns a page of the pulls matching the filter, newest first, together with the total
+// number of matching pulls.
+func (repository *PullHistoryRepositoryImpl) FindAll(ctx context.Context, filter *domain.PullHistoryFilter) ([]domain.PullHistory, int) {
+	condition := `WHERE gacha_system_id = $1 AND player_id = $2
+			AND ($3 = '' OR rarity_name = $3)
+			AND ($4::timestamptz IS NULL OR pulled_at >= $4)
+			AND ($5::timestamptz IS NULL OR pulled_at < $5)`
+	countQuery := `SELECT COUNT(*) FROM pull_history ` + condition
+	query := `SELECT id, pull_id, gacha_system_id, config_version_id, player_id, character_id, character_name, rarity_id, rarity_name, featured, pity, pulled_at
+			FROM pull_history ` + condition + `
+			ORDER BY pulled_at DESC, id DESC
+			LIMIT $6 OFFSET $7`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	var total int
+	err = tx.QueryRow(ctx, countQuery, filter.GachaSystemId, filter.PlayerId, filter.RarityName, filter.From, filter.To).Scan(&total)
+	helper.PanicIfError(err, "Failed to count pull history")
+
+	rows, err := tx.Query(ctx, query, filter.GachaSystemId, filter.PlayerId, filter.RarityName, filter.From, filter.To, filter.Limit, filter.Offset)
+	if err != nil {
+		log.Printf("Error while querying pull history: %v", err)
+	}
+	defer rows.Close()
+
+	var pullHistories []domain.PullHistory
+	for rows.Next() {
+		var pullHistory domain.PullHistory
+		err = rows.Scan(&pullHistory.Id, &pullHistory.PullId, &pullHistory.GachaSystemId, &pullHistory.ConfigVersionId, &pullHistory.PlayerId, &pullHistory.CharacterId, &pullHistory.CharacterName,
+			&pullHistory.RarityId, &pullHistory.RarityName, &pullHistory.Featured, &pullHistory.Pity, &pullHistory.PulledAt)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		pullHistories = append(pullHistories, pullHistory)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning pull history: %v", err)
+	}
+
+	return pullHistories, total
+}
+
+func (repository *PullHistoryRepositoryImpl) SaveAll(ctx context.Context, pullHistories []domain.PullHistory) {
+	query := `INSERT INTO pull_history (pull_id, gacha_system_id, config_version_id, player_id, character_id, character_name, rarity_id, rarity_name, featured, pity)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	for _, pullHistory := range pullHistories {
+		pity := pullHistory.Pity
+		if pity == nil {
+			pity = []domain.PullHistoryPity{}
+		}
+
+		_, err = tx.Exec(ctx, query, pullHistory.PullId, pullHistory.GachaSystemId, pullHistory.ConfigVersionId, pullHistory.PlayerId, pullHistory.CharacterId, pullHistory.CharacterName,
+			pullHistory.RarityId, pullHistory.RarityName, pullHistory.Featured, pity)
+		helper.PanicIfError(err, "Failed to save pull history")
+	}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type PullReservationRepository interface {
+	Save(ctx context.Context, reservation *domain.PullReservation) bool
+	FindByToken(ctx context.Context, token string) *domain.PullReservation
+	FindOpenByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) *domain.PullReservation
+	FindAllExpired(ctx context.Context, limit int) []domain.PullReservation
+	Resolve(ctx context.Context, reservation *domain.PullReservation, status string) bool
+}
+
+type PullReservationRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewPullReservationRepository(dbpool *pgxpool.Pool) PullReservationRepository {
+	return &PullReservationRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+// Save stores a new reservation. It reports false when the player already holds an open
+// reservation on the gacha system.
+func (repository *PullReservationRepositoryImpl) Save(ctx context.Context, reservation *domain.PullReservation) bool {
+	query := `INSERT INTO pull_reservation (token, gacha_system_id, config_version_id, player_id, banner_id, pulls, pity_before, fair_pull, expires_at)
+				VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (gacha_system_id, player_id) WHERE status = 'reserved' DO NOTHING
+				RETURNING status, created_at`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	pityBefore := reservation.PityBefore
+	if pityBefore == nil {
+		pityBefore = []domain.Pity{}
+	}
+
+	err = tx.QueryRow(ctx, query, reservation.Token, reservation.GachaSystemId, reservation.ConfigVersionId, reservation.PlayerId, reservation.BannerId,
+		reservation.Pulls, pityBefore, reservation.FairPull, reservation.ExpiresAt).Scan(&reservation.Status, &reservation.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	helper.PanicIfError(err, "Failed to save pull reservation")
+
+	return true
+}
+
+func (repository *PullReservationRepositoryImpl) FindByToken(ctx context.Context, token string) *domain.PullReservation {
+	query := `SELECT token, gacha_system_id, COALESCE(config_version_id, 0), player_id, banner_id, pulls, pity_before, fair_pull, status, created_at, expires_at, resolved_at
+				FROM pull_reservation
+				WHERE token = $1`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getPullReservationFromRow(tx.QueryRow(ctx, query, token))
+}
+
+func (repository *PullReservationRepositoryImpl) FindOpenByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) *domain.PullReservation {
+	query := `SELECT token, gacha_system_id, COALESCE(config_version_id, 0), player_id, banner_id, pulls, pity_before, fair_pull, status, created_at, expires_at, resolved_at
+				FROM pull_reservation
+				WHERE gacha_system_id = $1 AND player_id = $2 AND status = 'reserved'`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getPullReservationFromRow(tx.QueryRow(ctx, query, gachaSystemId, playerId))
+}
+
+// FindAllExpired returns open reservations whose time ran out, oldest first.
+func (repository *PullReservationRepositoryImpl) FindAllExpired(ctx context.Context, limit int) []domain.PullReservation {
+	query := `SELECT token, gacha_system_id, COALESCE(config_version_id, 0), player_id, banner_id, pulls, pity_before, fair_pull, status, created_at, expires_at, resolved_at
+				FROM pull_reservation
+				WHERE status = 'reserved' AND expires_at <= CURRENT_TIMESTAMP
+				ORDER BY expires_at
+				LIMIT $1`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		log.Printf("Error while querying pull reservations: %v", err)
+	}
+	defer rows.Close()
+
+	var reservations []domain.PullReservation
+	for rows.Next() {
+		reservation := getPullReservationFromRow(rows)
+		if reservation == nil {
+			continue
+		}
+
+		reservations = append(reservations, *reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning pull reservations: %v", err)
+	}
+
+	return reservations
+}
+
+// Resolve closes an open reservation with the given status. Only a reservation that has not
+// expired yet can be confirmed. It reports false when the reservation could not be closed.
+func (repository *PullReservationRepositoryImpl) Resolve(ctx context.Context, reservation *domain.PullReservation, status string) bool {
+	query := `UPDATE pull_reservation SET status = $2, resolved_at = CURRENT_TIMESTAMP
+				WHERE token = $1 AND status = 'reserved' AND ($2 <> 'confirmed' OR expires_at > CURRENT_TIMESTAMP)
+				RETURNING status, resolved_at`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, query, reservation.Token, status).Scan(&reservation.Status, &reservation.ResolvedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	helper.PanicIfError(err, "Failed to resolve pull reservation")
+
+	return true
+}
+
+func getPullReservationFromRow(row pgx.Row) *domain.PullReservation {
+	var reservation domain.PullReservation
+
+	err := row.Scan(&reservation.Token, &reservation.GachaSystemId, &reservation.ConfigVersionId, &reservation.PlayerId, &reservation.BannerId, &reservation.Pulls,
+		&reservation.PityBefore, &reservation.FairPull, &reservation.Status, &reservation.CreatedAt, &reservation.ExpiresAt, &reservation.ResolvedAt)
+	if err != nil {
+		return nil
+	}
+
+	return &reservation
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+	"time"
+)
+
+type SparkRepository interface {
+	FindByBannerIdAndPlayerId(ctx context.Context, bannerId int, playerId string) *domain.Spark
+	FindAllUnsettledEndedBefore(ctx context.Context, endedBefore time.Time) []domain.Spark
+	AddPoints(ctx context.Context, banner *domain.Banner, endpointId string, playerId string, points int) int
+	SpendPoints(ctx context.Context, bannerId int, playerId string, points int) (int, bool)
+	Settle(ctx context.Context, spark *domain.Spark) bool
+}
+
+type SparkRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewSparkRepository(dbpool *pgxpool.Pool) SparkRepository {
+	return &SparkRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *SparkRepositoryImpl) FindByBannerIdAndPlayerId(ctx context.Context, bannerId int, playerId string) *domain.Spark {
+	query := `SELECT banner_id, player_id, gacha_system_id, endpoint_id, banner_name, ends_at, spark_currency_id, spark_conversion,
+				points, settled_at, updated_at
+				FROM spark
+				WHERE banner_id = $1 AND player_id = $2`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	return getSparkFromRow(tx.QueryRow(ctx, query, bannerId, playerId))
+}
+
+// FindAllUnsettledEndedBefore returns the unsettled sparks of banners that ended before endedBefore.
+func (repository *SparkRepositoryImpl) FindAllUnsettledEndedBefore(ctx context.Context, endedBefore time.Time) []domain.Spark {
+	query := `SELECT banner_id, player_id, gacha_system_id, endpoint_id, banner_name, ends_at, spark_currency_id, spark_conversion,
+				points, settled_at, updated_at
+				FROM spark
+				WHERE ends_at <= $1 AND settled_at IS NULL
+				ORDER BY ends_at`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, endedBefore)
+	if err != nil {
+		log.Printf("Error while querying sparks: %v", err)
+	}
+	defer rows.Close()
+
+	var sparks []domain.Spark
+	for rows.Next() {
+		spark := getSparkFromRow(rows)
+		if spark == nil {
+			continue
+		}
+
+		sparks = append(sparks, *spark)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning sparks: %v", err)
+	}
+
+	return sparks
+}
+
+// AddPoints gives a player points on a banner and returns the points they have now. The settlement
+// terms of the spark are taken from the banner. Points are no longer added once the banner was
+// settled, 0 is returned then.
+func (repository *SparkRepositoryImpl) AddPoints(ctx context.Context, banner *domain.Banner, endpointId string, playerId string, points int) int {
+	query := `INSERT INTO spark (banner_id, player_id, gacha_system_id, endpoint_id, banner_name, ends_at, spark_currency_id, spark_conversion, points)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				ON CONFLICT (banner_id, player_id)
+				DO UPDATE SET points = spark.points + EXCLUDED.points, banner_name = EXCLUDED.banner_name,
+					ends_at = EXCLUDED.ends_at, spark_currency_id = EXCLUDED.spark_currency_id,
+					spark_conversion = EXCLUDED.spark_conversion, updated_at = CURRENT_TIMESTAMP
+				WHERE spark.settled_at IS NULL
+				RETURNING points`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	var total int
+	err = tx.QueryRow(ctx, query, banner.Id, playerId, banner.GachaSystemId, endpointId, banner.Name, banner.EndsAt, banner.SparkCurrencyId,
+		banner.SparkConversion, points).Scan(&total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0
+	}
+	helper.PanicIfError(err, "Failed to add spark points")
+
+	return total
+}
+
+// SpendPoints takes points from a player on a banner and returns the points left. It reports false
+// without taking anything when the player has fewer points or the banner was settled.
+func (repository *SparkRepositoryImpl) SpendPoints(ctx context.Context, bannerId int, playerId string, points int) (int, bool) {
+	query := `UPDATE spark SET points = points - $3, updated_at = CURRENT_TIMESTAMP
+				WHERE banner_id = $1 AND player_id = $2 AND points >= $3 AND settled_at IS NULL
+				RETURNING points`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	var left int
+	err = tx.QueryRow(ctx, query, bannerId, playerId, points).Scan(&left)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false
+	}
+	helper.PanicIfError(err, "Failed to spend spark points")
+
+	return left, true
+}
+
+// Settle marks the points of a player on an ended banner as settled and loads the points and terms
+// they had, so they are converted exactly once. It reports false when the spark was already settled.
+func (repository *SparkRepositoryImpl) Settle(ctx context.Context, spark *domain.Spark) bool {
+	query := `UPDATE spark SET settled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+				WHERE banner_id = $1 AND player_id = $2 AND settled_at IS NULL
+				RETURNING points, spark_currency_id, spark_conversion, settled_at`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	err = tx.QueryRow(ctx, query, spark.BannerId, spark.PlayerId).Scan(&spark.Points, &spark.SparkCurrencyId, &spark.SparkConversion, &spark.SettledAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	helper.PanicIfError(err, "Failed to settle spark points")
+
+	return true
+}
+
+func getSparkFromRow(row pgx.Row) *domain.Spark {
+	var spark domain.Spark
+
+	err := row.Scan(&spark.BannerId, &spark.PlayerId, &spark.GachaSystemId, &spark.EndpointId, &spark.BannerName, &spark.EndsAt, &spark.SparkCurrencyId,
+		&spark.SparkConversion, &spark.Points, &spark.SettledAt, &spark.UpdatedAt)
+	if err != nil {
+		return nil
+	}
+
+	return &spark
+}
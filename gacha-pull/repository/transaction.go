@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"gacha-pull/helper"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Transaction groups the calls of several repositories into one database transaction.
+type Transaction interface {
+	// Run calls fn inside one transaction. Repository calls made with the context handed to fn
+	// join it, and it commits when fn returns or rolls back when fn panics.
+	Run(ctx context.Context, fn func(ctx context.Context))
+}
+
+type TransactionImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewTransaction(dbpool *pgxpool.Pool) Transaction {
+	return &TransactionImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (transaction *TransactionImpl) Run(ctx context.Context, fn func(ctx context.Context)) {
+	helper.RunInTx(ctx, transaction.Dbpool, fn)
+}
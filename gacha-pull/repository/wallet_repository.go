@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gacha-pull/helper"
+	"gacha-pull/model/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log"
+)
+
+type WalletRepository interface {
+	FindAllByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) []domain.Wallet
+	FindLedgerByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string, limit int) []domain.WalletLedgerEntry
+	ApplyEntry(ctx context.Context, entry *domain.WalletLedgerEntry) bool
+}
+
+type WalletRepositoryImpl struct {
+	Dbpool *pgxpool.Pool
+}
+
+func NewWalletRepository(dbpool *pgxpool.Pool) WalletRepository {
+	return &WalletRepositoryImpl{
+		Dbpool: dbpool,
+	}
+}
+
+func (repository *WalletRepositoryImpl) FindAllByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string) []domain.Wallet {
+	query := `SELECT gacha_system_id, player_id, currency_id, balance FROM wallet WHERE gacha_system_id = $1 AND player_id = $2 ORDER BY currency_id`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId, playerId)
+	if err != nil {
+		log.Printf("Error while querying wallets: %v", err)
+	}
+	defer rows.Close()
+
+	var wallets []domain.Wallet
+	for rows.Next() {
+		var wallet domain.Wallet
+		err = rows.Scan(&wallet.GachaSystemId, &wallet.PlayerId, &wallet.CurrencyId, &wallet.Balance)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		wallets = append(wallets, wallet)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning wallets: %v", err)
+	}
+
+	return wallets
+}
+
+func (repository *WalletRepositoryImpl) FindLedgerByGachaSystemIdAndPlayerId(ctx context.Context, gachaSystemId int, playerId string, limit int) []domain.WalletLedgerEntry {
+	query := `SELECT id, gacha_system_id, player_id, currency_id, amount, balance_after, reason, COALESCE(note, ''), created_at
+			FROM wallet_ledger
+			WHERE gacha_system_id = $1 AND player_id = $2
+			ORDER BY created_at DESC, id DESC
+			LIMIT $3`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	rows, err := tx.Query(ctx, query, gachaSystemId, playerId, limit)
+	if err != nil {
+		log.Printf("Error while querying wallet ledger: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []domain.WalletLedgerEntry
+	for rows.Next() {
+		var entry domain.WalletLedgerEntry
+		err = rows.Scan(&entry.Id, &entry.GachaSystemId, &entry.PlayerId, &entry.CurrencyId, &entry.Amount,
+			&entry.BalanceAfter, &entry.Reason, &entry.Note, &entry.CreatedAt)
+		if err != nil {
+			log.Printf("Error scanning row: %v", err)
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("Error while scanning wallet ledger: %v", err)
+	}
+
+	return entries
+}
+
+// ApplyEntry credits (positive amount) or debits (negative amount) a wallet and records the entry
+// in the ledger, in one transaction. It reports false without changing anything when a debit is
+// larger than the balance.
+func (repository *WalletRepositoryImpl) ApplyEntry(ctx context.Context, entry *domain.WalletLedgerEntry) bool {
+	creditQuery := `INSERT INTO wallet (gacha_system_id, player_id, currency_id, balance)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (gacha_system_id, player_id, currency_id)
+				DO UPDATE SET balance = wallet.balance + EXCLUDED.balance, updated_at = CURRENT_TIMESTAMP
+				RETURNING balance`
+	debitQuery := `UPDATE wallet SET balance = balance + $4, updated_at = CURRENT_TIMESTAMP
+				WHERE gacha_system_id = $1 AND player_id = $2 AND currency_id = $3 AND balance + $4 >= 0
+				RETURNING balance`
+	ledgerQuery := `INSERT INTO wallet_ledger (gacha_system_id, player_id, currency_id, amount, balance_after, reason, note)
+				VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+				RETURNING id, created_at`
+
+	tx, err := helper.BeginTx(ctx, repository.Dbpool)
+	helper.PanicIfError(err, helper.ErrBeginTransaction)
+
+	defer helper.CommitOrRollback(tx, ctx)
+
+	query := creditQuery
+	if entry.Amount < 0 {
+		query = debitQuery
+	}
+
+	err = tx.QueryRow(ctx, query, entry.GachaSystemId, entry.PlayerId, entry.CurrencyId, entry.Amount).Scan(&entry.BalanceAfter)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	helper.PanicIfError(err, "Failed to update wallet")
+
+	err = tx.QueryRow(ctx, ledgerQuery, entry.GachaSystemId, entry.PlayerId, entry.CurrencyId, entry.Amount,
+		entry.BalanceAfter, entry.Reason, entry.Note).Scan(&entry.Id, &entry.CreatedAt)
+	helper.PanicIfError(err, "Failed to save wallet ledger entry")
+
+	return true
+}
@@ -0,0 +1,83 @@
+package sampler
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// RandomSource provides uniformly distributed numbers in [0, 1).
+type RandomSource interface {
+	Float64() float64
+}
+
+type mathRandom struct{}
+
+func (mathRandom) Float64() float64 {
+	return rand.Float64()
+}
+
+// DefaultRandomSource draws from the auto-seeded math/rand generator.
+var DefaultRandomSource RandomSource = mathRandom{}
+
+// Sampler picks an index with probability weight / total weight. It consumes exactly one number
+// from the RandomSource per sample, so a sequence of draws can be replayed from its rolls.
+type Sampler struct {
+	cumulativeWeights []float64
+	totalWeight       float64
+}
+
+// New builds a Sampler over the weights. Negative weights are treated as zero. It returns nil when
+// no weight is positive, since there is nothing to sample from.
+func New(weights []float64) *Sampler {
+	cumulativeWeights := make([]float64, len(weights))
+	totalWeight := float64(0)
+	for i, weight := range weights {
+		if weight > 0 {
+			totalWeight += weight
+		}
+		cumulativeWeights[i] = totalWeight
+	}
+
+	if totalWeight <= 0 {
+		return nil
+	}
+
+	return &Sampler{
+		cumulativeWeights: cumulativeWeights,
+		totalWeight:       totalWeight,
+	}
+}
+
+// Sample returns the index of the drawn weight.
+func (sampler *Sampler) Sample(random RandomSource) int {
+	return sampler.Pick(random.Float64())
+}
+
+// Pick maps a roll in [0, 1) to the index of a weight, the same way Sample does.
+func (sampler *Sampler) Pick(roll float64) int {
+	target := roll * sampler.totalWeight
+	index := sort.Search(len(sampler.cumulativeWeights), func(i int) bool {
+		return sampler.cumulativeWeights[i] > target
+	})
+
+	// Rounding can push the target onto the total, which belongs to the last positive weight
+	if index == len(sampler.cumulativeWeights) {
+		index = sort.SearchFloat64s(sampler.cumulativeWeights, sampler.totalWeight)
+	}
+
+	return index
+}
+
+// Probability returns the exact probability of drawing the index.
+func (sampler *Sampler) Probability(index int) float64 {
+	weight := sampler.cumulativeWeights[index]
+	if index > 0 {
+		weight -= sampler.cumulativeWeights[index-1]
+	}
+	return weight / sampler.totalWeight
+}
+
+// Len returns the number of weights the Sampler was built from.
+func (sampler *Sampler) Len() int {
+	return len(sampler.cumulativeWeights)
+}
@@ -0,0 +1,146 @@
+package sampler
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const draws = 2_000_000
+
+// chiSquareCritical approximates the chi-square critical value for a significance level of 0.001
+// using the Wilson-Hilferty transformation.
+func chiSquareCritical(degreesOfFreedom int) float64 {
+	const z = 3.090232
+	k := float64(degreesOfFreedom)
+	return k * math.Pow(1-2/(9*k)+z*math.Sqrt(2/(9*k)), 3)
+}
+
+func TestSampleMatchesWeights(t *testing.T) {
+	manyWeights := make([]float64, 20)
+	for i := range manyWeights {
+		manyWeights[i] = float64(i + 1)
+	}
+
+	tests := []struct {
+		name    string
+		weights []float64
+	}{
+		{"typical banner", []float64{0.6, 5.1, 94.3}},
+		{"five tiers", []float64{50, 30, 15, 4, 1}},
+		{"zero weight in the middle", []float64{10, 0, 90}},
+		{"leading and trailing zero", []float64{0, 25, 75, 0}},
+		{"thirds", []float64{33.33, 33.33, 33.34}},
+		{"chances not adding up to 100", []float64{1, 2, 3}},
+		{"many characters", manyWeights},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sampler := New(test.weights)
+			random := rand.New(rand.NewSource(int64(i + 1)))
+
+			observed := make([]int, len(test.weights))
+			for n := 0; n < draws; n++ {
+				observed[sampler.Sample(random)]++
+			}
+
+			totalWeight := float64(0)
+			for _, weight := range test.weights {
+				totalWeight += weight
+			}
+
+			chiSquare := float64(0)
+			degreesOfFreedom := -1
+			for index, weight := range test.weights {
+				if weight == 0 {
+					if observed[index] != 0 {
+						t.Fatalf("index %d has zero weight but was drawn %d times", index, observed[index])
+					}
+					continue
+				}
+
+				expected := draws * weight / totalWeight
+				difference := float64(observed[index]) - expected
+				chiSquare += difference * difference / expected
+				degreesOfFreedom++
+			}
+
+			critical := chiSquareCritical(degreesOfFreedom)
+			if chiSquare > critical {
+				t.Errorf("chi-square %.2f exceeds critical value %.2f (df=%d), observed %v", chiSquare, critical, degreesOfFreedom, observed)
+			}
+		})
+	}
+}
+
+func TestSampleDetectsSkewedDistribution(t *testing.T) {
+	// Guards the test above against being too lenient: sampling 0.6/5.1/94.3 and checking it
+	// against a slightly different published rate must fail the chi-square test
+	sampler := New([]float64{0.6, 5.1, 94.3})
+	published := []float64{0.5, 5.1, 94.4}
+	random := rand.New(rand.NewSource(42))
+
+	observed := make([]int, len(published))
+	for n := 0; n < draws; n++ {
+		observed[sampler.Sample(random)]++
+	}
+
+	chiSquare := float64(0)
+	for index, chance := range published {
+		expected := draws * chance / 100
+		difference := float64(observed[index]) - expected
+		chiSquare += difference * difference / expected
+	}
+
+	if chiSquare <= chiSquareCritical(len(published)-1) {
+		t.Errorf("chi-square %.2f did not detect the skewed distribution", chiSquare)
+	}
+}
+
+func TestPickBoundaries(t *testing.T) {
+	sampler := New([]float64{0, 1, 1, 0})
+
+	tests := []struct {
+		roll     float64
+		expected int
+	}{
+		{0, 1},
+		{0.4999, 1},
+		{0.5, 2},
+		{math.Nextafter(1, 0), 2},
+	}
+
+	for _, test := range tests {
+		if index := sampler.Pick(test.roll); index != test.expected {
+			t.Errorf("Pick(%v) = %d, expected %d", test.roll, index, test.expected)
+		}
+	}
+}
+
+func TestProbability(t *testing.T) {
+	weights := []float64{0.6, 5.1, 0, 94.3}
+	sampler := New(weights)
+
+	total := float64(0)
+	for index, weight := range weights {
+		probability := sampler.Probability(index)
+		if math.Abs(probability-weight/100) > 1e-12 {
+			t.Errorf("Probability(%d) = %v, expected %v", index, probability, weight/100)
+		}
+		total += probability
+	}
+
+	if math.Abs(total-1) > 1e-12 {
+		t.Errorf("probabilities add up to %v, expected 1", total)
+	}
+}
+
+func TestNewWithoutPositiveWeight(t *testing.T) {
+	if New([]float64{0, 0}) != nil {
+		t.Error("expected nil sampler when every weight is zero")
+	}
+	if New(nil) != nil {
+		t.Error("expected nil sampler without weights")
+	}
+}
@@ -0,0 +1,73 @@
+package selection
+
+import (
+	"gacha-pull/model/domain"
+	"gacha-pull/sampler"
+)
+
+// BoxCharacters returns the characters of a box gacha system, grouped by rarity.
+func (pool *Pool) BoxCharacters() []domain.Character {
+	var characters []domain.Character
+	for _, rarity := range pool.Rarities {
+		characters = append(characters, pool.RarityCharsMap[rarity.Id]...)
+	}
+	return characters
+}
+
+// BoxRemaining returns how many items are left in a box, given the copies drawn per character.
+func (pool *Pool) BoxRemaining(drawn map[int]int) int {
+	remaining := 0
+	for _, character := range pool.BoxCharacters() {
+		remaining += max(character.BoxQuantity-drawn[character.Id], 0)
+	}
+	return remaining
+}
+
+// DrawFromBox draws count items from a box without replacement, so every item left in the box is
+// equally likely to come next. drawn holds the copies drawn per character and is updated in place.
+// It returns nil when fewer than count items are left.
+func (pool *Pool) DrawFromBox(count int, drawn map[int]int, random sampler.RandomSource) []Result {
+	if pool.BoxRemaining(drawn) < count {
+		return nil
+	}
+
+	rarityMap := make(map[int]domain.Rarity)
+	for _, rarity := range pool.Rarities {
+		rarityMap[rarity.Id] = rarity
+	}
+
+	characters := pool.BoxCharacters()
+	weights := make([]float64, len(characters))
+
+	var results []Result
+	for i := 0; i < count; i++ {
+		for j, character := range characters {
+			weights[j] = float64(character.BoxQuantity - drawn[character.Id])
+		}
+
+		character := characters[sampler.New(weights).Sample(random)]
+		drawn[character.Id]++
+
+		results = append(results, Result{Character: character, Rarity: rarityMap[character.RarityId]})
+	}
+
+	return results
+}
+
+// CanResetBox reports whether a box may be reset. A box holding key items can be reset once every
+// key item was drawn, any other box once it is empty.
+func (pool *Pool) CanResetBox(drawn map[int]int) bool {
+	hasKey := false
+	for _, character := range pool.BoxCharacters() {
+		if !character.BoxKey || character.BoxQuantity == 0 {
+			continue
+		}
+
+		hasKey = true
+		if drawn[character.Id] < character.BoxQuantity {
+			return false
+		}
+	}
+
+	return hasKey || pool.BoxRemaining(drawn) == 0
+}
@@ -0,0 +1,98 @@
+package selection
+
+import (
+	"gacha-pull/model/domain"
+	"testing"
+)
+
+func newTestBox(t *testing.T, characters []domain.Character) *Pool {
+	gachaSystem := &domain.GachaSystem{Id: 1, Mode: domain.GachaSystemModeBox}
+	rarities := []domain.Rarity{{Id: 1, Name: "SSR"}, {Id: 2, Name: "R"}}
+
+	pool, err := NewPool(gachaSystem, nil, rarities, characters)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	return pool
+}
+
+func TestDrawFromBox(t *testing.T) {
+	pool := newTestBox(t, []domain.Character{
+		{Id: 1, RarityId: 1, BoxQuantity: 1},
+		{Id: 2, RarityId: 2, BoxQuantity: 3},
+	})
+
+	tests := []struct {
+		name     string
+		drawn    map[int]int
+		count    int
+		numbers  []float64
+		expected []int
+	}{
+		// The box holds 1 of character 1 and 3 of character 2, so a roll below 0.25 draws character 1
+		{"full box", map[int]int{}, 1, []float64{0.2}, []int{1}},
+		{"drawn items leave the box", map[int]int{}, 3, []float64{0.2, 0.2, 0.2}, []int{1, 2, 2}},
+		{"only what is left can be drawn", map[int]int{2: 3}, 1, []float64{0.9}, []int{1}},
+		{"last items", map[int]int{1: 1, 2: 1}, 2, []float64{0.5, 0.5}, []int{2, 2}},
+		{"fewer items left than asked for", map[int]int{2: 3}, 2, nil, nil},
+		{"empty box", map[int]int{1: 1, 2: 3}, 1, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			remaining := pool.BoxRemaining(test.drawn)
+
+			random := &rolls{t: t, numbers: test.numbers}
+			results := pool.DrawFromBox(test.count, test.drawn, random)
+
+			if len(results) != len(test.expected) {
+				t.Fatalf("drew %d item(s), expected %d", len(results), len(test.expected))
+			}
+			for i, result := range results {
+				if result.Character.Id != test.expected[i] {
+					t.Errorf("draw %d got character %d, expected %d", i, result.Character.Id, test.expected[i])
+				}
+				if result.Rarity.Id != result.Character.RarityId {
+					t.Errorf("draw %d got rarity %d for a character of rarity %d", i, result.Rarity.Id, result.Character.RarityId)
+				}
+			}
+			if left := pool.BoxRemaining(test.drawn); left != remaining-len(results) {
+				t.Errorf("%d item(s) left, expected %d", left, remaining-len(results))
+			}
+			if len(random.numbers) != 0 {
+				t.Errorf("%d rolls were left unused", len(random.numbers))
+			}
+		})
+	}
+}
+
+func TestCanResetBox(t *testing.T) {
+	withKey := newTestBox(t, []domain.Character{
+		{Id: 1, RarityId: 1, BoxQuantity: 1, BoxKey: true},
+		{Id: 2, RarityId: 2, BoxQuantity: 3},
+	})
+	withoutKey := newTestBox(t, []domain.Character{
+		{Id: 1, RarityId: 1, BoxQuantity: 1},
+		{Id: 2, RarityId: 2, BoxQuantity: 3},
+	})
+
+	tests := []struct {
+		name     string
+		pool     *Pool
+		drawn    map[int]int
+		expected bool
+	}{
+		{"key item left", withKey, map[int]int{2: 3}, false},
+		{"key item drawn", withKey, map[int]int{1: 1}, true},
+		{"box without key items not empty", withoutKey, map[int]int{1: 1, 2: 2}, false},
+		{"box without key items empty", withoutKey, map[int]int{1: 1, 2: 3}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if canReset := test.pool.CanResetBox(test.drawn); canReset != test.expected {
+				t.Errorf("CanResetBox returned %v, expected %v", canReset, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package selection
+
+import (
+	"errors"
+	"fmt"
+	"gacha-pull/model/domain"
+	"gacha-pull/sampler"
+	"math"
+)
+
+// chanceTolerance is how far the rarity chances of a pool may be off 100 through rounding
+const chanceTolerance = 0.005
+
+var (
+	// ErrNothingToPull is returned for a gacha system without a rarity that can be pulled
+	ErrNothingToPull = errors.New("rarities or characters not found")
+	// ErrChanceTotal is returned for a gacha system whose rarity chances do not add up to 100
+	ErrChanceTotal = errors.New("rarity chances must sum to 100")
+)
+
+// Pool is the compiled selection table of a gacha system: the rarities that can be pulled, their
+// characters and the rules applied on top of the base chances.
+type Pool struct {
+	GachaSystem        domain.GachaSystem
+	Rarities           []domain.Rarity
+	RarityNameMap      map[int]string
+	RarityCharsMap     map[int][]domain.Character
+	GuaranteedRarities []domain.Rarity
+	PityRarities       []domain.Rarity
+	Banner             *domain.Banner
+}
+
+// Result is the outcome of a single pull together with the pity state right after it.
+type Result struct {
+	Character domain.Character
+	Rarity    domain.Rarity
+	Pities    []domain.Pity
+}
+
+// NewPool compiles the selection table of a gacha system. Rarities without chance or characters
+// are left out, and ErrNothingToPull is returned when nothing can be pulled. The chances of the
+// rarities left must add up to 100, they are never scaled to fit, ErrChanceTotal is returned
+// otherwise. A running banner with featured characters replaces the featured characters of the
+// gacha system. Box draws ignore the rarity chances, so a box gacha system keeps every rarity with
+// characters.
+func NewPool(gachaSystem *domain.GachaSystem, banner *domain.Banner, rarities []domain.Rarity, characters []domain.Character) (*Pool, error) {
+	if banner != nil && len(banner.FeaturedCharacterIds) > 0 {
+		characters = featureBannerCharacters(banner, characters)
+	}
+
+	rarityNameMap := make(map[int]string)
+	for _, rarity := range rarities {
+		rarityNameMap[rarity.Id] = rarity.Name
+	}
+
+	rarityCharsMap := make(map[int][]domain.Character)
+	for _, character := range characters {
+		rarityCharsMap[character.RarityId] = append(rarityCharsMap[character.RarityId], character)
+	}
+
+	var filteredRarities []domain.Rarity
+	var chanceSum float64
+	for _, rarity := range rarities {
+		if len(rarityCharsMap[rarity.Id]) > 0 && (rarity.Chance > 0 || gachaSystem.Mode == domain.GachaSystemModeBox) {
+			filteredRarities = append(filteredRarities, rarity)
+			chanceSum += float64(rarity.Chance)
+		}
+	}
+
+	if len(filteredRarities) == 0 {
+		return nil, ErrNothingToPull
+	}
+
+	if gachaSystem.Mode != domain.GachaSystemModeBox && math.Abs(chanceSum-100) > chanceTolerance {
+		return nil, fmt.Errorf("%w, the rarities with characters sum to %.2f", ErrChanceTotal, chanceSum)
+	}
+
+	return &Pool{
+		GachaSystem:        *gachaSystem,
+		Rarities:           filteredRarities,
+		RarityNameMap:      rarityNameMap,
+		RarityCharsMap:     rarityCharsMap,
+		GuaranteedRarities: GuaranteedRarities(gachaSystem.GuaranteedRarityId, filteredRarities),
+		PityRarities:       PityRarities(filteredRarities, rarityCharsMap),
+		Banner:             banner,
+	}, nil
+}
+
+func featureBannerCharacters(banner *domain.Banner, characters []domain.Character) []domain.Character {
+	featuredMap := make(map[int]bool)
+	for _, characterId := range banner.FeaturedCharacterIds {
+		featuredMap[characterId] = true
+	}
+
+	bannerCharacters := make([]domain.Character, len(characters))
+	for i, character := range characters {
+		character.Featured = featuredMap[character.Id]
+		bannerCharacters[i] = character
+	}
+	return bannerCharacters
+}
+
+// NewPities returns the pity state of a player keyed by rarity id, starting from the stored pities.
+func (pool *Pool) NewPities(playerId string, storedPities []domain.Pity) map[int]*domain.Pity {
+	pities := make(map[int]*domain.Pity)
+	for _, rarity := range pool.PityRarities {
+		pities[rarity.Id] = &domain.Pity{GachaSystemId: pool.GachaSystem.Id, PlayerId: playerId, RarityId: rarity.Id}
+	}
+	for _, pity := range storedPities {
+		if _, ok := pities[pity.RarityId]; ok {
+			pities[pity.RarityId].Counter = pity.Counter
+			pities[pity.RarityId].GuaranteedFeatured = pity.GuaranteedFeatured
+		}
+	}
+	return pities
+}
+
+// Draw performs count pulls in a row. pities holds the pity state of the player and is updated in
+// place, a nil map draws without pity. The outcome only depends on the pool, the pity state and
+// the numbers returned by random, so a recorded random source replays the exact same pulls.
+func (pool *Pool) Draw(count int, pities map[int]*domain.Pity, random sampler.RandomSource) []Result {
+	var pityRarities []domain.Rarity
+	if pities != nil {
+		pityRarities = pool.PityRarities
+	}
+
+	guaranteePullCount := pool.GachaSystem.GuaranteePullCount
+	if guaranteePullCount < 1 {
+		guaranteePullCount = 1
+	}
+
+	var results []Result
+	guaranteeMet := false
+	for i := 0; i < count; i++ {
+		if i%guaranteePullCount == 0 {
+			guaranteeMet = false
+		}
+
+		effectiveRarities := pool.Rarities
+		if len(pityRarities) > 0 {
+			effectiveRarities = ApplySoftPity(pool.Rarities, pities)
+		}
+
+		var selectedRarity *domain.Rarity
+		pityRarity := ReachedPityRarity(pityRarities, pities)
+		lastPullOfBatch := (i+1)%guaranteePullCount == 0
+		if pityRarity != nil {
+			selectedRarity = pityRarity
+		} else if lastPullOfBatch && !guaranteeMet && len(pool.GuaranteedRarities) > 0 {
+			selectedRarity = RandomRarity(intersectRarities(effectiveRarities, pool.GuaranteedRarities), random)
+		} else {
+			selectedRarity = RandomRarity(effectiveRarities, random)
+		}
+
+		if containsRarity(pool.GuaranteedRarities, selectedRarity.Id) {
+			guaranteeMet = true
+		}
+
+		guaranteedFeatured := false
+		if pity, ok := pities[selectedRarity.Id]; ok {
+			guaranteedFeatured = pity.GuaranteedFeatured
+		}
+		selectedCharacter := RandomCharacterInRarity(selectedRarity, pool.RarityCharsMap, guaranteedFeatured, random)
+
+		result := Result{Character: *selectedCharacter}
+		for _, rarity := range pool.Rarities {
+			if rarity.Id == selectedCharacter.RarityId {
+				result.Rarity = rarity
+			}
+		}
+
+		if len(pityRarities) > 0 {
+			UpdatePities(pityRarities, pities, selectedCharacter, pool.RarityCharsMap)
+			for _, rarity := range pityRarities {
+				result.Pities = append(result.Pities, *pities[rarity.Id])
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
@@ -0,0 +1,284 @@
+package selection
+
+import (
+	"errors"
+	"gacha-pull/model/domain"
+	"testing"
+)
+
+// rolls is a random source returning fixed numbers in order, so a test decides every roll of a draw.
+type rolls struct {
+	t       *testing.T
+	numbers []float64
+}
+
+func (random *rolls) Float64() float64 {
+	if len(random.numbers) == 0 {
+		random.t.Fatal("draw consumed more rolls than expected")
+	}
+	number := random.numbers[0]
+	random.numbers = random.numbers[1:]
+	return number
+}
+
+func newTestPool(t *testing.T, rarities []domain.Rarity, characters []domain.Character) *Pool {
+	pool, err := NewPool(&domain.GachaSystem{Id: 1}, nil, rarities, characters)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+	return pool
+}
+
+func TestNewPoolChanceTotal(t *testing.T) {
+	characters := []domain.Character{{Id: 1, RarityId: 1}, {Id: 2, RarityId: 2}, {Id: 3, RarityId: 3}}
+
+	tests := []struct {
+		name     string
+		mode     string
+		chances  []float32
+		expected error
+	}{
+		{"adds up to 100", "", []float32{1, 9, 90}, nil},
+		{"rounded thirds", "", []float32{33.33, 33.33, 33.34}, nil},
+		{"below 100", "", []float32{1, 2, 3}, ErrChanceTotal},
+		{"above 100", "", []float32{10, 20, 80}, ErrChanceTotal},
+		{"box ignores chances", domain.GachaSystemModeBox, []float32{1, 2, 3}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var rarities []domain.Rarity
+			for i, chance := range test.chances {
+				rarities = append(rarities, domain.Rarity{Id: i + 1, Chance: chance})
+			}
+
+			_, err := NewPool(&domain.GachaSystem{Id: 1, Mode: test.mode}, nil, rarities, characters)
+			if !errors.Is(err, test.expected) {
+				t.Errorf("NewPool returned %v, expected %v", err, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewPoolRarityWithoutCharactersDoesNotCount(t *testing.T) {
+	rarities := []domain.Rarity{{Id: 1, Chance: 10}, {Id: 2, Chance: 90}}
+	characters := []domain.Character{{Id: 1, RarityId: 2}}
+
+	if _, err := NewPool(&domain.GachaSystem{Id: 1}, nil, rarities, characters); !errors.Is(err, ErrChanceTotal) {
+		t.Errorf("NewPool returned %v, expected %v", err, ErrChanceTotal)
+	}
+	if _, err := NewPool(&domain.GachaSystem{Id: 1}, nil, rarities, nil); !errors.Is(err, ErrNothingToPull) {
+		t.Errorf("NewPool returned %v, expected %v", err, ErrNothingToPull)
+	}
+}
+
+func TestDrawHardPity(t *testing.T) {
+	rarities := []domain.Rarity{
+		{Id: 1, Name: "SSR", Chance: 1, PityThreshold: 10},
+		{Id: 2, Name: "R", Chance: 99},
+	}
+	characters := []domain.Character{{Id: 1, RarityId: 1}, {Id: 2, RarityId: 2}}
+	pool := newTestPool(t, rarities, characters)
+
+	pities := pool.NewPities("player", []domain.Pity{{RarityId: 1, Counter: 9}})
+
+	// The first roll misses SSR and brings the counter to the threshold, the second pull is forced
+	// to SSR without consuming a rarity roll
+	results := pool.Draw(2, pities, &rolls{t: t, numbers: []float64{0.5, 0.5, 0.5}})
+
+	if results[0].Rarity.Id != 2 || results[0].Pities[0].Counter != 10 {
+		t.Errorf("first pull got rarity %d with counter %d, expected rarity 2 with counter 10", results[0].Rarity.Id, results[0].Pities[0].Counter)
+	}
+	if results[1].Rarity.Id != 1 || results[1].Pities[0].Counter != 0 {
+		t.Errorf("second pull got rarity %d with counter %d, expected rarity 1 with counter 0", results[1].Rarity.Id, results[1].Pities[0].Counter)
+	}
+}
+
+func TestDrawSoftPity(t *testing.T) {
+	rarities := []domain.Rarity{
+		{Id: 1, Name: "SSR", Chance: 1, SoftPityStart: 5, SoftPityStep: 10},
+		{Id: 2, Name: "R", Chance: 99},
+	}
+	characters := []domain.Character{{Id: 1, RarityId: 1}, {Id: 2, RarityId: 2}}
+	pool := newTestPool(t, rarities, characters)
+
+	tests := []struct {
+		name     string
+		counter  int
+		expected int
+	}{
+		// A roll of 0.05 lands past the base chance of 1% ...
+		{"before the ramp", 3, 2},
+		// ... but within the 11% of the first soft pity step on the fifth pull
+		{"first ramp step", 4, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pities := pool.NewPities("player", []domain.Pity{{RarityId: 1, Counter: test.counter}})
+			results := pool.Draw(1, pities, &rolls{t: t, numbers: []float64{0.05, 0.5}})
+
+			if results[0].Rarity.Id != test.expected {
+				t.Errorf("pulled rarity %d, expected %d", results[0].Rarity.Id, test.expected)
+			}
+		})
+	}
+}
+
+func TestApplySoftPityKeepsTotal(t *testing.T) {
+	rarities := []domain.Rarity{
+		{Id: 1, Chance: 1, SoftPityStart: 5, SoftPityStep: 10},
+		{Id: 2, Chance: 9},
+		{Id: 3, Chance: 90},
+	}
+	pities := map[int]*domain.Pity{1: {RarityId: 1, Counter: 6}}
+
+	effectiveRarities := ApplySoftPity(rarities, pities)
+
+	var total float32
+	for _, rarity := range effectiveRarities {
+		total += rarity.Chance
+	}
+	if effectiveRarities[0].Chance != 31 {
+		t.Errorf("boosted chance is %v, expected 31", effectiveRarities[0].Chance)
+	}
+	if total < 99.99 || total > 100.01 {
+		t.Errorf("chances add up to %v, expected 100", total)
+	}
+}
+
+func TestDrawFeaturedFiftyFifty(t *testing.T) {
+	rarities := []domain.Rarity{{Id: 1, Name: "SSR", Chance: 100, FeaturedChance: 50}}
+	characters := []domain.Character{
+		{Id: 1, RarityId: 1, Featured: true, Weight: 1},
+		{Id: 2, RarityId: 1, Weight: 1},
+	}
+	pool := newTestPool(t, rarities, characters)
+	pities := pool.NewPities("player", nil)
+
+	// Each pull rolls the rarity, then the 50/50, then the character. A guaranteed featured pull
+	// skips the 50/50 roll.
+	random := &rolls{t: t, numbers: []float64{
+		0.5, 0.9, 0.5, // loses the 50/50
+		0.5, 0.5, // guaranteed featured
+		0.5, 0.2, 0.5, // wins the 50/50
+	}}
+	results := pool.Draw(3, pities, random)
+
+	expected := []struct {
+		characterId        int
+		guaranteedFeatured bool
+	}{
+		{2, true},
+		{1, false},
+		{1, false},
+	}
+	for i, result := range results {
+		if result.Character.Id != expected[i].characterId || result.Pities[0].GuaranteedFeatured != expected[i].guaranteedFeatured {
+			t.Errorf("pull %d got character %d with guaranteed featured %v, expected character %d with %v",
+				i, result.Character.Id, result.Pities[0].GuaranteedFeatured, expected[i].characterId, expected[i].guaranteedFeatured)
+		}
+	}
+	if len(random.numbers) != 0 {
+		t.Errorf("%d rolls were left unused", len(random.numbers))
+	}
+}
+
+func TestDrawGuaranteedRarity(t *testing.T) {
+	rarities := []domain.Rarity{
+		{Id: 1, Name: "SSR", Chance: 1},
+		{Id: 2, Name: "SR", Chance: 9},
+		{Id: 3, Name: "R", Chance: 90},
+	}
+	characters := []domain.Character{{Id: 1, RarityId: 1}, {Id: 2, RarityId: 2}, {Id: 3, RarityId: 3}}
+	gachaSystem := &domain.GachaSystem{Id: 1, GuaranteedRarityId: 2, GuaranteePullCount: 10}
+	pool, err := NewPool(gachaSystem, nil, rarities, characters)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+
+	// A rarity roll of 0.5 pulls R, 0.05 pulls SR and 0.005 pulls SSR. Among the guaranteed
+	// rarities SSR and SR alone, 0.5 pulls SR and 0.05 pulls SSR.
+	tests := []struct {
+		name        string
+		count       int
+		rarityRolls map[int]float64
+		expected    map[int]int
+	}{
+		{"last pull of the batch is guaranteed", 10, nil, map[int]int{9: 2}},
+		{"guaranteed pull keeps the odds between guaranteed rarities", 10, map[int]float64{9: 0.05}, map[int]int{9: 1}},
+		{"rarity pulled earlier meets the guarantee", 10, map[int]float64{2: 0.05}, map[int]int{2: 2}},
+		{"rarer rarity meets the guarantee", 10, map[int]float64{0: 0.005}, map[int]int{0: 1}},
+		{"every batch is guaranteed", 20, nil, map[int]int{9: 2, 19: 2}},
+		{"pulls short of a batch are not guaranteed", 9, nil, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Every pull rolls its rarity, then its character
+			var numbers []float64
+			for i := 0; i < test.count; i++ {
+				rarityRoll, ok := test.rarityRolls[i]
+				if !ok {
+					rarityRoll = 0.5
+				}
+				numbers = append(numbers, rarityRoll, 0.5)
+			}
+
+			results := pool.Draw(test.count, nil, &rolls{t: t, numbers: numbers})
+
+			for i, result := range results {
+				expected, ok := test.expected[i]
+				if !ok {
+					expected = 3
+				}
+				if result.Rarity.Id != expected {
+					t.Errorf("pull %d got rarity %d, expected %d", i, result.Rarity.Id, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomCharacterInRarityWeights(t *testing.T) {
+	rarity := domain.Rarity{Id: 1, Chance: 100, FeaturedChance: 50}
+
+	tests := []struct {
+		name       string
+		characters []domain.Character
+		numbers    []float64
+		expected   int
+	}{
+		// Weights 1 and 3 split the roll at 0.25
+		{"light character", []domain.Character{{Id: 1, Weight: 1}, {Id: 2, Weight: 3}}, []float64{0.2}, 1},
+		{"heavy character", []domain.Character{{Id: 1, Weight: 1}, {Id: 2, Weight: 3}}, []float64{0.3}, 2},
+		{"heavy character up to the last roll", []domain.Character{{Id: 1, Weight: 1}, {Id: 2, Weight: 3}}, []float64{0.99}, 2},
+		{"fractional weights", []domain.Character{{Id: 1, Weight: 0.5}, {Id: 2, Weight: 1.5}}, []float64{0.24}, 1},
+		// The 50/50 is won first, then the weights pick among the featured characters only
+		{"weights within the featured characters", []domain.Character{
+			{Id: 1, Weight: 1, Featured: true},
+			{Id: 2, Weight: 9, Featured: true},
+			{Id: 3, Weight: 100},
+		}, []float64{0.2, 0.05}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rarityMap := make(map[int][]domain.Character)
+			for _, character := range test.characters {
+				character.RarityId = rarity.Id
+				rarityMap[rarity.Id] = append(rarityMap[rarity.Id], character)
+			}
+
+			random := &rolls{t: t, numbers: test.numbers}
+			character := RandomCharacterInRarity(&rarity, rarityMap, false, random)
+
+			if character.Id != test.expected {
+				t.Errorf("picked character %d, expected %d", character.Id, test.expected)
+			}
+			if len(random.numbers) != 0 {
+				t.Errorf("%d rolls were left unused", len(random.numbers))
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package selection
+
+import "gacha-pull/model/domain"
+
+// maxRatePulls bounds the pity walk of a rarity without hard pity. Its survival probability has
+// long become negligible by then.
+const maxRatePulls = 100000
+
+// RarityRate is the probability of pulling a rarity. The base rate is the chance of a single pull
+// without any pity, the consolidated rate is the long-run share of pulls landing on the rarity once
+// its hard pity, soft pity and featured guarantee are taken into account.
+type RarityRate struct {
+	Rarity           domain.Rarity
+	BaseRate         float64
+	ConsolidatedRate float64
+	ExpectedPulls    float64
+	Characters       []CharacterRate
+}
+
+type CharacterRate struct {
+	Character        domain.Character
+	BaseRate         float64
+	ConsolidatedRate float64
+}
+
+// Rates computes the pull probabilities of every rarity and character of the pool. A rarity with
+// hard or soft pity is followed through its own pity counter, the rarities without pity share the
+// remaining pulls in proportion to their chances. The multi-pull guarantee is left out, see
+// RatesApproximate for when that makes the consolidated rates an approximation.
+func (pool *Pool) Rates() []RarityRate {
+	var totalChance, unpitiedChance, pitiedRate float64
+	consolidatedRates := make([]float64, len(pool.Rarities))
+	for i, rarity := range pool.Rarities {
+		totalChance += float64(rarity.Chance)
+		if !pool.hasRatePity(&rarity) {
+			unpitiedChance += float64(rarity.Chance)
+			continue
+		}
+
+		if expectedPulls := pool.expectedPulls(&rarity); expectedPulls > 0 {
+			consolidatedRates[i] = 1 / expectedPulls
+			pitiedRate += consolidatedRates[i]
+		}
+	}
+
+	var rarityRates []RarityRate
+	for i, rarity := range pool.Rarities {
+		baseRate := float64(rarity.Chance) / totalChance
+
+		consolidatedRate := consolidatedRates[i]
+		if !pool.hasRatePity(&rarity) && unpitiedChance > 0 {
+			consolidatedRate = max(1-pitiedRate, 0) * float64(rarity.Chance) / unpitiedChance
+		}
+
+		expectedPulls := 0.0
+		if consolidatedRate > 0 {
+			expectedPulls = 1 / consolidatedRate
+		}
+
+		rarityRates = append(rarityRates, RarityRate{
+			Rarity:           rarity,
+			BaseRate:         baseRate,
+			ConsolidatedRate: consolidatedRate,
+			ExpectedPulls:    expectedPulls,
+			Characters:       pool.characterRates(&rarity, baseRate, consolidatedRate),
+		})
+	}
+
+	return rarityRates
+}
+
+// RatesApproximate reports whether the consolidated rates of Rates are an approximation. They are
+// exact when at most one rarity has pity and no multi-pull guarantee applies. Otherwise the pity
+// counters of several rarities interact, e.g. a pull forced to one rarity still counts as a miss
+// for the others, and the multi-pull guarantee makes rarer pulls more likely, neither of which
+// Rates models.
+func (pool *Pool) RatesApproximate() bool {
+	if len(pool.GuaranteedRarities) > 0 && len(pool.GuaranteedRarities) < len(pool.Rarities) {
+		return true
+	}
+
+	pitiedRarities := 0
+	for i := range pool.Rarities {
+		if pool.hasRatePity(&pool.Rarities[i]) {
+			pitiedRarities++
+		}
+	}
+	return pitiedRarities > 1
+}
+
+// hasRatePity reports whether pity changes the chance of pulling the rarity itself. A featured
+// guarantee only changes which character of the rarity is pulled.
+func (pool *Pool) hasRatePity(rarity *domain.Rarity) bool {
+	if !containsRarity(pool.PityRarities, rarity.Id) {
+		return false
+	}
+	return rarity.PityThreshold > 0 || (rarity.SoftPityStart > 0 && rarity.SoftPityStep > 0)
+}
+
+// expectedPulls returns the average number of pulls between two pulls of a rarity with pity,
+// walking its pity counter from zero until the hard pity forces it or it becomes practically
+// certain.
+func (pool *Pool) expectedPulls(rarity *domain.Rarity) float64 {
+	pities := map[int]*domain.Pity{rarity.Id: {RarityId: rarity.Id}}
+
+	var expectedPulls float64
+	survival := 1.0
+	for counter := 0; counter < maxRatePulls && survival > 1e-12; counter++ {
+		expectedPulls += survival
+
+		var chance float64
+		if rarity.PityThreshold > 0 && counter >= rarity.PityThreshold {
+			chance = 1
+		} else {
+			pities[rarity.Id].Counter = counter
+			chance = rarityChance(ApplySoftPity(pool.Rarities, pities), rarity.Id)
+		}
+
+		survival *= 1 - chance
+	}
+
+	return expectedPulls
+}
+
+func rarityChance(rarities []domain.Rarity, rarityId int) float64 {
+	var totalChance, chance float64
+	for _, rarity := range rarities {
+		totalChance += float64(rarity.Chance)
+		if rarity.Id == rarityId {
+			chance = float64(rarity.Chance)
+		}
+	}
+	if totalChance == 0 {
+		return 0
+	}
+	return chance / totalChance
+}
+
+// characterRates splits the rates of a rarity over its characters by weight. When the rarity
+// mixes featured and standard characters the featured ones share the featured chance, and in the
+// long run 1 / (2 - featured chance) once a lost 50/50 guarantees the next featured character.
+func (pool *Pool) characterRates(rarity *domain.Rarity, baseRate float64, consolidatedRate float64) []CharacterRate {
+	characters := pool.RarityCharsMap[rarity.Id]
+
+	featuredShare, consolidatedFeaturedShare := 1.0, 1.0
+	if hasFeaturedCharacter(characters) && hasStandardCharacter(characters) {
+		featuredShare = float64(rarity.FeaturedChance) / 100
+		consolidatedFeaturedShare = 1 / (2 - featuredShare)
+	}
+
+	var featuredWeight, standardWeight float64
+	for _, character := range characters {
+		if character.Featured {
+			featuredWeight += float64(character.Weight)
+		} else {
+			standardWeight += float64(character.Weight)
+		}
+	}
+
+	var characterRates []CharacterRate
+	for _, character := range characters {
+		groupShare, consolidatedGroupShare, groupWeight := featuredShare, consolidatedFeaturedShare, featuredWeight
+		if !character.Featured {
+			groupShare, consolidatedGroupShare, groupWeight = 1-featuredShare, 1-consolidatedFeaturedShare, standardWeight
+			if !hasFeaturedCharacter(characters) {
+				groupShare, consolidatedGroupShare = 1, 1
+			}
+		}
+
+		weightShare := 0.0
+		if groupWeight > 0 {
+			weightShare = float64(character.Weight) / groupWeight
+		}
+
+		characterRates = append(characterRates, CharacterRate{
+			Character:        character,
+			BaseRate:         baseRate * groupShare * weightShare,
+			ConsolidatedRate: consolidatedRate * consolidatedGroupShare * weightShare,
+		})
+	}
+
+	return characterRates
+}
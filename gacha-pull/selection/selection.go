@@ -0,0 +1,227 @@
+package selection
+
+import (
+	"gacha-pull/model/domain"
+	"gacha-pull/sampler"
+)
+
+// GuaranteedRarities returns the guaranteed rarity and every rarity that is at least as rare,
+// i.e. whose chance is not higher than the guaranteed one.
+func GuaranteedRarities(guaranteedRarityId int, rarities []domain.Rarity) []domain.Rarity {
+	if guaranteedRarityId == 0 {
+		return nil
+	}
+
+	var guaranteedRarity *domain.Rarity
+	for i := range rarities {
+		if rarities[i].Id == guaranteedRarityId {
+			guaranteedRarity = &rarities[i]
+			break
+		}
+	}
+
+	if guaranteedRarity == nil {
+		return nil
+	}
+
+	var guaranteedRarities []domain.Rarity
+	for _, rarity := range rarities {
+		if rarity.Chance <= guaranteedRarity.Chance {
+			guaranteedRarities = append(guaranteedRarities, rarity)
+		}
+	}
+
+	return guaranteedRarities
+}
+
+func containsRarity(rarities []domain.Rarity, rarityId int) bool {
+	for _, rarity := range rarities {
+		if rarity.Id == rarityId {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectRarities(rarities []domain.Rarity, subset []domain.Rarity) []domain.Rarity {
+	var intersection []domain.Rarity
+	for _, rarity := range rarities {
+		if containsRarity(subset, rarity.Id) {
+			intersection = append(intersection, rarity)
+		}
+	}
+	return intersection
+}
+
+// PityRarities returns the rarities that carry per-player pity state: a hard or soft pity, or
+// featured characters whose 50/50 can be lost.
+func PityRarities(rarities []domain.Rarity, rarityMap map[int][]domain.Character) []domain.Rarity {
+	var pityRarities []domain.Rarity
+	for _, rarity := range rarities {
+		if rarity.PityThreshold > 0 || rarity.SoftPityStart > 0 || hasFeaturedCharacter(rarityMap[rarity.Id]) {
+			pityRarities = append(pityRarities, rarity)
+		}
+	}
+	return pityRarities
+}
+
+// ReachedPityRarity returns the rarity the next pull is forced to, or nil when no pity threshold
+// has been reached. When several thresholds are reached at once the rarest rarity wins.
+func ReachedPityRarity(pityRarities []domain.Rarity, pities map[int]*domain.Pity) *domain.Rarity {
+	var reachedRarity *domain.Rarity
+	for i := range pityRarities {
+		rarity := &pityRarities[i]
+		if rarity.PityThreshold == 0 || pities[rarity.Id].Counter < rarity.PityThreshold {
+			continue
+		}
+		if reachedRarity == nil || rarity.Chance < reachedRarity.Chance {
+			reachedRarity = rarity
+		}
+	}
+	return reachedRarity
+}
+
+// ApplySoftPity returns a copy of rarities whose chances include the soft pity ramp. From pull
+// SoftPityStart onward a rarity gains SoftPityStep on every pull, and the chances of the remaining
+// rarities shrink proportionally so the total stays the same.
+func ApplySoftPity(rarities []domain.Rarity, pities map[int]*domain.Pity) []domain.Rarity {
+	var totalChance, boostedChance, unboostedChance float32
+	effectiveRarities := make([]domain.Rarity, len(rarities))
+	boosted := make([]bool, len(rarities))
+
+	for i, rarity := range rarities {
+		totalChance += rarity.Chance
+		effectiveRarities[i] = rarity
+
+		pity, ok := pities[rarity.Id]
+		if !ok || rarity.SoftPityStart == 0 || rarity.SoftPityStep == 0 {
+			continue
+		}
+
+		pullNumber := pity.Counter + 1
+		if pullNumber >= rarity.SoftPityStart {
+			boostSteps := float32(pullNumber - rarity.SoftPityStart + 1)
+			effectiveRarities[i].Chance = rarity.Chance + rarity.SoftPityStep*boostSteps
+			boosted[i] = true
+		}
+	}
+
+	for i := range effectiveRarities {
+		if boosted[i] {
+			effectiveRarities[i].Chance = min(effectiveRarities[i].Chance, totalChance)
+			boostedChance += effectiveRarities[i].Chance
+		} else {
+			unboostedChance += effectiveRarities[i].Chance
+		}
+	}
+
+	if boostedChance == 0 {
+		return rarities
+	}
+
+	for i := range effectiveRarities {
+		switch {
+		case boostedChance >= totalChance && boosted[i]:
+			effectiveRarities[i].Chance *= totalChance / boostedChance
+		case boostedChance >= totalChance:
+			effectiveRarities[i].Chance = 0
+		case !boosted[i] && unboostedChance > 0:
+			effectiveRarities[i].Chance *= (totalChance - boostedChance) / unboostedChance
+		}
+	}
+
+	return effectiveRarities
+}
+
+// UpdatePities resets the counter of the pulled rarity and counts one more miss for the others.
+// Losing the 50/50 of a rarity with featured characters guarantees a featured one next time.
+func UpdatePities(pityRarities []domain.Rarity, pities map[int]*domain.Pity, pulledCharacter *domain.Character, rarityMap map[int][]domain.Character) {
+	for _, rarity := range pityRarities {
+		pity := pities[rarity.Id]
+		if rarity.Id != pulledCharacter.RarityId {
+			pity.Counter++
+			continue
+		}
+
+		pity.Counter = 0
+		if hasFeaturedCharacter(rarityMap[rarity.Id]) && hasStandardCharacter(rarityMap[rarity.Id]) {
+			pity.GuaranteedFeatured = !pulledCharacter.Featured
+		}
+	}
+}
+
+func hasFeaturedCharacter(characters []domain.Character) bool {
+	for _, character := range characters {
+		if character.Featured {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStandardCharacter(characters []domain.Character) bool {
+	for _, character := range characters {
+		if !character.Featured {
+			return true
+		}
+	}
+	return false
+}
+
+// RandomRarity draws a rarity with probability chance / sum of all chances. The rarities of a pool
+// add up to 100, a smaller sum only comes from the guaranteed rarities of a multi-pull, which are
+// drawn in proportion to each other.
+func RandomRarity(rarities []domain.Rarity, random sampler.RandomSource) *domain.Rarity {
+	chances := make([]float64, len(rarities))
+	for i, rarity := range rarities {
+		chances[i] = float64(rarity.Chance)
+	}
+
+	raritySampler := sampler.New(chances)
+	if raritySampler == nil {
+		// Soft pity can leave every candidate without chance, fall back to an even draw
+		raritySampler = sampler.New(evenWeights(len(rarities)))
+	}
+
+	return &rarities[raritySampler.Sample(random)]
+}
+
+// RandomCharacterInRarity picks a character of the rarity in proportion to the character weights.
+// When the rarity mixes featured and standard characters, a featured one wins with the rarity's
+// featured chance, or always when the player lost the previous 50/50.
+func RandomCharacterInRarity(rarity *domain.Rarity, rarityMap map[int][]domain.Character, guaranteedFeatured bool, random sampler.RandomSource) *domain.Character {
+	charactersInRarity := rarityMap[rarity.Id]
+
+	if hasFeaturedCharacter(charactersInRarity) && hasStandardCharacter(charactersInRarity) {
+		featured := guaranteedFeatured || random.Float64()*100 < float64(rarity.FeaturedChance)
+
+		var candidates []domain.Character
+		for _, character := range charactersInRarity {
+			if character.Featured == featured {
+				candidates = append(candidates, character)
+			}
+		}
+		charactersInRarity = candidates
+	}
+
+	weights := make([]float64, len(charactersInRarity))
+	for i, character := range charactersInRarity {
+		weights[i] = float64(character.Weight)
+	}
+
+	characterSampler := sampler.New(weights)
+	if characterSampler == nil {
+		characterSampler = sampler.New(evenWeights(len(charactersInRarity)))
+	}
+
+	randomCharacter := charactersInRarity[characterSampler.Sample(random)]
+	return &randomCharacter
+}
+
+func evenWeights(count int) []float64 {
+	weights := make([]float64, count)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return weights
+}
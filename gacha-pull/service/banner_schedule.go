@@ -0,0 +1,62 @@
+package service
+
+import (
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"time"
+)
+
+// bannerSchedule is where a gacha system stands in its banner schedule at a moment. A gacha
+// system without banners is always open.
+type bannerSchedule struct {
+	Active *domain.Banner
+	Next   *domain.Banner
+	Last   *domain.Banner
+}
+
+// newBannerSchedule finds the running, the next and the last ended banner. banners must be
+// ordered by start time.
+func newBannerSchedule(banners []domain.Banner, now time.Time) *bannerSchedule {
+	schedule := &bannerSchedule{}
+	for i := range banners {
+		banner := &banners[i]
+		switch {
+		case !now.Before(banner.EndsAt):
+			schedule.Last = banner
+		case now.Before(banner.StartsAt):
+			if schedule.Next == nil {
+				schedule.Next = banner
+			}
+		default:
+			schedule.Active = banner
+		}
+	}
+	return schedule
+}
+
+func (schedule *bannerSchedule) isOpen() bool {
+	return schedule.Active != nil || (schedule.Next == nil && schedule.Last == nil)
+}
+
+// checkOpen rejects pulls made outside the window of every banner.
+func (schedule *bannerSchedule) checkOpen() {
+	if schedule.isOpen() {
+		return
+	}
+
+	if schedule.Next != nil {
+		panic(exception.NewBannerNotActiveError(fmt.Sprintf("No banner is running, the next banner %s starts at %s",
+			schedule.Next.Name, formatBannerTime(schedule.Next, schedule.Next.StartsAt))))
+	}
+	panic(exception.NewBannerNotActiveError(fmt.Sprintf("No banner is running, the last banner %s ended at %s",
+		schedule.Last.Name, formatBannerTime(schedule.Last, schedule.Last.EndsAt))))
+}
+
+func formatBannerTime(banner *domain.Banner, moment time.Time) string {
+	location, err := time.LoadLocation(banner.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+	return moment.In(location).Format(time.RFC3339)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/repository"
+	"gacha-pull/selection"
+)
+
+type BoxService interface {
+	FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.BoxResponse
+	Reset(ctx context.Context, endpointId string, player *domain.User) *web.BoxResponse
+}
+
+type BoxServiceImpl struct {
+	GachaPoolCache *poolcache.Cache
+	BoxRepository  repository.BoxRepository
+}
+
+func NewBoxService(
+	gachaPoolCache *poolcache.Cache,
+	boxRepository repository.BoxRepository,
+) BoxService {
+	return &BoxServiceImpl{
+		GachaPoolCache: gachaPoolCache,
+		BoxRepository:  boxRepository,
+	}
+}
+
+func (service *BoxServiceImpl) FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.BoxResponse {
+	pool := service.findBoxPool(ctx, endpointId, player)
+
+	box := service.BoxRepository.FindOrCreate(ctx, pool.GachaSystem.Id, player.Id)
+
+	return web.ToBoxResponse(pool, box)
+}
+
+func (service *BoxServiceImpl) Reset(ctx context.Context, endpointId string, player *domain.User) *web.BoxResponse {
+	pool := service.findBoxPool(ctx, endpointId, player)
+
+	box := service.BoxRepository.FindOrCreate(ctx, pool.GachaSystem.Id, player.Id)
+	if !pool.CanResetBox(box.Drawn) {
+		panic(exception.NewBadRequestError("Box can only be reset once every key item was drawn, or once it is empty"))
+	}
+
+	if !service.BoxRepository.Reset(ctx, box) {
+		panic(exception.NewConflictError("Box changed during the reset, please try again"))
+	}
+
+	return web.ToBoxResponse(pool, box)
+}
+
+func (service *BoxServiceImpl) findBoxPool(ctx context.Context, endpointId string, player *domain.User) *selection.Pool {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required for box gacha"))
+	}
+
+	pool, _ := findGachaPool(ctx, service.GachaPoolCache, endpointId)
+	if pool.GachaSystem.Mode != domain.GachaSystemModeBox {
+		panic(exception.NewBadRequestError("Gacha system is not a box gacha"))
+	}
+
+	return pool
+}
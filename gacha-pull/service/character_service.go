@@ -2,161 +2,312 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"gacha-pull/exception"
+	"gacha-pull/fairness"
 	"gacha-pull/model/domain"
 	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/receipt"
 	"gacha-pull/repository"
-	"log"
-	"math/rand"
+	"gacha-pull/sampler"
+	"gacha-pull/selection"
+	"time"
+)
+
+const (
+	DefaultMultiPullCount = 10
+	MaxMultiPullCount     = 100
 )
 
 type CharacterService interface {
-	Pull(ctx context.Context, endpointId string) *web.CharacterResponse
+	Pull(ctx context.Context, endpointId string, player *domain.User, request *web.PullRequest) *web.CharacterResponse
+	MultiPull(ctx context.Context, endpointId string, player *domain.User, request *web.PullRequest) []web.CharacterResponse
+	Reserve(ctx context.Context, endpointId string, player *domain.User, request *web.PullReservationRequest) *web.PullReservationResponse
+	Confirm(ctx context.Context, endpointId string, player *domain.User, token string) *web.PullReservationResponse
+	Cancel(ctx context.Context, endpointId string, player *domain.User, token string) *web.PullReservationResponse
+	ReleaseExpiredReservations(ctx context.Context)
 }
 
 type CharacterServiceImpl struct {
-	CharacterRepository   repository.CharacterRepository
-	RarityRepository      repository.RarityRepository
-	GachaSystemRepository repository.GachaSystemRepository
+	GachaPoolCache            *poolcache.Cache
+	PityRepository            repository.PityRepository
+	FairSeedRepository        repository.FairSeedRepository
+	PullHistoryRepository     repository.PullHistoryRepository
+	WalletRepository          repository.WalletRepository
+	InventoryRepository       repository.InventoryRepository
+	BoxRepository             repository.BoxRepository
+	SparkRepository           repository.SparkRepository
+	ReceiptSigner             *receipt.Signer
+	PullReservationRepository repository.PullReservationRepository
+	Transaction               repository.Transaction
 }
 
 func NewCharacterService(
-	characterRepository repository.CharacterRepository,
-	rarityRepository repository.RarityRepository,
-	gachaSystemRepository repository.GachaSystemRepository,
+	gachaPoolCache *poolcache.Cache,
+	pityRepository repository.PityRepository,
+	fairSeedRepository repository.FairSeedRepository,
+	pullHistoryRepository repository.PullHistoryRepository,
+	walletRepository repository.WalletRepository,
+	inventoryRepository repository.InventoryRepository,
+	boxRepository repository.BoxRepository,
+	sparkRepository repository.SparkRepository,
+	receiptSigner *receipt.Signer,
+	pullReservationRepository repository.PullReservationRepository,
+	transaction repository.Transaction,
 ) CharacterService {
 	return &CharacterServiceImpl{
-		CharacterRepository:   characterRepository,
-		RarityRepository:      rarityRepository,
-		GachaSystemRepository: gachaSystemRepository,
+		GachaPoolCache:            gachaPoolCache,
+		PityRepository:            pityRepository,
+		FairSeedRepository:        fairSeedRepository,
+		PullHistoryRepository:     pullHistoryRepository,
+		WalletRepository:          walletRepository,
+		InventoryRepository:       inventoryRepository,
+		BoxRepository:             boxRepository,
+		SparkRepository:           sparkRepository,
+		ReceiptSigner:             receiptSigner,
+		PullReservationRepository: pullReservationRepository,
+		Transaction:               transaction,
 	}
 }
 
-func (service *CharacterServiceImpl) Pull(ctx context.Context, endpointId string) *web.CharacterResponse {
-	gachaSystem := service.GachaSystemRepository.FindByEndpointId(ctx, endpointId)
-	if gachaSystem == nil {
-		panic(exception.NewNotFoundError("Gacha system not found"))
+func (service *CharacterServiceImpl) Pull(ctx context.Context, endpointId string, player *domain.User, request *web.PullRequest) *web.CharacterResponse {
+	request.Count = 1
+	characterResponses := service.MultiPull(ctx, endpointId, player, request)
+	return &characterResponses[0]
+}
+
+func (service *CharacterServiceImpl) MultiPull(ctx context.Context, endpointId string, player *domain.User, request *web.PullRequest) []web.CharacterResponse {
+	count := request.Count
+	if count < 1 || count > MaxMultiPullCount {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Pull count must be between 1 and %d", MaxMultiPullCount)))
 	}
 
-	rarities := service.RarityRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id)
-	characters := service.CharacterRepository.FindAllByGachaSystemId(ctx, gachaSystem.Id)
+	pool, schedule := findGachaPool(ctx, service.GachaPoolCache, endpointId)
+	schedule.checkOpen()
+
+	// The payment, the pulled characters, the pull history and the pity of the player are written in
+	// one transaction holding the pity lock of the player, so concurrent pulls never lose pity
+	// progress and a failed pull leaves nothing behind
+	var characterResponses []web.CharacterResponse
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		if player != nil {
+			service.PityRepository.LockByGachaSystemIdAndPlayerId(ctx, pool.GachaSystem.Id, player.Id)
+		}
+		characterResponses = service.multiPull(ctx, pool, schedule, endpointId, player, request)
+	})
+
+	return characterResponses
+}
 
-	if (rarities == nil || len(rarities) == 0) || (characters == nil || len(characters) == 0) {
-		panic(exception.NewNotFoundError("Rarities or characters not found"))
+// multiPull rolls and stores the pulls of MultiPull, inside its transaction.
+func (service *CharacterServiceImpl) multiPull(ctx context.Context, pool *selection.Pool, schedule *bannerSchedule, endpointId string, player *domain.User, request *web.PullRequest) []web.CharacterResponse {
+	count := request.Count
+
+	allCurrencies := findCurrencies(ctx, service.GachaPoolCache, endpointId)
+	currencies := pullCurrencies(allCurrencies)
+	if len(currencies) > 0 && player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required to pay for pulls"))
 	}
 
-	rarityNameMap := make(map[int]string)
-	for _, rarity := range rarities {
-		rarityNameMap[rarity.Id] = rarity.Name
+	// A box gacha draws from the box of the player instead of the rarity chances, without pity
+	var box *domain.Box
+	if pool.GachaSystem.Mode == domain.GachaSystemModeBox {
+		if player == nil {
+			panic(exception.NewUnauthorizedError("Player identity is required for box gacha"))
+		}
+		// Box draws depend on what is left in the box of a player, which the fairness proofs do not
+		// cover, so a box is never drawn from while claiming to be provably fair
+		if pool.GachaSystem.ProvablyFair {
+			panic(exception.NewConflictError("Provably fair mode is not available for box gacha systems"))
+		}
+
+		box = service.BoxRepository.FindOrCreate(ctx, pool.GachaSystem.Id, player.Id)
+		if remaining := pool.BoxRemaining(box.Drawn); remaining < count {
+			panic(exception.NewBadRequestError(fmt.Sprintf("Only %d items are left in the box", remaining)))
+		}
 	}
 
-	rarityCharsMap := make(map[int][]domain.Character)
-	for _, character := range characters {
-		rarityCharsMap[character.RarityId] = append(rarityCharsMap[character.RarityId], character)
+	if box == nil && player != nil {
+		service.checkNoOpenReservation(ctx, pool.GachaSystem.Id, player.Id)
 	}
 
-	var filteredRarities []domain.Rarity
-	for _, rarity := range rarities {
-		if len(rarityCharsMap[rarity.Id]) > 0 && rarity.Chance > 0 {
-			filteredRarities = append(filteredRarities, rarity)
-		}
+	// The pulls are paid before they are rolled, so a player who cannot pay never uses up a nonce of
+	// their seed pair. The payment is part of the transaction of the pull and is undone with it
+	// should storing the pulls fail.
+	if player != nil {
+		payForPulls(ctx, service.WalletRepository, &pool.GachaSystem, currencies, player.Id, count, request.Currency)
 	}
 
-	if len(filteredRarities) == 0 {
-		panic(exception.NewNotFoundError("Rarities or characters not found"))
+	var results []selection.Result
+	var fairPull *web.FairPullResponse
+	if box != nil {
+		results = pool.DrawFromBox(count, box.Drawn, sampler.DefaultRandomSource)
+	} else {
+		// Pity is tracked per player, so anonymous pulls never build up or consume it
+		var pities map[int]*domain.Pity
+		if player != nil {
+			pities = pool.NewPities(player.Id, service.PityRepository.FindAllByGachaSystemIdAndPlayerId(ctx, pool.GachaSystem.Id, player.Id))
+		}
+		results, fairPull = service.drawPulls(ctx, pool, player, count, pities)
 	}
 
-	selectedCharacter, rarities := SequentialRandomRarity(filteredRarities, rarityCharsMap)
+	// Another draw or a reset of the same box in the meantime would break the without replacement rule
+	if box != nil && !service.BoxRepository.SaveDraws(ctx, box, resultCharacterIds(results)) {
+		panic(exception.NewConflictError("Box changed during the draw, please draw again"))
+	}
 
-	if selectedCharacter != nil {
-		return web.ToCharacterResponse(selectedCharacter, rarityNameMap[selectedCharacter.RarityId])
+	pullIds := make([]string, count)
+	for i := range pullIds {
+		pullIds[i] = receipt.NewPullId()
 	}
 
-	log.Printf("Sequential random rarity failed, fallback to full random rarity")
+	characterResponses := service.completePulls(ctx, pool, schedule.Active, endpointId, player, results, pullIds, fairPull, allCurrencies)
 
-	if rarities == nil || len(rarities) == 0 {
-		rarities = filteredRarities
+	if lastPities := results[len(results)-1].Pities; len(lastPities) > 0 {
+		service.PityRepository.SaveAll(ctx, lastPities)
 	}
 
-	selectedCharacter = FullRandomRarity(rarities, rarityCharsMap)
-	return web.ToCharacterResponse(selectedCharacter, rarityNameMap[selectedCharacter.RarityId])
+	return characterResponses
 }
 
-func SequentialRandomRarity(rarities []domain.Rarity, rarityMap map[int][]domain.Character) (*domain.Character, []domain.Rarity) {
-	selectedRarityIdx := -1
-	totalChance := float32(100)
+// drawPulls rolls count pulls, from the seed pair of the player when the gacha system is provably
+// fair. pities holds the pity state of the player and is updated in place.
+func (service *CharacterServiceImpl) drawPulls(ctx context.Context, pool *selection.Pool, player *domain.User, count int, pities map[int]*domain.Pity) ([]selection.Result, *web.FairPullResponse) {
+	if !pool.GachaSystem.ProvablyFair {
+		return pool.Draw(count, pities, sampler.DefaultRandomSource), nil
+	}
 
-	for totalChance >= 0 && len(rarities) > 0 {
-		boostChance := rand.Float32() * totalChance
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required for provably fair pulls"))
+	}
 
-		randomIndex := rand.Intn(len(rarities))
-		selectedRarity := rarities[randomIndex]
+	fairSeed := findOrCreateFairSeed(ctx, service.FairSeedRepository, pool.GachaSystem.Id, player.Id)
+	nonce, ok := service.FairSeedRepository.UseNonce(ctx, fairSeed.Id)
+	if !ok {
+		panic(exception.NewBadRequestError("Seed pair was rotated during the pull, please pull again"))
+	}
 
-		rarityTotalChance := selectedRarity.Chance + boostChance
-		if rarityTotalChance < totalChance {
-			if randomIndex == len(rarities)-1 {
-				rarities = rarities[:randomIndex]
-			} else {
-				rarities = append(rarities[:randomIndex], rarities[randomIndex+1:]...)
-			}
-			continue
-		}
+	random := fairness.NewRandom(fairSeed.ServerSeed, fairSeed.ClientSeed, nonce)
+	fairPull := &web.FairPullResponse{
+		ServerSeedHash:  fairSeed.ServerSeedHash,
+		ClientSeed:      fairSeed.ClientSeed,
+		Nonce:           nonce,
+		PullCount:       count,
+		ConfigVersionId: pool.GachaSystem.ConfigVersionId,
+	}
+	if pool.Banner != nil {
+		bannerId := pool.Banner.Id
+		fairPull.BannerId = &bannerId
+	}
 
-		randomChance := rand.Float32() * 100
-		if randomChance < rarityTotalChance {
-			selectedRarityIdx = randomIndex
-			break
-		}
+	return pool.Draw(count, pities, random), fairPull
+}
 
-		totalChance -= rarityTotalChance
+// completePulls hands the pulled characters to the player and answers with one signed response
+// per pull: spark points are earned on the banner, characters are added to the inventory and the
+// pulls are written to the pull history.
+func (service *CharacterServiceImpl) completePulls(
+	ctx context.Context,
+	pool *selection.Pool,
+	banner *domain.Banner,
+	endpointId string,
+	player *domain.User,
+	results []selection.Result,
+	pullIds []string,
+	fairPull *web.FairPullResponse,
+	currencies []domain.Currency,
+) []web.CharacterResponse {
+	count := len(results)
+
+	// Every pull on a banner with a spark threshold earns the player one spark point
+	sparkPoints := 0
+	if player != nil && banner != nil && banner.SparkThreshold > 0 {
+		sparkPoints = service.SparkRepository.AddPoints(ctx, banner, endpointId, player.Id, count)
+	}
 
-		if randomIndex == len(rarities)-1 {
-			rarities = rarities[:randomIndex]
-		} else {
-			rarities = append(rarities[:randomIndex], rarities[randomIndex+1:]...)
-		}
+	var duplicates []*web.DuplicateResponse
+	if player != nil {
+		duplicates = collectCharacters(ctx, service.InventoryRepository, service.WalletRepository, pool.GachaSystem.Id, player.Id, results, currencies)
 	}
 
-	if selectedRarityIdx == -1 {
-		return nil, rarities
+	// Every pull gets a signed receipt, so game servers can trust results relayed by game clients
+	pulledAt := time.Now()
+	var playerId string
+	if player != nil {
+		playerId = player.Id
 	}
 
-	selectedRarity := rarities[selectedRarityIdx]
-	randomCharIndex := rand.Intn(len(rarityMap[selectedRarity.Id]))
-	randomCharacter := rarityMap[selectedRarity.Id][randomCharIndex]
-	return &randomCharacter, rarities
-}
+	var characterResponses []web.CharacterResponse
+	var pullHistories []domain.PullHistory
+	for i, result := range results {
+		characterResponse := web.ToCharacterResponse(&result.Character, result.Rarity.Name)
+		characterResponse.PullId = pullIds[i]
+		characterResponse.Receipt = service.ReceiptSigner.Sign(&receipt.Claims{
+			PullId:      pullIds[i],
+			EndpointId:  endpointId,
+			PlayerId:    playerId,
+			CharacterId: result.Character.Id,
+			RarityId:    result.Rarity.Id,
+			IssuedAt:    pulledAt.Unix(),
+		})
+		if len(result.Pities) > 0 {
+			characterResponse.Pity = web.ToPityResponses(pool.PityRarities, result.Pities)
+		}
+		if fairPull != nil {
+			fairPullResponse := *fairPull
+			fairPullResponse.PullIndex = i
+			characterResponse.Fairness = &fairPullResponse
+		}
+		if duplicates != nil {
+			isNew := duplicates[i] == nil
+			characterResponse.IsNew = &isNew
+			characterResponse.Duplicate = duplicates[i]
+		}
+		if sparkPoints > 0 {
+			pullSparkPoints := sparkPoints - count + i + 1
+			characterResponse.SparkPoints = &pullSparkPoints
+		}
 
-func FullRandomRarity(rarities []domain.Rarity, rarityMap map[int][]domain.Character) *domain.Character {
-	selectedCharacters := []domain.Character{}
+		characterResponses = append(characterResponses, *characterResponse)
 
-	for _, rarity := range rarities {
-		charactersInRarity := rarityMap[rarity.Id]
-		totalCharactersInRarity := len(charactersInRarity)
+		if player != nil {
+			pullHistories = append(pullHistories, toPullHistory(pool, player.Id, pullIds[i], &result))
+		}
+	}
 
-		randomChance := rand.Float32() * 100
+	if len(pullHistories) > 0 {
+		service.PullHistoryRepository.SaveAll(ctx, pullHistories)
+	}
 
-		if randomChance < rarity.Chance {
-			randomIndex := rand.Intn(totalCharactersInRarity)
-			selectedCharacter := charactersInRarity[randomIndex]
+	return characterResponses
+}
 
-			selectedCharacters = append(selectedCharacters, selectedCharacter)
-		}
+func toPullHistory(pool *selection.Pool, playerId string, pullId string, result *selection.Result) domain.PullHistory {
+	pullHistory := domain.PullHistory{
+		PullId:        pullId,
+		GachaSystemId: pool.GachaSystem.Id,
+		PlayerId:      playerId,
+		CharacterId:   result.Character.Id,
+		CharacterName: result.Character.Name,
+		RarityId:      result.Rarity.Id,
+		RarityName:    result.Rarity.Name,
+		Featured:      result.Character.Featured,
 	}
 
-	totalSelectedCharacters := len(selectedCharacters)
-	if totalSelectedCharacters == 0 {
-		randomIndex := rand.Intn(len(rarities))
-		selectedRarity := rarities[randomIndex]
-
-		totalCharactersInRarity := len(rarityMap[selectedRarity.Id])
-		randomIndex = rand.Intn(totalCharactersInRarity)
+	if configVersionId := pool.GachaSystem.ConfigVersionId; configVersionId != 0 {
+		pullHistory.ConfigVersionId = &configVersionId
+	}
 
-		randomCharacter := rarityMap[selectedRarity.Id][randomIndex]
-		return &randomCharacter
+	for i, pity := range result.Pities {
+		pullHistory.Pity = append(pullHistory.Pity, domain.PullHistoryPity{
+			RarityId:           pity.RarityId,
+			RarityName:         pool.PityRarities[i].Name,
+			Counter:            pity.Counter,
+			Threshold:          pool.PityRarities[i].PityThreshold,
+			GuaranteedFeatured: pity.GuaranteedFeatured,
+		})
 	}
 
-	randomIndex := rand.Intn(totalSelectedCharacters)
-	selectedCharacter := selectedCharacters[randomIndex]
-	return &selectedCharacter
+	return pullHistory
 }
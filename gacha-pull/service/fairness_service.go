@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/fairness"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/repository"
+	"gacha-pull/selection"
+	"time"
+)
+
+const MaxClientSeedLength = 64
+
+type FairnessService interface {
+	FindActiveSeed(ctx context.Context, endpointId string, player *domain.User) *web.FairSeedResponse
+	RotateSeed(ctx context.Context, endpointId string, player *domain.User, request *web.FairSeedRotateRequest) *web.FairSeedRotateResponse
+	Verify(ctx context.Context, endpointId string, request *web.FairVerifyRequest) *web.FairVerifyResponse
+}
+
+type FairnessServiceImpl struct {
+	GachaPoolCache            *poolcache.Cache
+	FairSeedRepository        repository.FairSeedRepository
+	PityRepository            repository.PityRepository
+	PullReservationRepository repository.PullReservationRepository
+	Transaction               repository.Transaction
+}
+
+func NewFairnessService(
+	gachaPoolCache *poolcache.Cache,
+	fairSeedRepository repository.FairSeedRepository,
+	pityRepository repository.PityRepository,
+	pullReservationRepository repository.PullReservationRepository,
+	transaction repository.Transaction,
+) FairnessService {
+	return &FairnessServiceImpl{
+		GachaPoolCache:            gachaPoolCache,
+		FairSeedRepository:        fairSeedRepository,
+		PityRepository:            pityRepository,
+		PullReservationRepository: pullReservationRepository,
+		Transaction:               transaction,
+	}
+}
+
+func (service *FairnessServiceImpl) FindActiveSeed(ctx context.Context, endpointId string, player *domain.User) *web.FairSeedResponse {
+	gachaSystem := service.findProvablyFairGachaSystem(ctx, endpointId, player)
+
+	fairSeed := findOrCreateFairSeed(ctx, service.FairSeedRepository, gachaSystem.Id, player.Id)
+
+	return web.ToFairSeedResponse(fairSeed)
+}
+
+func (service *FairnessServiceImpl) RotateSeed(ctx context.Context, endpointId string, player *domain.User, request *web.FairSeedRotateRequest) *web.FairSeedRotateResponse {
+	if len(request.ClientSeed) > MaxClientSeedLength {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Client seed must be at most %d characters", MaxClientSeedLength)))
+	}
+
+	gachaSystem := service.findProvablyFairGachaSystem(ctx, endpointId, player)
+
+	// Revealing the server seed reveals the pulls of an open reservation made with it, so the seed
+	// pair cannot be rotated before the reservation is resolved. The pity lock keeps a reservation
+	// from being made while rotating.
+	var fairSeed, nextFairSeed *domain.FairSeed
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		service.PityRepository.LockByGachaSystemIdAndPlayerId(ctx, gachaSystem.Id, player.Id)
+
+		reservation := service.PullReservationRepository.FindOpenByGachaSystemIdAndPlayerId(ctx, gachaSystem.Id, player.Id)
+		if reservation != nil && time.Now().Before(reservation.ExpiresAt) {
+			panic(exception.NewConflictError("Player holds an open reservation, confirm or cancel it before rotating the seed pair"))
+		}
+
+		fairSeed = findOrCreateFairSeed(ctx, service.FairSeedRepository, gachaSystem.Id, player.Id)
+
+		nextFairSeed = newFairSeed(gachaSystem.Id, player.Id, request.ClientSeed)
+		if !service.FairSeedRepository.Rotate(ctx, fairSeed.Id, nextFairSeed) {
+			panic(exception.NewBadRequestError("Seed pair was already rotated"))
+		}
+	})
+
+	return &web.FairSeedRotateResponse{
+		Revealed: web.ToRevealedFairSeedResponse(fairSeed),
+		Active:   web.ToFairSeedResponse(nextFairSeed),
+	}
+}
+
+func (service *FairnessServiceImpl) Verify(ctx context.Context, endpointId string, request *web.FairVerifyRequest) *web.FairVerifyResponse {
+	if request.ServerSeed == "" || request.ClientSeed == "" {
+		panic(exception.NewBadRequestError("Server seed and client seed are required"))
+	}
+	if request.Nonce < 0 {
+		panic(exception.NewBadRequestError("Nonce must not be negative"))
+	}
+	if request.PullCount == 0 {
+		request.PullCount = 1
+	}
+	if request.PullCount < 1 || request.PullCount > MaxMultiPullCount {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Pull count must be between 1 and %d", MaxMultiPullCount)))
+	}
+
+	// Pulls are replayed with the config version and banner they were made with, which may have
+	// been replaced since
+	entry := findVersionConfig(ctx, service.GachaPoolCache, endpointId, request.ConfigVersionId)
+
+	var banner *domain.Banner
+	if request.BannerId != nil {
+		banner = entry.FindBanner(*request.BannerId)
+		if banner == nil {
+			panic(exception.NewNotFoundError("Banner not found in the config version"))
+		}
+	}
+
+	pool := compilePool(entry, banner)
+	if !pool.GachaSystem.ProvablyFair {
+		panic(exception.NewBadRequestError("Provably fair mode is not enabled"))
+	}
+
+	pities := pool.NewPities("", toVerifyPities(pool, request.Pity))
+	results := fairness.Replay(pool, request.ServerSeed, request.ClientSeed, request.Nonce, request.PullCount, pities)
+
+	var characterResponses []web.CharacterResponse
+	for _, result := range results {
+		characterResponse := web.ToCharacterResponse(&result.Character, result.Rarity.Name)
+		if len(result.Pities) > 0 {
+			characterResponse.Pity = web.ToPityResponses(pool.PityRarities, result.Pities)
+		}
+		characterResponses = append(characterResponses, *characterResponse)
+	}
+
+	serverSeedHash := fairness.HashServerSeed(request.ServerSeed)
+	return &web.FairVerifyResponse{
+		ServerSeedHash: serverSeedHash,
+		HashMatches:    request.ServerSeedHash == "" || fairness.VerifyServerSeed(request.ServerSeed, request.ServerSeedHash),
+		Characters:     characterResponses,
+	}
+}
+
+func (service *FairnessServiceImpl) findProvablyFairGachaSystem(ctx context.Context, endpointId string, player *domain.User) *domain.GachaSystem {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required for provably fair pulls"))
+	}
+
+	gachaSystem := &findGachaConfig(ctx, service.GachaPoolCache, endpointId).GachaSystem
+	if !gachaSystem.ProvablyFair {
+		panic(exception.NewBadRequestError("Provably fair mode is not enabled"))
+	}
+
+	return gachaSystem
+}
+
+// toVerifyPities maps the pity state a player saw before the pulls, keyed by rarity name as in the
+// pull responses, back to the rarities of the pool.
+func toVerifyPities(pool *selection.Pool, pityRequests []web.FairVerifyPityRequest) []domain.Pity {
+	var pities []domain.Pity
+	for _, pityRequest := range pityRequests {
+		for _, rarity := range pool.PityRarities {
+			if rarity.Name == pityRequest.Rarity {
+				pities = append(pities, domain.Pity{
+					RarityId:           rarity.Id,
+					Counter:            pityRequest.Counter,
+					GuaranteedFeatured: pityRequest.GuaranteedFeatured,
+				})
+			}
+		}
+	}
+	return pities
+}
+
+func findOrCreateFairSeed(ctx context.Context, fairSeedRepository repository.FairSeedRepository, gachaSystemId int, playerId string) *domain.FairSeed {
+	fairSeed := fairSeedRepository.FindActiveByGachaSystemIdAndPlayerId(ctx, gachaSystemId, playerId)
+	if fairSeed != nil {
+		return fairSeed
+	}
+
+	fairSeed = fairSeedRepository.Save(ctx, newFairSeed(gachaSystemId, playerId, ""))
+	if fairSeed == nil {
+		panic(exception.NewNotFoundError("Seed pair not found"))
+	}
+
+	return fairSeed
+}
+
+func newFairSeed(gachaSystemId int, playerId string, clientSeed string) *domain.FairSeed {
+	if clientSeed == "" {
+		clientSeed = fairness.NewClientSeed()
+	}
+
+	serverSeed := fairness.NewServerSeed()
+	return &domain.FairSeed{
+		GachaSystemId:  gachaSystemId,
+		PlayerId:       playerId,
+		ServerSeed:     serverSeed,
+		ServerSeedHash: fairness.HashServerSeed(serverSeed),
+		ClientSeed:     clientSeed,
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"gacha-pull/poolcache"
+)
+
+type GachaConfigService interface {
+	Invalidate(ctx context.Context, endpointId string)
+}
+
+type GachaConfigServiceImpl struct {
+	GachaPoolCache *poolcache.Cache
+}
+
+func NewGachaConfigService(
+	gachaPoolCache *poolcache.Cache,
+) GachaConfigService {
+	return &GachaConfigServiceImpl{
+		GachaPoolCache: gachaPoolCache,
+	}
+}
+
+// Invalidate drops the cached configuration of the gacha system behind an endpoint once
+// gacha-master changed it, so the change reaches pulls without waiting for the cache to expire.
+func (service *GachaConfigServiceImpl) Invalidate(ctx context.Context, endpointId string) {
+	service.GachaPoolCache.Invalidate(endpointId)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/poolcache"
+	"gacha-pull/selection"
+	"time"
+)
+
+// findGachaConfig returns the cached configuration of the gacha system behind an endpoint.
+func findGachaConfig(ctx context.Context, gachaPoolCache *poolcache.Cache, endpointId string) *poolcache.Entry {
+	entry := gachaPoolCache.Find(ctx, endpointId)
+	if entry == nil {
+		panic(exception.NewNotFoundError("Gacha system not found"))
+	}
+
+	return entry
+}
+
+// findVersionConfig returns the cached configuration of a published config version of the
+// gacha system behind an endpoint, or the active one when versionId is 0.
+func findVersionConfig(ctx context.Context, gachaPoolCache *poolcache.Cache, endpointId string, versionId int) *poolcache.Entry {
+	if versionId == 0 {
+		return findGachaConfig(ctx, gachaPoolCache, endpointId)
+	}
+
+	entry := gachaPoolCache.FindVersion(ctx, endpointId, versionId)
+	if entry == nil {
+		panic(exception.NewNotFoundError("Config version not found"))
+	}
+
+	return entry
+}
+
+// findCurrencies returns the currencies of the gacha system behind an endpoint.
+func findCurrencies(ctx context.Context, gachaPoolCache *poolcache.Cache, endpointId string) []domain.Currency {
+	return findGachaConfig(ctx, gachaPoolCache, endpointId).Currencies
+}
+
+// findGachaPool returns the pool of a gacha system with the banner running now. Pulls must check
+// the returned schedule is open, other readers of the pool may use it between banners. The pool is
+// shared with other requests and must not be changed.
+func findGachaPool(ctx context.Context, gachaPoolCache *poolcache.Cache, endpointId string) (*selection.Pool, *bannerSchedule) {
+	entry := findGachaConfig(ctx, gachaPoolCache, endpointId)
+
+	schedule := newBannerSchedule(entry.Banners, time.Now())
+
+	return compilePool(entry, schedule.Active), schedule
+}
+
+// compilePool returns the pool of a cached configuration with banner. A gacha system whose rarity
+// chances do not add up to 100 is refused rather than pulled with scaled chances.
+func compilePool(entry *poolcache.Entry, banner *domain.Banner) *selection.Pool {
+	pool, err := entry.Pool(banner)
+	switch {
+	case errors.Is(err, selection.ErrNothingToPull):
+		panic(exception.NewNotFoundError("Rarities or characters not found"))
+	case errors.Is(err, selection.ErrChanceTotal):
+		panic(exception.NewConflictError("Gacha system is misconfigured: " + err.Error()))
+	}
+
+	return pool
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/repository"
+	"gacha-pull/selection"
+)
+
+const WalletReasonDuplicate = "duplicate"
+
+type InventoryService interface {
+	FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.InventoryResponse
+}
+
+type InventoryServiceImpl struct {
+	GachaPoolCache      *poolcache.Cache
+	InventoryRepository repository.InventoryRepository
+}
+
+func NewInventoryService(
+	gachaPoolCache *poolcache.Cache,
+	inventoryRepository repository.InventoryRepository,
+) InventoryService {
+	return &InventoryServiceImpl{
+		GachaPoolCache:      gachaPoolCache,
+		InventoryRepository: inventoryRepository,
+	}
+}
+
+func (service *InventoryServiceImpl) FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.InventoryResponse {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required to read the inventory"))
+	}
+
+	entry := findGachaConfig(ctx, service.GachaPoolCache, endpointId)
+	gachaSystem := &entry.GachaSystem
+
+	rarityMap := make(map[int]domain.Rarity)
+	for _, rarity := range entry.Rarities {
+		rarityMap[rarity.Id] = rarity
+	}
+
+	characters := entry.Characters
+	characterMap := make(map[int]domain.Character)
+	for _, character := range characters {
+		characterMap[character.Id] = character
+	}
+
+	inventoryResponse := &web.InventoryResponse{
+		PlayerId:        player.Id,
+		Characters:      []web.InventoryItemResponse{},
+		TotalCharacters: len(characters),
+	}
+
+	for _, inventoryItem := range service.InventoryRepository.FindAllByGachaSystemIdAndPlayerId(ctx, gachaSystem.Id, player.Id) {
+		character, ok := characterMap[inventoryItem.CharacterId]
+		if !ok {
+			continue
+		}
+
+		rarity := rarityMap[character.RarityId]
+		constellation, _ := convertDuplicate(&rarity, inventoryItem.Copies)
+		inventoryResponse.Characters = append(inventoryResponse.Characters, web.InventoryItemResponse{
+			Name:            character.Name,
+			ImageUrl:        character.ImageUrl,
+			Rarity:          rarity.Name,
+			Featured:        character.Featured,
+			Copies:          inventoryItem.Copies,
+			Constellation:   constellation,
+			FirstObtainedAt: inventoryItem.FirstObtainedAt,
+		})
+	}
+	inventoryResponse.OwnedCharacters = len(inventoryResponse.Characters)
+
+	return inventoryResponse
+}
+
+// convertDuplicate returns the constellation level of a character the player owns copies of and
+// the shards the latest copy converts into, following the duplicate conversion of its rarity.
+func convertDuplicate(rarity *domain.Rarity, copies int) (int, int) {
+	if copies < 2 {
+		return 0, 0
+	}
+
+	switch rarity.DuplicateConversion {
+	case domain.DuplicateConversionShards:
+		return 0, rarity.DuplicateShards
+	case domain.DuplicateConversionConstellation:
+		if copies-1 <= rarity.MaxConstellation {
+			return copies - 1, 0
+		}
+		if rarity.DuplicateCurrencyId == nil {
+			return rarity.MaxConstellation, 0
+		}
+		return rarity.MaxConstellation, rarity.DuplicateShards
+	}
+
+	return 0, 0
+}
+
+// collectCharacters adds the pulled characters to the inventory of the player and credits the
+// shards their duplicates convert into. It returns the duplicate outcome of every pull in pull
+// order, nil for a character the player did not own yet.
+func collectCharacters(
+	ctx context.Context,
+	inventoryRepository repository.InventoryRepository,
+	walletRepository repository.WalletRepository,
+	gachaSystemId int,
+	playerId string,
+	results []selection.Result,
+	currencies []domain.Currency,
+) []*web.DuplicateResponse {
+	currencyNameMap := make(map[int]string)
+	for _, currency := range currencies {
+		currencyNameMap[currency.Id] = currency.Name
+	}
+
+	copies := inventoryRepository.AddAll(ctx, gachaSystemId, playerId, resultCharacterIds(results))
+
+	duplicates := make([]*web.DuplicateResponse, len(results))
+	shardMap := make(map[int]int64)
+	duplicateCountMap := make(map[int]int)
+	var shardCurrencyIds []int
+	for i, result := range results {
+		if copies[i] < 2 {
+			continue
+		}
+
+		constellation, shards := convertDuplicate(&result.Rarity, copies[i])
+		duplicate := &web.DuplicateResponse{
+			Copies:        copies[i],
+			Constellation: constellation,
+		}
+
+		// Shards of a currency deleted since the rarity was configured are dropped
+		if shards > 0 {
+			currencyId := *result.Rarity.DuplicateCurrencyId
+			if currencyName, ok := currencyNameMap[currencyId]; ok {
+				duplicate.Currency = currencyName
+				duplicate.Shards = shards
+
+				if _, ok := shardMap[currencyId]; !ok {
+					shardCurrencyIds = append(shardCurrencyIds, currencyId)
+				}
+				shardMap[currencyId] += int64(shards)
+				duplicateCountMap[currencyId]++
+			}
+		}
+
+		duplicates[i] = duplicate
+	}
+
+	for _, currencyId := range shardCurrencyIds {
+		walletRepository.ApplyEntry(ctx, &domain.WalletLedgerEntry{
+			GachaSystemId: gachaSystemId,
+			PlayerId:      playerId,
+			CurrencyId:    currencyId,
+			Amount:        shardMap[currencyId],
+			Reason:        WalletReasonDuplicate,
+			Note:          fmt.Sprintf("%d duplicate(s)", duplicateCountMap[currencyId]),
+		})
+	}
+
+	return duplicates
+}
+
+func resultCharacterIds(results []selection.Result) []int {
+	characterIds := make([]int, len(results))
+	for i, result := range results {
+		characterIds[i] = result.Character.Id
+	}
+	return characterIds
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/repository"
+)
+
+const (
+	DefaultPullHistoryPageSize = 20
+	MaxPullHistoryPageSize     = 100
+)
+
+type PullHistoryService interface {
+	FindAll(ctx context.Context, endpointId string, player *domain.User, request *web.PullHistoryRequest) *web.PullHistoryPageResponse
+}
+
+type PullHistoryServiceImpl struct {
+	GachaPoolCache        *poolcache.Cache
+	PullHistoryRepository repository.PullHistoryRepository
+}
+
+func NewPullHistoryService(gachaPoolCache *poolcache.Cache, pullHistoryRepository repository.PullHistoryRepository) PullHistoryService {
+	return &PullHistoryServiceImpl{
+		GachaPoolCache:        gachaPoolCache,
+		PullHistoryRepository: pullHistoryRepository,
+	}
+}
+
+func (service *PullHistoryServiceImpl) FindAll(ctx context.Context, endpointId string, player *domain.User, request *web.PullHistoryRequest) *web.PullHistoryPageResponse {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required to read the pull history"))
+	}
+	if request.Page == 0 {
+		request.Page = 1
+	}
+	if request.Size == 0 {
+		request.Size = DefaultPullHistoryPageSize
+	}
+	if request.Page < 1 {
+		panic(exception.NewBadRequestError("Page must be at least 1"))
+	}
+	if request.Size < 1 || request.Size > MaxPullHistoryPageSize {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Page size must be between 1 and %d", MaxPullHistoryPageSize)))
+	}
+	if request.From != nil && request.To != nil && !request.From.Before(*request.To) {
+		panic(exception.NewBadRequestError("From must be before to"))
+	}
+
+	gachaSystem := &findGachaConfig(ctx, service.GachaPoolCache, endpointId).GachaSystem
+
+	pullHistories, totalPulls := service.PullHistoryRepository.FindAll(ctx, &domain.PullHistoryFilter{
+		GachaSystemId: gachaSystem.Id,
+		PlayerId:      player.Id,
+		RarityName:    request.Rarity,
+		From:          request.From,
+		To:            request.To,
+		Limit:         request.Size,
+		Offset:        (request.Page - 1) * request.Size,
+	})
+
+	return &web.PullHistoryPageResponse{
+		Pulls:      web.ToPullHistoryResponses(pullHistories),
+		Page:       request.Page,
+		Size:       request.Size,
+		TotalPulls: totalPulls,
+		TotalPages: (totalPulls + request.Size - 1) / request.Size,
+	}
+}
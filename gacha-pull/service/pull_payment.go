@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/repository"
+	"math"
+	"strings"
+)
+
+const (
+	WalletReasonPull = "pull"
+)
+
+// PullPrice returns the price of count pulls paid with a currency. Pulling at least the multi-pull
+// discount count at once takes the multi-pull discount off, rounding in favour of the player.
+func PullPrice(gachaSystem *domain.GachaSystem, currency *domain.Currency, count int) int64 {
+	price := int64(currency.PullCost) * int64(count)
+	if gachaSystem.MultiPullDiscount > 0 && gachaSystem.MultiPullDiscountCount > 0 && count >= gachaSystem.MultiPullDiscountCount {
+		price = int64(math.Floor(float64(price) * float64(100-gachaSystem.MultiPullDiscount) / 100))
+	}
+	return price
+}
+
+// pullCurrencies returns the currencies pulls can be paid with, i.e. the ones with a pull cost.
+func pullCurrencies(currencies []domain.Currency) []domain.Currency {
+	var paidCurrencies []domain.Currency
+	for _, currency := range currencies {
+		if currency.PullCost > 0 {
+			paidCurrencies = append(paidCurrencies, currency)
+		}
+	}
+	return paidCurrencies
+}
+
+// payForPulls debits the wallet of the player. Without a currency name the first currency the
+// player can afford is used. It returns nil when the gacha system has no pull cost.
+func payForPulls(
+	ctx context.Context,
+	walletRepository repository.WalletRepository,
+	gachaSystem *domain.GachaSystem,
+	currencies []domain.Currency,
+	playerId string,
+	count int,
+	currencyName string,
+) *domain.WalletLedgerEntry {
+	if len(currencies) == 0 {
+		if currencyName != "" {
+			panic(exception.NewBadRequestError("Pulls in this gacha system are free"))
+		}
+		return nil
+	}
+
+	candidates := currencies
+	if currencyName != "" {
+		candidates = nil
+		for _, currency := range currencies {
+			if strings.EqualFold(currency.Name, currencyName) {
+				candidates = append(candidates, currency)
+			}
+		}
+		if len(candidates) == 0 {
+			panic(exception.NewBadRequestError(fmt.Sprintf("Currency %s cannot be used for pulls", currencyName)))
+		}
+	}
+
+	var prices []string
+	for _, currency := range candidates {
+		price := PullPrice(gachaSystem, &currency, count)
+		if price == 0 {
+			return nil
+		}
+
+		entry := domain.WalletLedgerEntry{
+			GachaSystemId: gachaSystem.Id,
+			PlayerId:      playerId,
+			CurrencyId:    currency.Id,
+			Amount:        -price,
+			Reason:        WalletReasonPull,
+			Note:          fmt.Sprintf("%d pull(s)", count),
+		}
+		if walletRepository.ApplyEntry(ctx, &entry) {
+			return &entry
+		}
+		prices = append(prices, fmt.Sprintf("%d %s", price, currency.Name))
+	}
+
+	panic(exception.NewPaymentRequiredError(fmt.Sprintf("Insufficient balance, %d pull(s) cost %s", count, strings.Join(prices, " or "))))
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/receipt"
+	"gacha-pull/selection"
+	"log"
+	"time"
+)
+
+const (
+	DefaultReservationTtl = 15 * time.Minute
+	MinReservationTtl     = time.Minute
+	MaxReservationTtl     = 24 * time.Hour
+	// ReservationSweepInterval is how often expired reservations are released
+	ReservationSweepInterval = time.Minute
+	reservationSweepBatch    = 100
+)
+
+// Reserve rolls pulls for a player without revealing or handing them out. The pulls are paid
+// outside of the gacha system, so no currency is spent: the game server confirms the reservation
+// once the payment cleared, or cancels it. The pity of the player moves on right away, so a player
+// holds at most one open reservation per gacha system and cannot pull in the meantime. The check
+// for an open reservation and the pity update happen in one transaction holding the pity lock of
+// the player, so a concurrent pull cannot slip in between.
+func (service *CharacterServiceImpl) Reserve(ctx context.Context, endpointId string, player *domain.User, request *web.PullReservationRequest) *web.PullReservationResponse {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required to reserve pulls"))
+	}
+	if request.Count < 1 || request.Count > MaxMultiPullCount {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Pull count must be between 1 and %d", MaxMultiPullCount)))
+	}
+	if request.Ttl == 0 {
+		request.Ttl = DefaultReservationTtl
+	}
+	if request.Ttl < MinReservationTtl || request.Ttl > MaxReservationTtl {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Reservation ttl must be between %d and %d seconds",
+			int(MinReservationTtl.Seconds()), int(MaxReservationTtl.Seconds()))))
+	}
+
+	pool, schedule := findGachaPool(ctx, service.GachaPoolCache, endpointId)
+	schedule.checkOpen()
+	if pool.GachaSystem.Mode == domain.GachaSystemModeBox {
+		panic(exception.NewBadRequestError("Box gacha pulls cannot be reserved"))
+	}
+
+	var reservation *domain.PullReservation
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		service.PityRepository.LockByGachaSystemIdAndPlayerId(ctx, pool.GachaSystem.Id, player.Id)
+		reservation = service.reserve(ctx, pool, schedule, player, request)
+	})
+
+	return web.ToPullReservationResponse(reservation)
+}
+
+// reserve rolls and stores the reservation of Reserve, inside its transaction.
+func (service *CharacterServiceImpl) reserve(ctx context.Context, pool *selection.Pool, schedule *bannerSchedule, player *domain.User, request *web.PullReservationRequest) *domain.PullReservation {
+	service.checkNoOpenReservation(ctx, pool.GachaSystem.Id, player.Id)
+
+	pities := pool.NewPities(player.Id, service.PityRepository.FindAllByGachaSystemIdAndPlayerId(ctx, pool.GachaSystem.Id, player.Id))
+	var pityBefore []domain.Pity
+	for _, rarity := range pool.PityRarities {
+		pityBefore = append(pityBefore, *pities[rarity.Id])
+	}
+
+	results, fairPull := service.drawPulls(ctx, pool, player, request.Count, pities)
+
+	reservation := &domain.PullReservation{
+		Token:           newReservationToken(),
+		GachaSystemId:   pool.GachaSystem.Id,
+		ConfigVersionId: pool.GachaSystem.ConfigVersionId,
+		PlayerId:        player.Id,
+		PityBefore:      pityBefore,
+		ExpiresAt:       time.Now().Add(request.Ttl),
+	}
+	if schedule.Active != nil {
+		reservation.BannerId = &schedule.Active.Id
+	}
+	if fairPull != nil {
+		reservation.FairPull = &domain.ReservedFairPull{
+			ServerSeedHash: fairPull.ServerSeedHash,
+			ClientSeed:     fairPull.ClientSeed,
+			Nonce:          fairPull.Nonce,
+		}
+	}
+	for _, result := range results {
+		reservation.Pulls = append(reservation.Pulls, domain.ReservedPull{
+			PullId:      receipt.NewPullId(),
+			CharacterId: result.Character.Id,
+			RarityId:    result.Rarity.Id,
+			Featured:    result.Character.Featured,
+			Pities:      result.Pities,
+		})
+	}
+
+	if !service.PullReservationRepository.Save(ctx, reservation) {
+		panic(exception.NewConflictError("Player already holds an open reservation"))
+	}
+
+	if lastPities := results[len(results)-1].Pities; len(lastPities) > 0 {
+		service.PityRepository.SaveAll(ctx, lastPities)
+	}
+
+	return reservation
+}
+
+// Confirm hands the reserved pulls to the player and reveals them. The reservation is confirmed
+// at most once, and only before it expires. Closing the reservation and handing out the pulls
+// happen in one transaction, so a failed confirmation leaves the reservation open. The pulls are
+// delivered with the config version and banner they were rolled with, even when another version
+// was published in the meantime.
+func (service *CharacterServiceImpl) Confirm(ctx context.Context, endpointId string, player *domain.User, token string) *web.PullReservationResponse {
+	entry := findGachaConfig(ctx, service.GachaPoolCache, endpointId)
+
+	reservation := service.findOpenReservation(ctx, entry.GachaSystem.Id, player, token)
+
+	reservedEntry := findVersionConfig(ctx, service.GachaPoolCache, endpointId, reservation.ConfigVersionId)
+	var banner *domain.Banner
+	if reservation.BannerId != nil {
+		banner = reservedEntry.FindBanner(*reservation.BannerId)
+	}
+	pool := compilePool(reservedEntry, banner)
+
+	// The results are looked up before the reservation is closed, so one that cannot be delivered
+	// anymore stays open and gives the pity back once it expires
+	results := reservedResults(pool, reservation)
+
+	var fairPull *web.FairPullResponse
+	if reservation.FairPull != nil {
+		fairPull = &web.FairPullResponse{
+			ServerSeedHash:  reservation.FairPull.ServerSeedHash,
+			ClientSeed:      reservation.FairPull.ClientSeed,
+			Nonce:           reservation.FairPull.Nonce,
+			PullCount:       len(results),
+			ConfigVersionId: reservation.ConfigVersionId,
+			BannerId:        reservation.BannerId,
+		}
+	}
+
+	pullIds := make([]string, len(reservation.Pulls))
+	for i, pull := range reservation.Pulls {
+		pullIds[i] = pull.PullId
+	}
+
+	currencies := entry.Currencies
+
+	var characterResponses []web.CharacterResponse
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		service.PityRepository.LockByGachaSystemIdAndPlayerId(ctx, reservation.GachaSystemId, reservation.PlayerId)
+		if !service.PullReservationRepository.Resolve(ctx, reservation, domain.PullReservationConfirmed) {
+			panic(exception.NewConflictError("Reservation was resolved or expired in the meantime"))
+		}
+
+		characterResponses = service.completePulls(ctx, pool, banner, endpointId, player, results, pullIds, fairPull, currencies)
+	})
+
+	reservationResponse := web.ToPullReservationResponse(reservation)
+	reservationResponse.Characters = characterResponses
+
+	return reservationResponse
+}
+
+// Cancel drops the reserved pulls and gives the player their pity back, unless they were provably
+// fair, see releaseReservation.
+func (service *CharacterServiceImpl) Cancel(ctx context.Context, endpointId string, player *domain.User, token string) *web.PullReservationResponse {
+	gachaSystem := &findGachaConfig(ctx, service.GachaPoolCache, endpointId).GachaSystem
+
+	reservation := service.findOpenReservation(ctx, gachaSystem.Id, player, token)
+
+	if !service.releaseReservation(ctx, reservation, domain.PullReservationCancelled) {
+		panic(exception.NewConflictError("Reservation was resolved in the meantime"))
+	}
+
+	return web.ToPullReservationResponse(reservation)
+}
+
+// ReleaseExpiredReservations closes the reservations that were neither confirmed nor cancelled in
+// time and gives the players their pity back like Cancel does.
+func (service *CharacterServiceImpl) ReleaseExpiredReservations(ctx context.Context) {
+	for {
+		reservations := service.PullReservationRepository.FindAllExpired(ctx, reservationSweepBatch)
+		for i := range reservations {
+			if service.releaseReservation(ctx, &reservations[i], domain.PullReservationExpired) {
+				log.Printf("Released expired pull reservation of player %s on gacha system %d", reservations[i].PlayerId, reservations[i].GachaSystemId)
+			}
+		}
+
+		if len(reservations) < reservationSweepBatch {
+			return
+		}
+	}
+}
+
+// checkNoOpenReservation rejects pulls of a player holding an open reservation, their pity already
+// moved on for the reserved pulls. A reservation whose time ran out is released on the spot.
+func (service *CharacterServiceImpl) checkNoOpenReservation(ctx context.Context, gachaSystemId int, playerId string) {
+	reservation := service.PullReservationRepository.FindOpenByGachaSystemIdAndPlayerId(ctx, gachaSystemId, playerId)
+	if reservation == nil {
+		return
+	}
+
+	if time.Now().Before(reservation.ExpiresAt) || !service.releaseReservation(ctx, reservation, domain.PullReservationExpired) {
+		panic(exception.NewConflictError("Player holds an open reservation, confirm or cancel it first"))
+	}
+}
+
+// findOpenReservation finds a reservation a trusted game server may resolve for the player.
+func (service *CharacterServiceImpl) findOpenReservation(ctx context.Context, gachaSystemId int, player *domain.User, token string) *domain.PullReservation {
+	if player == nil || !player.GameServer {
+		panic(exception.NewUnauthorizedError("Reservations can only be resolved by a trusted game server"))
+	}
+
+	reservation := service.PullReservationRepository.FindByToken(ctx, token)
+	if reservation == nil || reservation.GachaSystemId != gachaSystemId || reservation.PlayerId != player.Id {
+		panic(exception.NewNotFoundError("Reservation not found"))
+	}
+	if reservation.Status != domain.PullReservationReserved {
+		panic(exception.NewConflictError(fmt.Sprintf("Reservation is already %s", reservation.Status)))
+	}
+	if !time.Now().Before(reservation.ExpiresAt) {
+		service.releaseReservation(ctx, reservation, domain.PullReservationExpired)
+		panic(exception.NewConflictError("Reservation is already expired"))
+	}
+
+	return reservation
+}
+
+// releaseReservation closes a reservation without handing out its pulls and puts the pity of the
+// player back to where it was before. It reports false when the reservation was already closed.
+//
+// A provably fair reservation keeps the pity and the nonce it used up: once the seed pair is
+// revealed its hidden pulls can be computed, and giving the pity back would let a player who
+// dislikes them reroll for free by letting the reservation lapse.
+func (service *CharacterServiceImpl) releaseReservation(ctx context.Context, reservation *domain.PullReservation, status string) bool {
+	released := false
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		service.PityRepository.LockByGachaSystemIdAndPlayerId(ctx, reservation.GachaSystemId, reservation.PlayerId)
+		if !service.PullReservationRepository.Resolve(ctx, reservation, status) {
+			return
+		}
+		released = true
+
+		if reservation.FairPull == nil && len(reservation.PityBefore) > 0 {
+			service.PityRepository.SaveAll(ctx, reservation.PityBefore)
+		}
+	})
+
+	return released
+}
+
+// reservedResults looks the reserved pulls up in the pool again. The pity of a pull is left out
+// when the pity rarities of the gacha system changed since the reservation.
+func reservedResults(pool *selection.Pool, reservation *domain.PullReservation) []selection.Result {
+	var results []selection.Result
+	for _, pull := range reservation.Pulls {
+		var character *domain.Character
+		for _, rarityCharacter := range pool.RarityCharsMap[pull.RarityId] {
+			if rarityCharacter.Id == pull.CharacterId {
+				character = &rarityCharacter
+				break
+			}
+		}
+		if character == nil {
+			panic(exception.NewNotFoundError("Reserved character is no longer available"))
+		}
+		character.Featured = pull.Featured
+
+		result := selection.Result{
+			Character: *character,
+			Rarity:    domain.Rarity{Id: pull.RarityId, Name: pool.RarityNameMap[pull.RarityId]},
+		}
+		for _, rarity := range pool.Rarities {
+			if rarity.Id == pull.RarityId {
+				result.Rarity = rarity
+			}
+		}
+		if len(pull.Pities) == len(pool.PityRarities) {
+			result.Pities = pull.Pities
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+func newReservationToken() string {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(token)
+}
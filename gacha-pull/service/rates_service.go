@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"time"
+)
+
+type RatesService interface {
+	FindRates(ctx context.Context, endpointId string) *web.RatesResponse
+}
+
+type RatesServiceImpl struct {
+	GachaPoolCache *poolcache.Cache
+}
+
+func NewRatesService(
+	gachaPoolCache *poolcache.Cache,
+) RatesService {
+	return &RatesServiceImpl{
+		GachaPoolCache: gachaPoolCache,
+	}
+}
+
+// FindRates discloses the rates of the banner running now, or of the gacha system itself between
+// banners. The odds of a box gacha change with every draw, so they are shown with the box itself.
+func (service *RatesServiceImpl) FindRates(ctx context.Context, endpointId string) *web.RatesResponse {
+	entry := findGachaConfig(ctx, service.GachaPoolCache, endpointId)
+	schedule := newBannerSchedule(entry.Banners, time.Now())
+
+	pool := compilePool(entry, schedule.Active)
+	if pool.GachaSystem.Mode == domain.GachaSystemModeBox {
+		panic(exception.NewBadRequestError("Rates of a box gacha depend on the box of each player, see the box of the player instead"))
+	}
+
+	// The pool compiled, so neither do the rates fail
+	ratesResponse, _ := entry.Rates(schedule.Active)
+	return ratesResponse
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"gacha-pull/receipt"
+)
+
+type ReceiptService interface {
+	FindKeys() *receipt.JWKS
+}
+
+type ReceiptServiceImpl struct {
+	ReceiptSigner *receipt.Signer
+}
+
+func NewReceiptService(receiptSigner *receipt.Signer) ReceiptService {
+	return &ReceiptServiceImpl{
+		ReceiptSigner: receiptSigner,
+	}
+}
+
+// FindKeys publishes the public key pull receipts are signed with, for offline verification.
+func (service *ReceiptServiceImpl) FindKeys() *receipt.JWKS {
+	return service.ReceiptSigner.JWKS()
+}
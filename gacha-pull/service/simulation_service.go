@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/client"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/sampler"
+	"gacha-pull/selection"
+	"gacha-pull/simulation"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSimulationRuns     = 10000
+	MaxSimulationRuns         = 100000
+	DefaultSimulationMaxPulls = 1000
+	MaxSimulationMaxPulls     = 10000
+	// MaxSimulatedPulls bounds runs times max pulls, so a single simulation stays within seconds
+	MaxSimulatedPulls = 10000000
+)
+
+type SimulationService interface {
+	Simulate(ctx context.Context, endpointId string, request *web.SimulationRequest) *web.SimulationResponse
+}
+
+type SimulationServiceImpl struct {
+	GachaPoolCache *poolcache.Cache
+}
+
+func NewSimulationService(
+	gachaPoolCache *poolcache.Cache,
+) SimulationService {
+	return &SimulationServiceImpl{
+		GachaPoolCache: gachaPoolCache,
+	}
+}
+
+func (service *SimulationServiceImpl) Simulate(ctx context.Context, endpointId string, request *web.SimulationRequest) *web.SimulationResponse {
+	validateSimulationRequest(request)
+
+	pool := service.findSimulationPool(ctx, endpointId, request)
+	if pool.GachaSystem.Mode == domain.GachaSystemModeBox {
+		panic(exception.NewBadRequestError("Box gacha systems cannot be simulated"))
+	}
+
+	target, targetName := simulationTarget(pool, request)
+	summary := simulation.Run(pool, &simulation.Options{
+		Runs:         request.Runs,
+		MaxPulls:     request.MaxPulls,
+		PullsPerStep: request.PullsPerStep,
+		Target:       target,
+	}, sampler.DefaultRandomSource)
+
+	bannerName := ""
+	if pool.Banner != nil {
+		bannerName = pool.Banner.Name
+	}
+
+	return web.ToSimulationResponse(targetName, bannerName, request, summary)
+}
+
+func validateSimulationRequest(request *web.SimulationRequest) {
+	if (request.Character == "") == (request.Rarity == "") {
+		panic(exception.NewBadRequestError("Either a character or a rarity must be given as target"))
+	}
+	if request.Runs == 0 {
+		request.Runs = DefaultSimulationRuns
+	}
+	if request.MaxPulls == 0 {
+		request.MaxPulls = DefaultSimulationMaxPulls
+	}
+	if request.PullsPerStep == 0 {
+		request.PullsPerStep = 1
+	}
+	if request.Runs < 1 || request.Runs > MaxSimulationRuns {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Runs must be between 1 and %d", MaxSimulationRuns)))
+	}
+	if request.MaxPulls < 1 || request.MaxPulls > MaxSimulationMaxPulls {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Max pulls must be between 1 and %d", MaxSimulationMaxPulls)))
+	}
+	if request.PullsPerStep < 1 || request.PullsPerStep > MaxMultiPullCount {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Pulls per step must be between 1 and %d", MaxMultiPullCount)))
+	}
+	if request.Runs*request.MaxPulls > MaxSimulatedPulls {
+		panic(exception.NewBadRequestError(fmt.Sprintf("Runs times max pulls must be at most %d", MaxSimulatedPulls)))
+	}
+}
+
+// findSimulationPool compiles the pool of the banner running now, or of a scheduled banner so it
+// can be tried out before it starts. The pool is compiled from the draft in the request when there
+// is one, so a draft can be simulated before it is published.
+func (service *SimulationServiceImpl) findSimulationPool(ctx context.Context, endpointId string, request *web.SimulationRequest) *selection.Pool {
+	var entry *poolcache.Entry
+	if request.Config != nil {
+		if request.Config.SchemaVersion != client.GachaConfigSchemaVersion {
+			panic(exception.NewBadRequestError(fmt.Sprintf("Gacha config schema version %d is not supported", request.Config.SchemaVersion)))
+		}
+		entry = poolcache.NewEntry(request.Config)
+	} else {
+		entry = findGachaConfig(ctx, service.GachaPoolCache, endpointId)
+	}
+
+	if request.BannerId == 0 {
+		return compilePool(entry, newBannerSchedule(entry.Banners, time.Now()).Active)
+	}
+
+	banner := entry.FindBanner(request.BannerId)
+	if banner == nil {
+		panic(exception.NewNotFoundError("Banner not found"))
+	}
+
+	return compilePool(entry, banner)
+}
+
+func simulationTarget(pool *selection.Pool, request *web.SimulationRequest) (func(result *selection.Result) bool, string) {
+	if request.Rarity != "" {
+		for _, rarity := range pool.Rarities {
+			if strings.EqualFold(rarity.Name, request.Rarity) {
+				rarityId := rarity.Id
+				return func(result *selection.Result) bool { return result.Rarity.Id == rarityId }, rarity.Name
+			}
+		}
+		panic(exception.NewNotFoundError("Rarity not found or cannot be pulled"))
+	}
+
+	for _, rarity := range pool.Rarities {
+		for _, character := range pool.RarityCharsMap[rarity.Id] {
+			if strings.EqualFold(character.Name, request.Character) {
+				characterId := character.Id
+				return func(result *selection.Result) bool { return result.Character.Id == characterId }, character.Name
+			}
+		}
+	}
+	panic(exception.NewNotFoundError("Character not found or cannot be pulled"))
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/repository"
+	"gacha-pull/selection"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	WalletReasonSpark = "spark"
+
+	// SparkSettleInterval is how often the spark points of ended banners are settled
+	SparkSettleInterval = time.Minute
+)
+
+type SparkService interface {
+	FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.SparkResponse
+	Redeem(ctx context.Context, endpointId string, player *domain.User, request *web.SparkRedeemRequest) *web.SparkRedeemResponse
+	SettleEndedBanners(ctx context.Context)
+}
+
+type SparkServiceImpl struct {
+	GachaPoolCache      *poolcache.Cache
+	SparkRepository     repository.SparkRepository
+	WalletRepository    repository.WalletRepository
+	InventoryRepository repository.InventoryRepository
+	Transaction         repository.Transaction
+}
+
+func NewSparkService(
+	gachaPoolCache *poolcache.Cache,
+	sparkRepository repository.SparkRepository,
+	walletRepository repository.WalletRepository,
+	inventoryRepository repository.InventoryRepository,
+	transaction repository.Transaction,
+) SparkService {
+	return &SparkServiceImpl{
+		GachaPoolCache:      gachaPoolCache,
+		SparkRepository:     sparkRepository,
+		WalletRepository:    walletRepository,
+		InventoryRepository: inventoryRepository,
+		Transaction:         transaction,
+	}
+}
+
+func (service *SparkServiceImpl) FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.SparkResponse {
+	pool := service.findSparkPool(ctx, endpointId, player)
+
+	spark := service.SparkRepository.FindByBannerIdAndPlayerId(ctx, pool.Banner.Id, player.Id)
+
+	var characterResponses []web.CharacterResponse
+	for _, character := range sparkCharacters(pool) {
+		characterResponses = append(characterResponses, *web.ToCharacterResponse(&character, pool.RarityNameMap[character.RarityId]))
+	}
+
+	return web.ToSparkResponse(player.Id, pool.Banner, formatBannerTime(pool.Banner, pool.Banner.EndsAt), spark, characterResponses)
+}
+
+// Redeem exchanges spark points for a featured character of the running banner. The points are
+// spent in the transaction that adds the character, so they are never lost to a failed redeem.
+func (service *SparkServiceImpl) Redeem(ctx context.Context, endpointId string, player *domain.User, request *web.SparkRedeemRequest) *web.SparkRedeemResponse {
+	pool := service.findSparkPool(ctx, endpointId, player)
+	banner := pool.Banner
+
+	var selectedCharacter *domain.Character
+	for _, character := range sparkCharacters(pool) {
+		if strings.EqualFold(character.Name, request.Character) {
+			selectedCharacter = &character
+			break
+		}
+	}
+	if selectedCharacter == nil {
+		panic(exception.NewNotFoundError("Featured character not found"))
+	}
+
+	result := selection.Result{Character: *selectedCharacter}
+	for _, rarity := range pool.Rarities {
+		if rarity.Id == selectedCharacter.RarityId {
+			result.Rarity = rarity
+		}
+	}
+
+	currencies := findCurrencies(ctx, service.GachaPoolCache, endpointId)
+
+	var points int
+	var duplicates []*web.DuplicateResponse
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		var ok bool
+		points, ok = service.SparkRepository.SpendPoints(ctx, banner.Id, player.Id, banner.SparkThreshold)
+		if !ok {
+			panic(exception.NewBadRequestError(fmt.Sprintf("Redeeming a character takes %d spark points", banner.SparkThreshold)))
+		}
+
+		duplicates = collectCharacters(ctx, service.InventoryRepository, service.WalletRepository, pool.GachaSystem.Id, player.Id,
+			[]selection.Result{result}, currencies)
+	})
+
+	characterResponse := web.ToCharacterResponse(selectedCharacter, result.Rarity.Name)
+	isNew := duplicates[0] == nil
+	characterResponse.IsNew = &isNew
+	characterResponse.Duplicate = duplicates[0]
+
+	return &web.SparkRedeemResponse{
+		Character: characterResponse,
+		Points:    points,
+	}
+}
+
+// SettleEndedBanners converts the spark points left on ended banners into the spark currency of
+// the banner, or lets them expire when the banner has none or its currency was deleted since. A
+// spark is settled on the terms it keeps, so banners edited or deleted since are settled as the
+// player earned the points. Every spark is settled exactly once, together with its conversion,
+// also when several instances settle at the same time. A spark that fails to settle is logged and
+// tried again by the next sweep, the other sparks are settled all the same.
+func (service *SparkServiceImpl) SettleEndedBanners(ctx context.Context) {
+	settled := make(map[int]int)
+	for _, spark := range service.SparkRepository.FindAllUnsettledEndedBefore(ctx, time.Now()) {
+		if service.settle(ctx, &spark) {
+			settled[spark.BannerId]++
+		}
+	}
+
+	for bannerId, players := range settled {
+		log.Printf("Settled the spark points of %d player(s) on banner %d", players, bannerId)
+	}
+}
+
+// settle settles one spark in its own transaction and reports whether it did.
+func (service *SparkServiceImpl) settle(ctx context.Context, spark *domain.Spark) (settled bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("Error while settling the spark points of player %s on banner %d: %v", spark.PlayerId, spark.BannerId, err)
+			settled = false
+		}
+	}()
+
+	service.Transaction.Run(ctx, func(ctx context.Context) {
+		if !service.SparkRepository.Settle(ctx, spark) {
+			return
+		}
+		settled = true
+
+		if spark.SparkCurrencyId == nil || spark.Points == 0 {
+			return
+		}
+
+		// The currency may have been deleted since the points were earned, they expire then
+		entry := service.GachaPoolCache.Find(ctx, spark.EndpointId)
+		if entry == nil || !hasCurrency(entry.Currencies, *spark.SparkCurrencyId) {
+			log.Printf("Spark points of player %s on banner %d expired, the spark currency no longer exists", spark.PlayerId, spark.BannerId)
+			return
+		}
+
+		service.WalletRepository.ApplyEntry(ctx, &domain.WalletLedgerEntry{
+			GachaSystemId: spark.GachaSystemId,
+			PlayerId:      spark.PlayerId,
+			CurrencyId:    *spark.SparkCurrencyId,
+			Amount:        int64(spark.Points) * int64(spark.SparkConversion),
+			Reason:        WalletReasonSpark,
+			Note:          fmt.Sprintf("%d spark point(s) left on %s", spark.Points, spark.BannerName),
+		})
+	})
+
+	return settled
+}
+
+// findSparkPool compiles the pool of the running banner, which must give spark points.
+func (service *SparkServiceImpl) findSparkPool(ctx context.Context, endpointId string, player *domain.User) *selection.Pool {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required for spark points"))
+	}
+
+	pool, schedule := findGachaPool(ctx, service.GachaPoolCache, endpointId)
+	if schedule.Active == nil {
+		schedule.checkOpen()
+		panic(exception.NewBannerNotActiveError("No banner is running"))
+	}
+	if schedule.Active.SparkThreshold == 0 {
+		panic(exception.NewBadRequestError("Running banner does not give spark points"))
+	}
+
+	return pool
+}
+
+// sparkCharacters returns the featured characters spark points can be redeemed for.
+func sparkCharacters(pool *selection.Pool) []domain.Character {
+	var characters []domain.Character
+	for _, rarity := range pool.Rarities {
+		for _, character := range pool.RarityCharsMap[rarity.Id] {
+			if character.Featured {
+				characters = append(characters, character)
+			}
+		}
+	}
+	return characters
+}
+
+// hasCurrency reports whether currencies holds the currency with the given id.
+func hasCurrency(currencies []domain.Currency, currencyId int) bool {
+	for _, currency := range currencies {
+		if currency.Id == currencyId {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"gacha-pull/exception"
+	"gacha-pull/model/domain"
+	"gacha-pull/model/web"
+	"gacha-pull/poolcache"
+	"gacha-pull/repository"
+)
+
+// WalletLedgerLimit is how many of the latest ledger entries are listed with a wallet
+const WalletLedgerLimit = 50
+
+type WalletService interface {
+	FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.WalletResponse
+	FindLedger(ctx context.Context, gachaSystemId int, playerId string) *web.WalletLedgerResponse
+	ApplyEntry(ctx context.Context, gachaSystemId int, request *web.WalletEntryRequest) *web.WalletLedgerEntryResponse
+}
+
+type WalletServiceImpl struct {
+	GachaPoolCache   *poolcache.Cache
+	WalletRepository repository.WalletRepository
+}
+
+func NewWalletService(
+	gachaPoolCache *poolcache.Cache,
+	walletRepository repository.WalletRepository,
+) WalletService {
+	return &WalletServiceImpl{
+		GachaPoolCache:   gachaPoolCache,
+		WalletRepository: walletRepository,
+	}
+}
+
+func (service *WalletServiceImpl) FindByPlayer(ctx context.Context, endpointId string, player *domain.User) *web.WalletResponse {
+	if player == nil {
+		panic(exception.NewUnauthorizedError("Player identity is required to read the wallet"))
+	}
+
+	entry := findGachaConfig(ctx, service.GachaPoolCache, endpointId)
+	gachaSystem := &entry.GachaSystem
+
+	currencies := entry.Currencies
+	wallets := service.WalletRepository.FindAllByGachaSystemIdAndPlayerId(ctx, gachaSystem.Id, player.Id)
+
+	return web.ToWalletResponse(player.Id, currencies, wallets)
+}
+
+// FindLedger returns the balances and the latest ledger entries of a player for gacha-master,
+// which names the currencies itself.
+func (service *WalletServiceImpl) FindLedger(ctx context.Context, gachaSystemId int, playerId string) *web.WalletLedgerResponse {
+	wallets := service.WalletRepository.FindAllByGachaSystemIdAndPlayerId(ctx, gachaSystemId, playerId)
+	entries := service.WalletRepository.FindLedgerByGachaSystemIdAndPlayerId(ctx, gachaSystemId, playerId, WalletLedgerLimit)
+
+	return web.ToWalletLedgerResponse(playerId, wallets, entries)
+}
+
+// ApplyEntry credits or debits the wallet of a player on behalf of gacha-master, which checked
+// the currency belongs to the gacha system. Currency can be granted before the gacha system is
+// published, so the configuration is not needed. A debit larger than the balance is refused.
+func (service *WalletServiceImpl) ApplyEntry(ctx context.Context, gachaSystemId int, request *web.WalletEntryRequest) *web.WalletLedgerEntryResponse {
+	if request.PlayerId == "" || len(request.PlayerId) > 100 {
+		panic(exception.NewBadRequestError("Player id must be between 1 and 100 characters"))
+	}
+	if request.CurrencyId == 0 || request.Amount == 0 {
+		panic(exception.NewBadRequestError("Currency and a non-zero amount are required"))
+	}
+	if request.Reason == "" || len(request.Reason) > 50 {
+		panic(exception.NewBadRequestError("Reason must be between 1 and 50 characters"))
+	}
+
+	ledgerEntry := domain.WalletLedgerEntry{
+		GachaSystemId: gachaSystemId,
+		PlayerId:      request.PlayerId,
+		CurrencyId:    request.CurrencyId,
+		Amount:        request.Amount,
+		Reason:        request.Reason,
+		Note:          request.Note,
+	}
+
+	if !service.WalletRepository.ApplyEntry(ctx, &ledgerEntry) {
+		panic(exception.NewBadRequestError("Player balance is lower than the amount to revoke"))
+	}
+
+	return web.ToWalletLedgerEntryResponse(&ledgerEntry)
+}
@@ -0,0 +1,120 @@
+// Package simulation estimates how many pulls it takes to obtain a target by running the pull
+// selection of a pool many times, the same way player pulls are drawn.
+package simulation
+
+import (
+	"gacha-pull/sampler"
+	"gacha-pull/selection"
+	"math"
+	"sort"
+)
+
+// histogramBuckets is the number of buckets the pull counts are grouped into at most.
+const histogramBuckets = 20
+
+// Options describe one simulation. Every run starts a new player without pity and pulls
+// PullsPerStep characters at a time, like a multi-pull, until Target matches a result or MaxPulls
+// pulls have been made.
+type Options struct {
+	Runs         int
+	MaxPulls     int
+	PullsPerStep int
+	Target       func(result *selection.Result) bool
+}
+
+// Summary holds the number of pulls it took to obtain the target. The percentiles are nil when
+// that share of the runs did not obtain the target within the maximum number of pulls.
+type Summary struct {
+	Runs        int
+	Obtained    int
+	MeanPulls   float64
+	MinPulls    int
+	MaxPulls    int
+	MedianPulls *int
+	P90Pulls    *int
+	P99Pulls    *int
+	Histogram   []Bucket
+}
+
+// Bucket counts the runs that obtained the target within From to To pulls, both included.
+type Bucket struct {
+	From  int
+	To    int
+	Count int
+}
+
+func Run(pool *selection.Pool, options *Options, random sampler.RandomSource) *Summary {
+	var pullCounts []int
+	for run := 0; run < options.Runs; run++ {
+		if pulls, ok := pullsToObtain(pool, options, random); ok {
+			pullCounts = append(pullCounts, pulls)
+		}
+	}
+	sort.Ints(pullCounts)
+
+	summary := &Summary{
+		Runs:        options.Runs,
+		Obtained:    len(pullCounts),
+		MedianPulls: percentile(pullCounts, options.Runs, 0.5),
+		P90Pulls:    percentile(pullCounts, options.Runs, 0.9),
+		P99Pulls:    percentile(pullCounts, options.Runs, 0.99),
+		Histogram:   []Bucket{},
+	}
+	if len(pullCounts) == 0 {
+		return summary
+	}
+
+	var totalPulls int
+	for _, pulls := range pullCounts {
+		totalPulls += pulls
+	}
+	summary.MeanPulls = float64(totalPulls) / float64(len(pullCounts))
+	summary.MinPulls = pullCounts[0]
+	summary.MaxPulls = pullCounts[len(pullCounts)-1]
+	summary.Histogram = histogram(pullCounts)
+
+	return summary
+}
+
+func pullsToObtain(pool *selection.Pool, options *Options, random sampler.RandomSource) (int, bool) {
+	pities := pool.NewPities("", nil)
+
+	for pulls := 0; pulls < options.MaxPulls; pulls += options.PullsPerStep {
+		results := pool.Draw(options.PullsPerStep, pities, random)
+		for i := range results {
+			if pulls+i+1 > options.MaxPulls {
+				return 0, false
+			}
+			if options.Target(&results[i]) {
+				return pulls + i + 1, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// percentile returns the pull count at or below which the given share of all runs obtained the
+// target. pullCounts holds the sorted pull counts of the runs that obtained it.
+func percentile(pullCounts []int, runs int, share float64) *int {
+	rank := int(math.Ceil(share * float64(runs)))
+	if rank < 1 || rank > len(pullCounts) {
+		return nil
+	}
+	return &pullCounts[rank-1]
+}
+
+func histogram(pullCounts []int) []Bucket {
+	maxPulls := pullCounts[len(pullCounts)-1]
+	width := (maxPulls + histogramBuckets - 1) / histogramBuckets
+
+	var buckets []Bucket
+	for from := 1; from <= maxPulls; from += width {
+		buckets = append(buckets, Bucket{From: from, To: from + width - 1})
+	}
+	for _, pulls := range pullCounts {
+		buckets[(pulls-1)/width].Count++
+	}
+
+	return buckets
+}